@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package bitsandblooms converts between immutable bitset.Set values and mutable
+// github.com/bits-and-blooms/bitset BitSets, so code bases can mix the two during a gradual migration.
+//
+// It is a separate module, so the main package doesn't depend on bits-and-blooms.
+package bitsandblooms
+
+import (
+	bbitset "github.com/bits-and-blooms/bitset"
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+// FromBitsAndBlooms returns a bitset.Set with the bits of b.
+// The bits are copied, so b can be modified afterwards.
+func FromBitsAndBlooms(b *bbitset.BitSet) bitset.Set {
+	if b == nil {
+		return bitset.New()
+	}
+
+	words := make([]uint64, len(b.Words()))
+	copy(words, b.Words())
+	return bitset.UnsafeFromWords(words)
+}
+
+// ToBitsAndBlooms returns a new bits-and-blooms BitSet with the bits of s.
+// Its length is the index of the highest set bit in s rounded up to a multiple of 64.
+func ToBitsAndBlooms(s bitset.Set) *bbitset.BitSet {
+	return bbitset.From(bitset.AppendWords(nil, s))
+}
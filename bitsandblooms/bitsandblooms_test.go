@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitsandblooms
+
+import (
+	"testing"
+
+	bbitset "github.com/bits-and-blooms/bitset"
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+func TestFromBitsAndBlooms(t *testing.T) {
+	b := bbitset.New(1000).Set(3).Set(700).Set(100_000)
+	s := FromBitsAndBlooms(b)
+
+	b.Clear(3)
+	if !s.Test(3) || !s.Test(700) || !s.Test(100_000) || s.Test(4) {
+		t.Error("Converted set has incorrect bits, or shares memory with the BitSet")
+	}
+	if s.Count() != 3 {
+		t.Errorf("Expected 3 bits, got %d", s.Count())
+	}
+
+	if s := FromBitsAndBlooms(nil); s.Count() != 0 {
+		t.Error("Converting nil should return an empty set")
+	}
+	if s := FromBitsAndBlooms(bbitset.New(5000)); s.Count() != 0 {
+		t.Error("Converting an empty BitSet should return an empty set")
+	}
+}
+
+func TestToBitsAndBlooms(t *testing.T) {
+	s := bitset.NewBuilder(0).WithMany(1, 64, 2_000_000).Build()
+	b := ToBitsAndBlooms(s)
+
+	if !b.Test(1) || !b.Test(64) || !b.Test(2_000_000) || b.Test(2) {
+		t.Error("Converted BitSet has incorrect bits")
+	}
+	if b.Count() != 3 {
+		t.Errorf("Expected 3 bits, got %d", b.Count())
+	}
+
+	// Round trip
+	if got := FromBitsAndBlooms(b); got.Key() != s.Key() {
+		t.Error("Set does not round trip through a BitSet")
+	}
+	if b := ToBitsAndBlooms(bitset.New()); b.Count() != 0 {
+		t.Error("Converting an empty set should return an empty BitSet")
+	}
+}
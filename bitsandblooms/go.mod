@@ -0,0 +1,8 @@
+module github.com/sibber5/go-immutable-bitset/bitsandblooms
+
+go 1.25.0
+
+require (
+	github.com/bits-and-blooms/bitset v1.24.4
+	github.com/sibber5/go-immutable-bitset v1.2.0
+)
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrExhausted is returned when an Allocator has no free ids, or no free block of the requested size.
+var ErrExhausted = errors.New("bitset: no free ids")
+
+// bitset.Allocator hands out ids in [0, size), always picking the lowest free ones,
+// and tracks which are in use as an immutable Set.
+//
+// Like the other types in this package, an Allocator is immutable: every method that changes it returns a new one,
+// so every state is a snapshot that can be kept or shared. To share an allocator between goroutines,
+// wrap its updates in Store.Update or a mutex.
+type Allocator struct {
+	used Set
+	size uint32
+	hint uint32 // every id below hint is in use
+}
+
+// NewAllocator creates and returns a new bitset.Allocator with every id in [0, size) free.
+func NewAllocator(size uint32) Allocator {
+	return Allocator{used: New(), size: size}
+}
+
+// Size returns the number of ids a can hand out.
+func (a Allocator) Size() uint32 {
+	return a.size
+}
+
+// Used returns the ids that are in use.
+func (a Allocator) Used() Set {
+	if a.used == nil {
+		return New()
+	}
+	return a.used
+}
+
+// Allocate returns a new bitset.Allocator with the lowest free id in use, and that id,
+// or ErrExhausted if every id is in use.
+// The original bitset.Allocator is not modified.
+func (a Allocator) Allocate() (Allocator, uint32, error) {
+	return a.AllocateBlock(1, 1)
+}
+
+// AllocateBlock returns a new bitset.Allocator with the n consecutive ids starting at the lowest free multiple of align
+// in use, and the first of those ids, or ErrExhausted if there is no such block.
+// An align of 0 is the same as 1. It panics if n is 0.
+// The original bitset.Allocator is not modified.
+func (a Allocator) AllocateBlock(n, align uint32) (Allocator, uint32, error) {
+	if n == 0 {
+		panic("bitset: cannot allocate an empty block")
+	}
+	align = max(align, 1)
+	alignUp := func(i uint64) uint64 {
+		return (i + uint64(align) - 1) / uint64(align) * uint64(align)
+	}
+
+	used := a.Used()
+	first, ok := nextClearBit(used, a.hint)
+	if !ok {
+		return a, 0, ErrExhausted
+	}
+
+	// Skip past the used ids in the way of each candidate block, a run at a time
+	start := alignUp(uint64(first))
+	for start+uint64(n) <= uint64(a.size) {
+		next, ok := nextSetBit(used, uint32(start))
+		if !ok || uint64(next) >= start+uint64(n) {
+			break
+		}
+		free, ok := nextClearBit(used, next)
+		if !ok {
+			return a, 0, ErrExhausted
+		}
+		start = alignUp(uint64(free))
+	}
+	if start+uint64(n) > uint64(a.size) {
+		return a, 0, ErrExhausted
+	}
+
+	if n == 1 {
+		a.used = used.Set(uint32(start)) // only copies what Set copies, e.g. the path to one leaf of a trie
+	} else {
+		a.used = used.Union(fromRuns([]run[uint32]{{uint32(start), uint32(start + uint64(n) - 1)}}))
+	}
+	a.hint = first
+	if start == uint64(first) {
+		a.hint = uint32(start + uint64(n))
+	}
+	return a, uint32(start), nil
+}
+
+// Reserve returns a new bitset.Allocator with the given id in use, so it is never handed out,
+// or ErrOutOfRange if the id is outside [0, Size). Reserving an id that is already in use does nothing.
+// The original bitset.Allocator is not modified.
+func (a Allocator) Reserve(id uint32) (Allocator, error) {
+	if id >= a.size {
+		return a, fmt.Errorf("%w: %d is not in [0, %d)", ErrOutOfRange, id, a.size)
+	}
+
+	a.used = a.Used().Set(id)
+	return a, nil
+}
+
+// Release returns a new bitset.Allocator with the given id free, so it can be handed out again.
+// Releasing an id that is already free does nothing.
+// The original bitset.Allocator is not modified.
+func (a Allocator) Release(id uint32) Allocator {
+	a.used = a.Used().Clear(id)
+	a.hint = min(a.hint, id)
+	return a
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a := NewAllocator(3)
+	for want := range uint32(3) {
+		var id uint32
+		var err error
+		if a, id, err = a.Allocate(); err != nil || id != want {
+			t.Fatalf("Expected id %d, got %d (%v)", want, id, err)
+		}
+	}
+	if _, _, err := a.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+
+	a = a.Release(1)
+	if a.Used().Test(1) || a.Used().Count() != 2 {
+		t.Error("Release should free the id")
+	}
+	a2, id, err := a.Allocate()
+	if err != nil || id != 1 {
+		t.Errorf("Expected the released id 1, got %d (%v)", id, err)
+	}
+	if a.Used().Test(1) || !a2.Used().Test(1) {
+		t.Error("Allocate should not modify the original allocator")
+	}
+}
+
+func TestAllocatorReserve(t *testing.T) {
+	a, err := NewAllocator(10).Reserve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, id, _ := a.Allocate(); id != 1 {
+		t.Errorf("Expected id 1 after reserving 0, got %d", id)
+	}
+	if _, err := a.Reserve(10); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+
+	var zero Allocator
+	if _, _, err := zero.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Error("The zero Allocator should have no ids")
+	}
+}
+
+func TestAllocateBlock(t *testing.T) {
+	a := NewAllocator(64)
+	a, _ = a.Reserve(1)
+	a, _ = a.Reserve(9)
+
+	tests := []struct {
+		n, align, want uint32
+	}{
+		{4, 4, 4},  // [4, 8) is free
+		{4, 4, 12}, // [8, 12) has 9
+		{2, 0, 2},  // unaligned
+		{3, 16, 16},
+	}
+	for _, tt := range tests {
+		var start uint32
+		var err error
+		a, start, err = a.AllocateBlock(tt.n, tt.align)
+		if err != nil || start != tt.want {
+			t.Errorf("AllocateBlock(%d, %d) = %d (%v), expected %d", tt.n, tt.align, start, err, tt.want)
+		}
+		for i := start; i < start+tt.n; i++ {
+			if !a.Used().Test(i) {
+				t.Errorf("Id %d in the block is not in use", i)
+			}
+		}
+	}
+
+	if _, _, err := a.AllocateBlock(48, 1); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+	if _, start, err := a.AllocateBlock(32, 8); err != nil || start != 24 {
+		t.Errorf("Expected a block at 24, got %d (%v)", start, err)
+	}
+}
+
+// lowestFreeBlock returns what AllocateBlock should return for used, by testing every id.
+func lowestFreeBlock(used Set, size, n, align uint32) (uint32, bool) {
+	align = max(align, 1)
+outer:
+	for start := uint64(0); start+uint64(n) <= uint64(size); start += uint64(align) {
+		for i := start; i < start+uint64(n); i++ {
+			if used.Test(uint32(i)) {
+				continue outer
+			}
+		}
+		return uint32(start), true
+	}
+	return 0, false
+}
+
+func TestAllocatorRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(11, 12))
+	a := NewAllocator(2000)
+	for range 3000 {
+		switch op := r.IntN(10); {
+		case op < 3 && a.Used().Count() > 0:
+			a = a.Release(nthBit(a.Used(), r.IntN(a.Used().Count())))
+		case op < 4:
+			a = a.Release(r.Uint32N(2000))
+		default:
+			n, align := uint32(1), uint32(1)
+			if op == 9 {
+				n, align = 1+r.Uint32N(20), r.Uint32N(9)
+			}
+			want, wantOK := lowestFreeBlock(a.Used(), a.Size(), n, align)
+			next, start, err := a.AllocateBlock(n, align)
+			if (err == nil) != wantOK || (wantOK && start != want) {
+				t.Fatalf("AllocateBlock(%d, %d) = %d (%v), expected %d, %v", n, align, start, err, want, wantOK)
+			}
+			a = next
+		}
+	}
+}
+
+func TestAllocatorMany(t *testing.T) {
+	const n = 200_000
+	a := NewAllocator(n)
+	for want := range uint32(n) {
+		var id uint32
+		var err error
+		if a, id, err = a.Allocate(); err != nil || id != want {
+			t.Fatalf("Expected id %d, got %d (%v)", want, id, err)
+		}
+	}
+	if _, _, err := a.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+}
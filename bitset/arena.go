@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+// bitset.Arena allocates the words of the sets built from it out of large shared blocks,
+// so a batch that creates millions of short-lived sets makes a few big allocations instead of millions of small ones,
+// and can reuse the same memory for the next batch by calling Reset.
+//
+// Only the sets returned by the arena's builders and FromWords are allocated from the arena.
+// Sets derived from them using Set, Clear, or the boolean operations are allocated normally.
+//
+// An Arena is not safe for concurrent use.
+type Arena struct {
+	block []uint64 // the current block
+	off   int      // index of the first free word in block
+	used  int      // words allocated since the last Reset
+}
+
+// NewArena creates and returns a new bitset.Arena with room for at least sizeHint bits before it needs to grow.
+func NewArena(sizeHint int) *Arena {
+	return &Arena{block: make([]uint64, max((sizeHint+63)/64, 1024))}
+}
+
+// Reset makes all the memory of the arena available for reuse by the next batch.
+//
+// WARNING: Every set built from the arena before calling Reset shares its memory,
+// so using any of them after calling Reset is not supported and will cause undefined behavior.
+func (a *Arena) Reset() {
+	if a.used > len(a.block) {
+		// The batch didn't fit in one block, so make the next one big enough for all of it
+		a.block = make([]uint64, a.used)
+	} else {
+		clear(a.block[:a.off])
+	}
+	a.off, a.used = 0, 0
+}
+
+// Alloc returns n zeroed words from the arena, so an Arena is a WordAllocator.
+func (a *Arena) Alloc(n int) []uint64 {
+	if n > len(a.block)-a.off {
+		a.block = make([]uint64, max(len(a.block), n))
+		a.off = 0
+	}
+
+	words := a.block[a.off : a.off+n : a.off+n]
+	a.off += n
+	a.used += n
+	return words
+}
+
+// Free does nothing, as the memory of an arena is only reused after calling Reset.
+func (a *Arena) Free(words []uint64) {}
+
+// FromWords returns a bitset.Set with the bits in words, where bit i is bit i%64 of words[i/64].
+// words is copied into the arena, and can be modified afterwards.
+func (a *Arena) FromWords(words []uint64) Set {
+	return FromWordsWith(a, words)
+}
+
+// NewBuilder creates and returns a new bitset.Builder that allocates from the arena,
+// with an initial bit capacity of at least minCapacity.
+// You can set bits beyond this capacity and the builder will expand automatically.
+func (a *Arena) NewBuilder(minCapacity int) Builder {
+	return NewBuilderWith(a, minCapacity)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	a := NewArena(64 * 1024)
+
+	b := a.NewBuilder(500)
+	for i := uint32(0); i < 500; i += 3 {
+		b = b.With(i)
+	}
+	b = b.WithMany(100, 2000)
+	bs := b.Build()
+	if _, ok := bs.(largeBitSet); !ok {
+		t.Fatalf("Expected largeBitSet, but got %T", bs)
+	}
+	for _, i := range []uint32{3, 100, 498, 2000} {
+		if !bs.Test(i) {
+			t.Errorf("Set built from arena is missing bit %d", i)
+		}
+	}
+	if bs.Test(4) || bs.Test(1999) {
+		t.Error("Set built from arena has a bit that wasn't set")
+	}
+
+	words := []uint64{1, 2, 3, 4, 0}
+	fw := a.FromWords(words)
+	words[0] = 0
+	if !fw.Test(0) || !fw.Test(65) || fw.Test(64) {
+		t.Error("FromWords should have copied the words")
+	}
+
+	// Sets allocated from the arena don't overlap
+	if !bs.Test(3) || !bs.Test(2000) {
+		t.Error("Set built from arena was overwritten by a later allocation")
+	}
+
+	if a.used != len(fw.(largeBitSet))+(500+63)/64+2000/64+1 {
+		t.Errorf("Arena has unexpected usage %d", a.used)
+	}
+}
+
+func TestArenaReset(t *testing.T) {
+	a := NewArena(0)
+	block := &a.block[0]
+
+	a.FromWords([]uint64{1, 2, 3, 4})
+	a.Reset()
+	if &a.block[0] != block || a.block[0] != 0 {
+		t.Error("Reset should zero and reuse the block")
+	}
+
+	// Allocate more than the block holds, so that Reset grows it
+	for range 3 {
+		a.NewBuilder(1000 * 64)
+	}
+	a.Reset()
+	if len(a.block) != 3000 {
+		t.Errorf("Expected block of 3000 words after Reset, got %d", len(a.block))
+	}
+
+	bs := a.NewBuilder(0).With(1000).Build()
+	if !bs.Test(1000) || bs.Test(999) {
+		t.Error("Set built after Reset has incorrect bits")
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+// ToArrowValidity returns the bits [0, length) of s as an Apache Arrow validity bitmap, where a set bit marks a
+// valid (non-null) slot: bit i is bit i%8 of byte i/8. The result has (length+7)/8 bytes, and bits of s at or
+// past length are dropped.
+func ToArrowValidity(s Set, length int) []byte {
+	data := make([]byte, (length+7)/8)
+	// Only the words below length are read
+	for _, iw := range nonzeroWords(slice(s, 0, uint64(max(length, 0)))) {
+		for j := range 8 {
+			if k := iw.i*8 + j; k < len(data) {
+				data[k] = byte(iw.w >> (8 * j))
+			}
+		}
+	}
+	return data
+}
+
+// FromArrowValidity returns a bitset.Set with the bits [offset, offset+length) of an Apache Arrow validity bitmap
+// in the layout described by ToArrowValidity, where bit i of the result is bit offset+i of data,
+// i.e. the valid slots of an array with the given offset and length.
+//
+// A nil data means every slot is valid, as in Arrow arrays without a validity buffer.
+// FromArrowValidity panics if data is not nil and has fewer than offset+length bits.
+func FromArrowValidity(data []byte, offset, length int) Set {
+	if length <= 0 {
+		return New()
+	}
+	if data == nil {
+		return fromRuns([]run[uint32]{{0, uint32(length - 1)}})
+	}
+
+	data = data[offset/8 : (offset+length+7)/8]
+	shift := offset % 8
+	words := make([]uint64, (length+63)/64)
+	for i := range words {
+		var w uint64
+		for j := range 8 {
+			if k := i*8 + j; k < len(data) {
+				w |= uint64(data[k]) << (8 * j)
+			}
+		}
+		w >>= shift
+		if k := i*8 + 8; shift != 0 && k < len(data) {
+			w |= uint64(data[k]) << (64 - shift)
+		}
+		words[i] = w
+	}
+	if rem := length % 64; rem != 0 {
+		words[len(words)-1] &= 1<<rem - 1
+	}
+	return fromWords(words)
+}
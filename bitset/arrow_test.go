@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+func TestToArrowValidity(t *testing.T) {
+	s := FromIndices(0, 2, 9, 20)
+	if got, want := ToArrowValidity(s, 12), []byte{0x05, 0x02}; !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+	if got := ToArrowValidity(s, 0); len(got) != 0 {
+		t.Errorf("Expected no bytes for length 0, got % x", got)
+	}
+	if got := ToArrowValidity(New(), 70); !bytes.Equal(got, make([]byte, 9)) {
+		t.Errorf("Expected 9 zero bytes, got % x", got)
+	}
+
+	// Only the words below length are read, however far the set spans
+	hi := FromIndices(0, 3, 4e9)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	got := ToArrowValidity(hi, 10)
+	runtime.ReadMemStats(&after)
+	if !bytes.Equal(got, []byte{0x09, 0x00}) {
+		t.Errorf("Expected 09 00, got % x", got)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<12 {
+		t.Errorf("ToArrowValidity of 10 bits allocated %d bytes", n)
+	}
+}
+
+func TestFromArrowValidity(t *testing.T) {
+	data := []byte{0b1010_1100, 0xff, 0x01}
+	s := FromArrowValidity(data, 0, 24)
+	for _, i := range []uint32{2, 3, 5, 7, 8, 15, 16} {
+		if !s.Test(i) {
+			t.Errorf("Expected bit %d to be set", i)
+		}
+	}
+	if s.Count() != 13 {
+		t.Errorf("Expected 13 bits, got %d", s.Count())
+	}
+
+	// Offset into the buffer, as for a sliced array
+	s = FromArrowValidity(data, 3, 6)
+	if got := ToArrowValidity(s, 6); !bytes.Equal(got, []byte{0b11_0101}) {
+		t.Errorf("Unexpected bits with offset: %08b", got)
+	}
+
+	if s := FromArrowValidity(nil, 0, 100); s.Count() != 100 || s.Test(100) {
+		t.Error("A nil buffer should mark every slot valid")
+	}
+	if s := FromArrowValidity(data, 5, 0); s.Count() != 0 {
+		t.Error("Expected an empty set for length 0")
+	}
+
+	for _, ts := range testSets() {
+		words := AppendWords(nil, ts.s)
+		length := len(words)*64 + 5
+		data := ToArrowValidity(ts.s, length)
+		if got := FromArrowValidity(data, 0, length); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip", ts.name)
+		}
+
+		// Shift the buffer by 3 bits and read it back with an offset
+		shifted := ToArrowValidity(FromIndices(shiftedIndices(ts.s, 3)...), length+3)
+		if got := FromArrowValidity(shifted, 3, length); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip with an offset", ts.name)
+		}
+	}
+}
+
+// shiftedIndices returns the bit indices of s, each increased by delta.
+func shiftedIndices(s Set, delta uint32) []uint32 {
+	var indices []uint32
+	for i := range s.Indices() {
+		indices = append(indices, i+delta)
+	}
+	return indices
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/asn1"
+	"math/bits"
+)
+
+// ToBitString returns the bits of s as an ASN.1 BIT STRING, where bit i is the i-th bit from the start,
+// i.e. the most significant bit of the first byte is bit 0, as in named bit lists like X.509 KeyUsage.
+// BitLength ends at the highest set bit, so the result is in the canonical DER form for named bit lists.
+func ToBitString(s Set) asn1.BitString {
+	// Redis bitmaps use the same layout
+	data := EncodeRedis(s)
+	if len(data) == 0 {
+		return asn1.BitString{Bytes: data}
+	}
+	return asn1.BitString{Bytes: data, BitLength: len(data)*8 - bits.TrailingZeros8(data[len(data)-1])}
+}
+
+// FromBitString returns a bitset.Set with the bits of an ASN.1 BIT STRING, numbered as described by ToBitString.
+// Bits past BitLength are ignored.
+func FromBitString(b asn1.BitString) Set {
+	n := min((b.BitLength+7)/8, len(b.Bytes))
+	if n <= 0 {
+		return New()
+	}
+
+	data := make([]byte, n)
+	copy(data, b.Bytes)
+	if rem := b.BitLength % 8; rem != 0 && n == (b.BitLength+7)/8 {
+		data[n-1] &^= 0xff >> rem
+	}
+	return DecodeRedis(data)
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestToBitString(t *testing.T) {
+	// digitalSignature (0) and keyCertSign (5) from X.509 KeyUsage
+	bs := ToBitString(FromIndices(0, 5))
+	if !bytes.Equal(bs.Bytes, []byte{0x84}) || bs.BitLength != 6 {
+		t.Errorf("Unexpected BIT STRING %+v", bs)
+	}
+	if bs.At(0) != 1 || bs.At(5) != 1 || bs.At(1) != 0 {
+		t.Error("BIT STRING has incorrect bits")
+	}
+
+	der, err := asn1.Marshal(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x03, 0x02, 0x02, 0x84}; !bytes.Equal(der, want) {
+		t.Errorf("Expected DER % x, got % x", want, der)
+	}
+
+	if bs := ToBitString(New()); bs.BitLength != 0 || len(bs.Bytes) != 0 {
+		t.Errorf("Expected an empty BIT STRING, got %+v", bs)
+	}
+}
+
+func TestFromBitString(t *testing.T) {
+	var bs asn1.BitString
+	if _, err := asn1.Unmarshal([]byte{0x03, 0x03, 0x07, 0x80, 0x80}, &bs); err != nil {
+		t.Fatal(err)
+	}
+	s := FromBitString(bs)
+	if s.Count() != 2 || !s.Test(0) || !s.Test(8) {
+		t.Errorf("Set from BIT STRING has incorrect bits: %v", s)
+	}
+
+	// Bits past BitLength are ignored
+	s = FromBitString(asn1.BitString{Bytes: []byte{0xff, 0xff}, BitLength: 10})
+	if s.Count() != 10 || s.Test(10) {
+		t.Errorf("Bits past BitLength should be ignored, got %d bits", s.Count())
+	}
+	if s := FromBitString(asn1.BitString{Bytes: []byte{0xff}}); s.Count() != 0 {
+		t.Error("A BIT STRING with no bits should give an empty set")
+	}
+
+	for _, ts := range testSets() {
+		if got := FromBitString(ToBitString(ts.s)); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip, got %T", ts.name, got)
+		}
+	}
+}
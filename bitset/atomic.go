@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "sync/atomic"
+
+// bitset.Atomic holds a Set that can be loaded and replaced atomically, for publishing sets to concurrent readers
+// without locks. The zero value holds an empty set.
+//
+// An Atomic must not be copied after first use.
+type Atomic struct {
+	p atomic.Pointer[Set]
+}
+
+// NewAtomic creates and returns a new bitset.Atomic holding s.
+func NewAtomic(s Set) *Atomic {
+	a := &Atomic{}
+	a.Store(s)
+	return a
+}
+
+// Load returns the set currently held by a.
+func (a *Atomic) Load() Set {
+	if p := a.p.Load(); p != nil {
+		return *p
+	}
+	return New()
+}
+
+// Store replaces the set held by a with s.
+func (a *Atomic) Store(s Set) {
+	a.p.Store(&s)
+}
+
+// Swap replaces the set held by a with s, and returns the previous set.
+func (a *Atomic) Swap(s Set) Set {
+	if p := a.p.Swap(&s); p != nil {
+		return *p
+	}
+	return New()
+}
+
+// Update replaces the set held by a with the result of calling fn with it, and returns the new set.
+// If another goroutine replaces the set while fn is running, fn is called again with the newer set,
+// so fn may be called more than once and must not have side effects.
+func (a *Atomic) Update(fn func(Set) Set) Set {
+	for {
+		p := a.p.Load()
+		old := New()
+		if p != nil {
+			old = *p
+		}
+
+		s := fn(old)
+		if a.p.CompareAndSwap(p, &s) {
+			return s
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomic(t *testing.T) {
+	var a Atomic
+	if s := a.Load(); s != New() {
+		t.Errorf("Zero value of Atomic should hold an empty set, got %v", s)
+	}
+
+	a.Store(New().Set(1))
+	if !a.Load().Test(1) {
+		t.Error("Load should return the stored set")
+	}
+
+	old := a.Swap(New().Set(2))
+	if !old.Test(1) || old.Test(2) || !a.Load().Test(2) {
+		t.Error("Swap should return the previous set and store the new one")
+	}
+
+	if s := NewAtomic(New().Set(3)).Load(); !s.Test(3) {
+		t.Error("NewAtomic should hold the given set")
+	}
+}
+
+func TestAtomicUpdate(t *testing.T) {
+	a := NewAtomic(New())
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 100 {
+				a.Update(func(s Set) Set {
+					return s.Set(uint32(g*100 + i))
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	s := a.Load()
+	for i := range uint32(800) {
+		if !s.Test(i) {
+			t.Fatalf("Update lost bit %d", i)
+		}
+	}
+}
@@ -3,7 +3,24 @@
 
 package bitset
 
+import (
+	"fmt"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"unsafe"
+)
+
 // bitset.Set is an immutable bit set.
+//
+// Every operation returns its result in canonical form: trailing zero words are trimmed, sets with only bits below 64
+// are always stored in a single word, and the representation only depends on which bits are set, not on the operations
+// that produced it. So two sets with the same bits always have identical internal representations.
+// The only exceptions are sets with a ShrinkPolicy other than ShrinkAlways, which are allowed to keep trailing zero words,
+// sets returned by FromMappedBytes, which are always stored as the words they were given,
+// and the views returned by UnionView and IntersectView.
 type Set interface {
 	// Test reports whether the bit for the given bit index is set.
 	Test(bitIndex uint32) bool
@@ -15,6 +32,40 @@ type Set interface {
 	// Clear returns a new bitset.Set with the bit for the given bit index cleared.
 	// The original bitset.Set is not modified.
 	Clear(bitIndex uint32) Set
+
+	// Union returns a new bitset.Set with the bits that are set in either this set or other.
+	// Neither set is modified.
+	Union(other Set) Set
+
+	// Intersect returns a new bitset.Set with the bits that are set in both this set and other.
+	// Neither set is modified.
+	Intersect(other Set) Set
+
+	// Difference returns a new bitset.Set with the bits that are set in this set but not in other.
+	// Neither set is modified.
+	Difference(other Set) Set
+
+	// SymmetricDifference returns a new bitset.Set with the bits that are set in exactly one of this set and other.
+	// Neither set is modified.
+	SymmetricDifference(other Set) Set
+
+	// Count returns the number of set bits.
+	// On 32-bit platforms, a count that does not fit in an int, i.e. above 2^31-1, is returned as math.MaxInt.
+	Count() int
+
+	// Indices returns an iterator over the bit indices of the set bits, in ascending order.
+	Indices() iter.Seq[uint32]
+
+	// SizeInBytes returns the approximate number of bytes of heap memory used by this set.
+	// Memory shared with other sets is counted in full.
+	SizeInBytes() int
+
+	// Kind returns the internal representation used by this set.
+	Kind() Kind
+
+	// Key returns a comparable value representing the bits of this set.
+	// Two sets have the same key if and only if they have the same bits, so keys can be used as map keys to deduplicate sets.
+	Key() Key
 }
 
 // New creates and returns a new empty bitset.Set.
@@ -22,6 +73,223 @@ func New() Set {
 	return bitSet64(0)
 }
 
+// Full returns a bitset.Set with the bits [0, n) set, e.g. to start from every id and subtract the ones in use.
+// It is stored as a single run of bits, or as whole words for small n, without setting the bits one by one.
+func Full(n uint32) Set {
+	if n == 0 {
+		return bitSet64(0)
+	}
+	return fromRuns([]run[uint32]{{0, n - 1}})
+}
+
+// FromStride returns a bitset.Set with count bits set, every step-th bit starting at start, e.g. all even slots or
+// every 8th lane. Dense strides are built by repeating the pattern of words they make, rather than bit by bit.
+// It panics if step is 0 and count is more than 1, or if the last bit would be past the highest bit index.
+func FromStride(start, step, count uint32) Set {
+	if count == 0 {
+		return bitSet64(0)
+	}
+	last := uint64(start) + uint64(count-1)*uint64(step)
+	if count > 1 && step == 0 || last > math.MaxUint32 {
+		panic(fmt.Sprintf("bitset: invalid stride of %d bits from %d by %d", count, start, step))
+	}
+	if count == 1 || step == 1 {
+		return fromRuns([]run[uint32]{{start, uint32(last)}})
+	}
+
+	w := int(last/64) + 1
+	if w > 3 && preferSparse(int(count), w) {
+		indices := make([]uint32, count)
+		for k := range indices {
+			indices[k] = start + uint32(k)*step
+		}
+		return fromIndices(indices)
+	}
+
+	// The bits congruent to start modulo step repeat every lcm(step, 64) bits, so build the words of one period
+	// and copy them over the rest, before clearing the bits outside [start, last]
+	period := int(step) / gcd(int(step), 64)
+	words := make([]uint64, w)
+	rem := start % step
+	for j := range min(period, w) {
+		base := uint32(j * 64)
+		for i := base + (rem+step-base%step)%step; i < base+64; i += step {
+			words[j] |= 1 << (i - base)
+		}
+	}
+	for filled := period; filled < w; filled *= 2 {
+		copy(words[filled:], words[:filled])
+	}
+	clear(words[:start/64])
+	words[start/64] &= ^uint64(0) << (start % 64)
+	words[w-1] &= ^uint64(0) >> (63 - last%64)
+	return fromWords(words)
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// UnsafeFromWords returns a bitset.Set with the bits in words, where bit i is bit i%64 of words[i/64].
+//
+// words is adopted without copying when it is stored as plain words, so this avoids a second full copy of
+// a set that was just deserialized. Words spanning trieMinWords words or more, i.e. 8 KiB, are always adopted,
+// as a read-only set of KindMapped rather than a trie, which copies them the first time Set or Clear changes them.
+// The caller must not modify words, or any slice sharing its backing array, after calling UnsafeFromWords.
+// Doing so will modify the returned set, and every set derived from it, in place.
+func UnsafeFromWords(words []uint64) Set {
+	lastIdx := len(words) - 1
+	for lastIdx >= 0 && words[lastIdx] == 0 {
+		lastIdx--
+	}
+	if lastIdx+1 >= trieMinWords {
+		return mappedBitSet{words: words[:lastIdx+1]}
+	}
+	return fromWords(words)
+}
+
+// FromIndices returns a bitset.Set with the bits for the given bit indices set.
+// The indices can be in any order, and may contain duplicates.
+//
+// Unlike a Builder, it picks the representation from all the indices up front,
+// so it never allocates words all the way up to a far away bit index.
+func FromIndices(bitIndices ...uint32) Set {
+	indices := slices.Clone(bitIndices)
+	slices.Sort(indices)
+	return fromIndices(slices.Clip(slices.Compact(indices)))
+}
+
+// TestAndSet returns a new bitset.Set with the bit for the given bit index set, and whether it was not set before.
+// If it was already set, it returns s itself, so deduplicating with it only looks up each bit once. A nil s is empty.
+// The original bitset.Set is not modified.
+func TestAndSet(s Set, bitIndex uint32) (Set, bool) {
+	s = orEmpty(s)
+	if s.Test(bitIndex) {
+		return s, false
+	}
+	return s.Set(bitIndex), true
+}
+
+// TestAndClear returns a new bitset.Set with the bit for the given bit index cleared, and whether it was set before.
+// If it was not set, it returns s itself. A nil s is empty.
+// The original bitset.Set is not modified.
+func TestAndClear(s Set, bitIndex uint32) (Set, bool) {
+	s = orEmpty(s)
+	if !s.Test(bitIndex) {
+		return s, false
+	}
+	return s.Clear(bitIndex), true
+}
+
+// SwapBits returns a new bitset.Set with the values of bits i and j exchanged, e.g. to follow the entities the bits
+// refer to being reordered. If both bits have the same value, it returns s itself.
+// The original bitset.Set is not modified.
+func SwapBits(s Set, i, j uint32) Set {
+	if s.Test(i) == s.Test(j) {
+		return s
+	}
+	if !s.Test(i) {
+		i, j = j, i
+	}
+
+	// Move the bit from i to j with a single copy where the words allow it
+	words, ok := denseWords(s)
+	if !ok || int(j/64) >= len(words) {
+		return s.Clear(i).Set(j)
+	}
+	newBits := slices.Clone(words)
+	newBits[i/64] &^= 1 << (i % 64)
+	newBits[j/64] |= 1 << (j % 64)
+	if r, ok := s.(retainedBitSet); ok {
+		return r.retain(newBits)
+	}
+	return fromWords(newBits)
+}
+
+// AddSorted returns a new bitset.Set with the bits of s and the bits for the given bit indices set.
+// The indices must be in ascending order, and may contain duplicates. It panics if they are not sorted.
+//
+// Unlike calling Set for every index, it copies s at most once, e.g. to add a sorted posting list.
+// The original bitset.Set is not modified.
+func AddSorted(s Set, bitIndices []uint32) Set {
+	if len(bitIndices) == 0 {
+		return s
+	}
+	if !slices.IsSorted(bitIndices) {
+		panic("bitset: AddSorted indices are not sorted")
+	}
+
+	words, ok := denseWords(s)
+	last := int(bitIndices[len(bitIndices)-1] / 64)
+	if !ok || last >= len(words) && preferSparse(popCount(words)+len(bitIndices), last+1) {
+		return s.Union(fromIndices(slices.Clip(slices.Compact(slices.Clone(bitIndices)))))
+	}
+
+	newBits := make([]uint64, max(len(words), last+1))
+	copy(newBits, words)
+	for _, i := range bitIndices {
+		newBits[i/64] |= 1 << (i % 64)
+	}
+	if r, ok := s.(retainedBitSet); ok {
+		return r.retain(newBits)
+	}
+	return fromWords(newBits)
+}
+
+// AppendWords appends the bits of s to dst as words, where bit i is bit i%64 of word i/64,
+// up to the word with the highest set bit, and returns the extended slice.
+func AppendWords(dst []uint64, s Set) []uint64 {
+	words := wordsOf(s)
+	lastIdx := len(words) - 1
+	for lastIdx >= 0 && words[lastIdx] == 0 {
+		lastIdx--
+	}
+	return append(dst, words[:(lastIdx+1)]...)
+}
+
+// AppendIndices appends the bit indices of the set bits of s to dst, in ascending order, and returns the extended slice.
+// Unlike collecting Indices, it allocates nothing when dst has enough capacity, so one buffer can be reused.
+func AppendIndices(dst []uint32, s Set) []uint32 {
+	switch s := materialized(s).(type) {
+	case bitSet64:
+		return appendBits(dst, uint64(s), 0)
+	case bitSet192:
+		for i, w := range s {
+			dst = appendBits(dst, w, uint32(i*64))
+		}
+		return dst
+	case sparseBitSet:
+		return append(dst, s...)
+	case chunkedBitSet:
+		for _, ch := range s.chunks {
+			dst = ch.c.appendTo(dst, uint32(ch.key)<<16)
+		}
+		return dst
+	case runBitSet:
+		for _, r := range s {
+			for i := r.start; ; i++ {
+				dst = append(dst, i)
+				if i == r.last {
+					break
+				}
+			}
+		}
+		return dst
+	case trieBitSet:
+		return appendTrieIndices(dst, s.root, s.height, 0)
+	}
+
+	words, _ := denseWords(s)
+	for i, w := range words {
+		dst = appendBits(dst, w, uint32(i*64))
+	}
+	return dst
+}
+
 // bitset.Builder provides a mutable interface for efficiently constructing a bitset
 // by setting the bits before creating the final immutable Set.
 //
@@ -41,12 +309,11 @@ type Builder interface {
 type bitSetBuilder []uint64
 
 func (b bitSet64) With(bitIndex uint32) Builder {
-	s := b.Set(bitIndex)
 	if bitIndex < 64 {
-		return s.(bitSet64)
+		return b | (1 << bitIndex)
 	}
 
-	return bitSetBuilder(s.(largeBitSet))
+	return bitSetBuilder(withBit([]uint64{uint64(b)}, bitIndex))
 }
 
 func (b bitSet64) WithMany(bitIndices ...uint32) Builder {
@@ -68,7 +335,7 @@ func (b bitSetBuilder) With(bitIndex uint32) Builder {
 		return b
 	}
 
-	return bitSetBuilder(largeBitSet(b).Set(bitIndex).(largeBitSet))
+	return bitSetBuilder(withBit(b, bitIndex))
 }
 
 func (b bitSetBuilder) WithMany(bitIndices ...uint32) Builder {
@@ -80,7 +347,7 @@ func (b bitSetBuilder) WithMany(bitIndices ...uint32) Builder {
 }
 
 func (b bitSetBuilder) Build() Set {
-	return largeBitSet(b)
+	return fromWords(b)
 }
 
 // NewBuilder creates and returns a new bitset.Builder with an initial bit capacity of at least minCapacity.
@@ -110,12 +377,19 @@ func (b bitSet64) Set(bitIndex uint32) Set {
 		return b | (1 << bitIndex)
 	}
 
-	// Upgrade to largeBitSet
-	idx := int(bitIndex / 64)
-	newBits := make([]uint64, idx+1)
-	newBits[0] = uint64(b)
-	newBits[idx] |= 1 << (bitIndex % 64)
-	return largeBitSet(newBits)
+	recordUpgrade()
+
+	// Upgrade to bitSet192
+	if bitIndex < 192 {
+		return bitSet192{uint64(b)}.Set(bitIndex)
+	}
+
+	// Upgrade to largeBitSet, or a compact representation if the new bit is far away
+	if preferSparse(bits.OnesCount64(uint64(b))+1, int(bitIndex/64)+1) {
+		return fromIndices(sparseBitSet(appendBits(nil, uint64(b), 0)).insert(bitIndex))
+	}
+
+	return fromWords(withBit([]uint64{uint64(b)}, bitIndex))
 }
 
 func (b bitSet64) Clear(bitIndex uint32) Set {
@@ -126,8 +400,143 @@ func (b bitSet64) Clear(bitIndex uint32) Set {
 	return b &^ (1 << bitIndex)
 }
 
-// Large (>64 bits)
-type largeBitSet []uint64 // immutable - always copied on modification
+func (b bitSet64) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b bitSet64) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b bitSet64) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b bitSet64) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b bitSet64) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+func (b bitSet64) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldWords([]uint64{uint64(b)}, 0, yield)
+	}
+}
+
+func (b bitSet64) SizeInBytes() int {
+	return int(unsafe.Sizeof(b))
+}
+
+func (b bitSet64) Kind() Kind {
+	return KindSmall
+}
+
+func (b bitSet64) Key() Key {
+	return Key(appendWords(newKey(KindSmall, 8), []uint64{uint64(b)}))
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b bitSet64) wordLen() int {
+	if b == 0 {
+		return 0
+	}
+	return 1
+}
+
+// Medium (≤192 bits)
+type bitSet192 [3]uint64 // at least one of the upper two words is nonzero
+
+func (b bitSet192) Test(bitIndex uint32) bool {
+	if bitIndex >= 192 {
+		return false
+	}
+
+	return b[bitIndex/64]&(1<<(bitIndex%64)) != 0
+}
+
+func (b bitSet192) Set(bitIndex uint32) Set {
+	if bitIndex < 192 {
+		b[bitIndex/64] |= 1 << (bitIndex % 64)
+		return b
+	}
+
+	recordUpgrade()
+
+	// Upgrade to largeBitSet, or a compact representation if the new bit is far away
+	if preferSparse(popCount(b[:])+1, int(bitIndex/64)+1) {
+		return fromIndices(sparseFromWords(b[:]).insert(bitIndex))
+	}
+
+	return fromWords(withBit(b[:], bitIndex))
+}
+
+func (b bitSet192) Clear(bitIndex uint32) Set {
+	if bitIndex >= 192 {
+		return b
+	}
+
+	b[bitIndex/64] &^= 1 << (bitIndex % 64)
+	if b[1] == 0 && b[2] == 0 {
+		recordDowngrade()
+		return bitSet64(b[0])
+	}
+	return b
+}
+
+func (b bitSet192) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b bitSet192) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b bitSet192) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b bitSet192) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b bitSet192) Count() int {
+	return popCount(b[:])
+}
+
+func (b bitSet192) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldWords(b[:], 0, yield)
+	}
+}
+
+func (b bitSet192) SizeInBytes() int {
+	return int(unsafe.Sizeof(b))
+}
+
+func (b bitSet192) Kind() Kind {
+	return KindMedium
+}
+
+func (b bitSet192) Key() Key {
+	return Key(appendWords(newKey(KindMedium, 24), b[:]))
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b bitSet192) wordLen() int {
+	if b[2] == 0 {
+		return 2
+	}
+	return 3
+}
+
+// Large (>192 bits)
+//
+// A largeBitSet never spans trieMinWords words or more, i.e. 8 KiB, so Count scans at most that many words.
+// Bigger dense sets are trieBitSets, which keep their count and highest word up to date on every change.
+type largeBitSet []uint64 // immutable - always copied on modification. No trailing zero words
 
 func (b largeBitSet) Test(bitIndex uint32) bool {
 	idx := int(bitIndex / 64)
@@ -139,37 +548,180 @@ func (b largeBitSet) Test(bitIndex uint32) bool {
 }
 
 func (b largeBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
 	idx := int(bitIndex / 64)
-	newBits := make([]uint64, max(len(b), idx+1))
-	copy(newBits, b)
-	newBits[idx] |= 1 << (bitIndex % 64)
-	return largeBitSet(newBits)
+	if idx >= len(b) && preferSparse(popCount(b)+1, idx+1) {
+		return fromIndices(sparseFromWords(b).insert(bitIndex))
+	}
+
+	return fromWords(withBit(b, bitIndex))
 }
 
 func (b largeBitSet) Clear(bitIndex uint32) Set {
-	idx := int(bitIndex / 64)
-	if idx >= len(b) {
+	if !b.Test(bitIndex) {
 		return b
 	}
 
-	lastIdx := len(b) - 1
-	for lastIdx >= 0 && (b[lastIdx] == 0 || (lastIdx == idx && b[lastIdx] == 1<<(bitIndex%64))) {
-		lastIdx--
-	}
-
-	if lastIdx <= 0 {
-		b := b[0]
-		if b != 0 && idx == 0 {
-			b &^= (1 << bitIndex)
+	idx := int(bitIndex / 64)
+	bits := b
+	if idx == len(b)-1 {
+		// Only clearing a bit of the last word can leave trailing zero words
+		lastIdx := idx
+		for lastIdx >= 0 && (b[lastIdx] == 0 || (lastIdx == idx && b[lastIdx] == 1<<(bitIndex%64))) {
+			lastIdx--
+		}
+		bits = b[:(lastIdx + 1)]
+		if len(bits) <= 1 {
+			recordDowngrade()
+			return fromWords(bits)
 		}
-		return bitSet64(b)
 	}
 
-	bits := b[:(lastIdx + 1)]
 	newBits := make([]uint64, len(bits))
 	copy(newBits, bits)
 	if idx < len(newBits) {
 		newBits[idx] &^= 1 << (bitIndex % 64)
 	}
-	return largeBitSet(newBits)
+	recordCopy(len(newBits))
+	s := fromWords(newBits)
+	if _, ok := s.(largeBitSet); !ok {
+		recordDowngrade()
+	}
+	return s
+}
+
+func (b largeBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b largeBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b largeBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b largeBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b largeBitSet) Count() int {
+	return popCount(b)
+}
+
+func (b largeBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldWords(b, 0, yield)
+	}
+}
+
+func (b largeBitSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(b)) + 8*cap(b)
+}
+
+func (b largeBitSet) Kind() Kind {
+	return KindDense
+}
+
+func (b largeBitSet) Key() Key {
+	return Key(appendWords(newKey(KindDense, 8*len(b)), b))
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b largeBitSet) wordLen() int {
+	return len(b)
+}
+
+// withBit returns a copy of words, grown if needed, with the bit for the given bit index set.
+func withBit(words []uint64, bitIndex uint32) []uint64 {
+	idx := int(bitIndex / 64)
+	newBits := make([]uint64, max(len(words), idx+1))
+	copy(newBits, words)
+	newBits[idx] |= 1 << (bitIndex % 64)
+	recordCopy(len(newBits))
+	return newBits
+}
+
+// fromWords returns the most suitable Set representation for the given bits.
+// words is not copied, and must not be modified afterwards.
+func fromWords(words []uint64) Set {
+	lastIdx := len(words) - 1
+	for lastIdx >= 0 && words[lastIdx] == 0 {
+		lastIdx--
+	}
+
+	switch {
+	case lastIdx < 0:
+		return bitSet64(0)
+	case lastIdx == 0:
+		return bitSet64(words[0])
+	case lastIdx < 3:
+		var b bitSet192
+		copy(b[:], words)
+		return b
+	}
+
+	words = words[:(lastIdx + 1)]
+	return fromCountedWords(words, popCount(words), runCount(words))
+}
+
+// fromCountedWords is fromWords for more than 3 words without trailing zero words, with n set bits in r runs.
+func fromCountedWords(words []uint64, n, r int) Set {
+	switch {
+	case preferRuns(n, r, len(words)):
+		return runBitSet(appendWordRuns(make([]run[uint32], 0, r), words, 0))
+	case !preferSparse(n, len(words)):
+		return denseFromWords(words, n, r)
+	case n > maxSparseLen:
+		return chunkedFromWords(words)
+	}
+	return sparseFromWords(words)
+}
+
+// isEmpty reports whether s has no set bits.
+func isEmpty(s Set) bool {
+	s = materialized(s)
+	if b, ok := s.(retainedBitSet); ok {
+		return slices.IndexFunc(b.words, func(w uint64) bool { return w != 0 }) < 0
+	}
+	// Every other representation, including a materialized view, is canonical, so an empty set is always bitSet64(0)
+	return s == bitSet64(0)
+}
+
+// yieldWords calls yield with every set bit in words in ascending order, until yield returns false.
+// Bit i of words[0] has the bit index base+i. It reports whether yield always returned true.
+func yieldWords(words []uint64, base uint32, yield func(uint32) bool) bool {
+	for i, w := range words {
+		for ; w != 0; w &= w - 1 {
+			if !yield(base + uint32(i*64+bits.TrailingZeros64(w))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// popCount returns the number of set bits in words, or math.MaxInt if that does not fit in an int.
+func popCount(words []uint64) int {
+	if strconv.IntSize == 64 {
+		return popCountWords(words)
+	}
+
+	// Count in blocks that can't overflow an int on 32-bit platforms
+	n := uint64(0)
+	for len(words) > 0 {
+		k := min(len(words), 1<<24)
+		n += uint64(popCountWords(words[:k]))
+		words = words[k:]
+	}
+	return clampCount(n)
+}
+
+// clampCount returns n as an int, or math.MaxInt if it does not fit, which only happens on 32-bit platforms.
+func clampCount(n uint64) int {
+	return int(min(n, math.MaxInt))
 }
@@ -4,7 +4,10 @@
 package bitset
 
 import (
+	"math"
+	"slices"
 	"testing"
+	"unsafe"
 )
 
 func TestBitSet64(t *testing.T) {
@@ -56,7 +59,7 @@ func TestBitSetUpgradeAndDowngrade(t *testing.T) {
 	bs = bs.Set(10)
 
 	// Upgrade to largeBitSet
-	largeBs := bs.Set(100)
+	largeBs := bs.Set(200)
 
 	if _, ok := largeBs.(largeBitSet); !ok {
 		t.Fatalf("BitSet should have upgraded to largeBitSet, but got %T", largeBs)
@@ -65,30 +68,74 @@ func TestBitSetUpgradeAndDowngrade(t *testing.T) {
 	if !largeBs.Test(10) {
 		t.Error("Upgraded set should retain old bits")
 	}
-	if !largeBs.Test(100) {
+	if !largeBs.Test(200) {
 		t.Error("Upgraded set should have the new bit")
 	}
 
 	// Test immutability during upgrade
-	if bs.Test(100) {
+	if bs.Test(200) {
 		t.Error("Original bitSet64 should not be modified during upgrade")
 	}
 
 	// Downgrade back to bitSet64
-	downgradedBs := largeBs.Clear(100)
+	downgradedBs := largeBs.Clear(200)
 	if _, ok := downgradedBs.(bitSet64); !ok {
 		t.Fatalf("Set should have downgraded to bitSet64, but got %T", downgradedBs)
 	}
-	if !downgradedBs.Test(10) || downgradedBs.Test(100) {
+	if !downgradedBs.Test(10) || downgradedBs.Test(200) {
 		t.Error("Downgraded set has incorrect bits")
 	}
 
 	// Test immutability during downgrade
-	if !largeBs.Test(100) {
+	if !largeBs.Test(200) {
 		t.Error("Original largeBitSet should not be modified during downgrade")
 	}
 }
 
+func TestBitSet192(t *testing.T) {
+	bs := New().Set(10).Set(100)
+
+	if _, ok := bs.(bitSet192); !ok {
+		t.Fatalf("BitSet should have upgraded to bitSet192, but got %T", bs)
+	}
+	if !bs.Test(10) || !bs.Test(100) || bs.Test(191) || bs.Test(300) {
+		t.Error("bitSet192 has incorrect bits")
+	}
+
+	// Test immutability on Set
+	bs2 := bs.Set(191)
+	if bs.Test(191) {
+		t.Error("Original bitSet192 should not be modified after Set")
+	}
+	if !bs2.Test(191) || !bs2.Test(100) {
+		t.Error("New bitSet192 should have old and new bits")
+	}
+
+	// Clearing a high bit is a no-op
+	if bs3 := bs2.Clear(500); bs3 != bs2 {
+		t.Error("Clearing a bit >= 192 from bitSet192 should be a no-op")
+	}
+
+	// Upgrade to largeBitSet
+	large := bs2.Set(200)
+	if _, ok := large.(largeBitSet); !ok {
+		t.Fatalf("BitSet should have upgraded to largeBitSet, but got %T", large)
+	}
+	if !large.Test(10) || !large.Test(191) || !large.Test(200) {
+		t.Error("Upgraded set should retain old bits")
+	}
+
+	// Downgrade back to bitSet192, then bitSet64
+	medium := large.Clear(200)
+	if medium != bs2 {
+		t.Errorf("Set should have downgraded to bitSet192, but got %T %v", medium, medium)
+	}
+	small := medium.Clear(100).Clear(191)
+	if small != bitSet64(1<<10) {
+		t.Errorf("Set should have downgraded to bitSet64, but got %T %v", small, small)
+	}
+}
+
 func TestLargeBitSet(t *testing.T) {
 	// Start with a large set
 	var bs Set = New().Set(100)
@@ -112,6 +159,56 @@ func TestLargeBitSet(t *testing.T) {
 	}
 }
 
+func TestSetExistingBit(t *testing.T) {
+	bs := New().Set(100).Set(200).(largeBitSet)
+	if bs2 := bs.Set(200).(largeBitSet); &bs2[0] != &bs[0] {
+		t.Error("Set of a bit that is already set should return the receiver")
+	}
+
+	rs := WithShrinkPolicy(bs, ShrinkNever).(retainedBitSet)
+	if rs2 := rs.Set(100).(retainedBitSet); &rs2.words[0] != &rs.words[0] {
+		t.Error("Set of a bit that is already set should return the receiver")
+	}
+}
+
+func TestClearUnsetBit(t *testing.T) {
+	bs := New().Set(100).Set(200).Set(300).(largeBitSet)
+	for _, i := range []uint32{0, 101, 299, 1000} {
+		if bs2, ok := bs.Clear(i).(largeBitSet); !ok || &bs2[0] != &bs[0] {
+			t.Errorf("Clear(%d) of a bit that is not set should return the receiver", i)
+		}
+	}
+
+	rs := WithShrinkPolicy(bs, ShrinkNever).(retainedBitSet)
+	if rs2 := rs.Clear(101).(retainedBitSet); &rs2.words[0] != &rs.words[0] {
+		t.Error("Clear of a bit that is not set should return the receiver")
+	}
+
+	// Clearing a bit before the last word keeps the length
+	if got := bs.Set(1).Clear(100); got.Test(100) || !got.Test(1) || !got.Test(300) {
+		t.Errorf("Clear(100) = %v", slices.Collect(got.Indices()))
+	}
+}
+
+func TestLargeBitSetMaxWords(t *testing.T) {
+	words := make([]uint64, trieMinWords)
+	for i := range trieMinWords - 1 {
+		words[i] = 0x5555555555555555
+	}
+	if bs, ok := fromWords(words).(largeBitSet); !ok || len(bs) != trieMinWords-1 {
+		t.Fatalf("Expected a largeBitSet of %d words, got %T", trieMinWords-1, fromWords(words))
+	}
+
+	words[trieMinWords-1] = 1
+	bs, ok := fromWords(words).(trieBitSet)
+	if !ok {
+		t.Fatalf("Expected a trieBitSet at %d words, got %T", trieMinWords, fromWords(words))
+	}
+	if bs.Count() != 32*(trieMinWords-1)+1 || bs.wordLen() != trieMinWords {
+		t.Errorf("trieBitSet should track its count and width, got %d and %d", bs.Count(), bs.wordLen())
+	}
+}
+
 func TestLargeBitSetDowngrade(t *testing.T) {
 	// Create a set that will downgrade upon removal
 	bs := New().Set(5).Set(70)
@@ -163,14 +260,14 @@ func TestBitSetBuilder(t *testing.T) {
 
 	t.Run("Small (bitSet64) builder that upgrades", func(t *testing.T) {
 		b := NewBuilder(0)
-		b = b.With(10).With(100)
+		b = b.With(10).With(200)
 
 		if _, ok := b.(bitSetBuilder); !ok {
 			t.Fatalf("Builder should have transitioned to bitSetBuilderImpl, but is %T", b)
 		}
 
 		bs := b.Build()
-		if !bs.Test(10) || !bs.Test(100) {
+		if !bs.Test(10) || !bs.Test(200) {
 			t.Error("Set built after builder upgrade has incorrect bits")
 		}
 		if _, ok := bs.(largeBitSet); !ok {
@@ -207,23 +304,23 @@ func TestBitSetBuilder(t *testing.T) {
 
 func TestLargeBitSetClearAndShrink(t *testing.T) {
 	// Create a set with gaps to test slice trimming
-	// bits will be {0, bit for 70, 0, bit for 200}
-	bs := New().Set(70).Set(200)
+	// bits will be {0, bit for 70, bit for 130, bit for 200, bit for 260, 0, 0, bit for 450}
+	bs := New().Set(70).Set(130).Set(200).Set(260).Set(450)
 
-	// Clear 200, which should shrink the backing slice
-	bs2 := bs.Clear(200)
+	// Clear 450, which should shrink the backing slice
+	bs2 := bs.Clear(450)
 
-	if !bs2.Test(70) {
-		t.Error("Set should still have bit 70 after shrinking")
+	if !bs2.Test(70) || !bs2.Test(260) {
+		t.Error("Set should still have bits 70 and 260 after shrinking")
 	}
-	if bs2.Test(200) {
-		t.Error("Set should not have bit 200 after removal")
+	if bs2.Test(450) {
+		t.Error("Set should not have bit 450 after removal")
 	}
 
 	// Verify internal slice length (by casting)
 	if lbs, ok := bs2.(largeBitSet); ok {
-		// Expect length 2 for bits up to 127
-		expectedLen := (70 / 64) + 1
+		// Expect length 5 for bits up to 319
+		expectedLen := (260 / 64) + 1
 		if len(lbs) != expectedLen {
 			t.Errorf("Backing slice did not shrink correctly. want len %d, got %d", expectedLen, len(lbs))
 		}
@@ -231,3 +328,330 @@ func TestLargeBitSetClearAndShrink(t *testing.T) {
 		t.Errorf("Expected largeBitSet after shrinking, but got %T", bs2)
 	}
 }
+
+func TestUnsafeFromWords(t *testing.T) {
+	words := []uint64{1, 0, 1 << 63, 0xff, 0, 0}
+	bs := UnsafeFromWords(words)
+
+	lbs, ok := bs.(largeBitSet)
+	if !ok {
+		t.Fatalf("Expected largeBitSet, but got %T", bs)
+	}
+	if len(lbs) != 4 {
+		t.Errorf("Trailing zero words should have been trimmed, got len %d", len(lbs))
+	}
+	if &lbs[0] != &words[0] {
+		t.Error("Words should have been adopted without copying")
+	}
+	if !bs.Test(0) || !bs.Test(191) || !bs.Test(192) || bs.Test(1) {
+		t.Error("Set from words has incorrect bits")
+	}
+
+	if bs := UnsafeFromWords([]uint64{0, 0}); bs != bitSet64(0) {
+		t.Errorf("Expected empty bitSet64, but got %T %v", bs, bs)
+	}
+}
+
+func TestUnsafeFromWordsHuge(t *testing.T) {
+	words := make([]uint64, 4*trieMinWords)
+	for i := range words {
+		words[i] = 0x5555555555555555
+	}
+	words = append(words, 0, 0)
+
+	bs := UnsafeFromWords(words)
+	if bs.Kind() != KindMapped || bs.Count() != 32*4*trieMinWords {
+		t.Fatalf("Expected the words to be adopted as a mapped set, got %v with %d bits", bs.Kind(), bs.Count())
+	}
+	// The words are aliased, not copied
+	words[10] |= 2
+	if !bs.Test(10*64 + 1) {
+		t.Error("Expected a change to the words to be visible in the set")
+	}
+	if n := testing.AllocsPerRun(10, func() { UnsafeFromWords(words) }); n > 1 {
+		t.Errorf("UnsafeFromWords allocated %v times, expected at most once for the interface", n)
+	}
+
+	// Modifications copy the words, and leave them unchanged
+	if s := bs.Set(3); !s.Test(3) || words[0]&(1<<3) != 0 {
+		t.Error("Set on an adopted set should copy the words")
+	}
+	if err := CheckInvariants(bs); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSizeInBytes(t *testing.T) {
+	// Headers are measured, as they are smaller on 32-bit platforms
+	sliceHeader := int(unsafe.Sizeof([]uint64{}))
+	tests := []struct {
+		name string
+		s    Set
+		want int
+	}{
+		{"bitSet64", New().Set(5), 8},
+		{"bitSet192", New().Set(100), 24},
+		{"largeBitSet", largeBitSet(make([]uint64, 5, 8)), sliceHeader + 8*8},
+		{"sparseBitSet", sparseBitSet{1, 1_000_000}, sliceHeader + 4*2},
+		{"runBitSet", runBitSet{{0, 1_000_000}}, sliceHeader + 8},
+		{"retainedBitSet", retainedBitSet{words: make([]uint64, 3)}, int(unsafe.Sizeof(retainedBitSet{})) + 8*3},
+	}
+	for _, tt := range tests {
+		if got := tt.s.SizeInBytes(); got != tt.want {
+			t.Errorf("%s: want %d bytes, got %d", tt.name, tt.want, got)
+		}
+	}
+
+	// Each chunk of a chunkedBitSet holding 5000 scattered bits is an arrayContainer
+	chunked := testSets()[6].s.(chunkedBitSet)
+	want := int(unsafe.Sizeof(chunked)) + int(unsafe.Sizeof(chunk{}))*cap(chunked.chunks)
+	for _, ch := range chunked.chunks {
+		want += sliceHeader + 2*cap(ch.c.(arrayContainer))
+	}
+	if got := chunked.SizeInBytes(); got != want {
+		t.Errorf("chunkedBitSet: want %d bytes, got %d", want, got)
+	}
+
+	// A trieBitSet with 1100 words has a root with 3 child nodes, and 69 leaves
+	trie := everyThird(1100 * 64).(trieBitSet)
+	if got, want := trie.SizeInBytes(), int(unsafe.Sizeof(trie))+4*int(unsafe.Sizeof(trieNode{}))+69*128; got != want {
+		t.Errorf("trieBitSet: want %d bytes, got %d", want, got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	for _, ts := range testSets() {
+		want := len(ts.bits)
+		for _, r := range ts.runs {
+			want += int(r.last-r.start) + 1
+		}
+		if got := ts.s.Count(); got != want {
+			t.Errorf("%s: want %d bits, got %d", ts.name, want, got)
+		}
+	}
+}
+
+func TestTestAndSet(t *testing.T) {
+	for _, ts := range testSets() {
+		for _, i := range []uint32{0, 1, 64, 299, 70_001, 3_000_050} {
+			s, changed := TestAndSet(ts.s, i)
+			if changed == ts.s.Test(i) || !s.Test(i) {
+				t.Errorf("%s: TestAndSet(%d) = %v, should have changed: %v", ts.name, i, changed, !ts.s.Test(i))
+			}
+			if s.Key() != ts.s.Set(i).Key() {
+				t.Errorf("%s: TestAndSet(%d) differs from Set", ts.name, i)
+			}
+
+			s, changed = TestAndClear(ts.s, i)
+			if changed != ts.s.Test(i) || s.Test(i) {
+				t.Errorf("%s: TestAndClear(%d) = %v, should have changed: %v", ts.name, i, changed, ts.s.Test(i))
+			}
+			if s.Key() != ts.s.Clear(i).Key() {
+				t.Errorf("%s: TestAndClear(%d) differs from Clear", ts.name, i)
+			}
+		}
+	}
+}
+
+func TestSwapBits(t *testing.T) {
+	pairs := [][2]uint32{{1, 2}, {5, 5}, {0, 63}, {64, 150}, {3, 299}, {6, 1_000_000}, {10, 3_000_050}, {7, 1 << 31}}
+	for _, ts := range testSets() {
+		for _, p := range pairs {
+			i, j := p[0], p[1]
+			got := SwapBits(ts.s, i, j)
+			want := ts.s.Clear(i).Clear(j)
+			if ts.s.Test(i) {
+				want = want.Set(j)
+			}
+			if ts.s.Test(j) {
+				want = want.Set(i)
+			}
+			if got.Key() != want.Key() {
+				t.Errorf("%s: SwapBits(%d, %d) = %v bits, want %v", ts.name, i, j, got.Count(), want.Count())
+			}
+			checkCanonical(t, got)
+			if SwapBits(got, j, i).Key() != ts.s.Key() {
+				t.Errorf("%s: swapping %d and %d twice should restore the set", ts.name, i, j)
+			}
+		}
+	}
+
+	rs := WithShrinkPolicy(New().Set(5).Set(700), ShrinkNever)
+	if got := SwapBits(rs, 700, 6); got.Kind() != KindRetained || !got.Test(6) || got.Test(700) {
+		t.Errorf("SwapBits on a retained set = %v", got.Kind())
+	}
+}
+
+func TestAddSorted(t *testing.T) {
+	indices := []uint32{3, 64, 64, 65, 300, 1 << 20}
+	for _, ts := range testSets() {
+		got := AddSorted(ts.s, indices)
+		want := ts.s
+		for _, i := range indices {
+			want = want.Set(i)
+		}
+		if got.Key() != want.Key() {
+			t.Errorf("%s: AddSorted = %v, want %v", ts.name, slices.Collect(got.Indices()), slices.Collect(want.Indices()))
+		}
+		checkCanonical(t, got)
+	}
+
+	s := New().Set(1)
+	if got := AddSorted(s, nil); got != s {
+		t.Error("AddSorted with no indices should return s")
+	}
+
+	rs := WithShrinkPolicy(New(), ShrinkNever)
+	if got := AddSorted(rs, []uint32{5, 700}); got.Kind() != KindRetained || !got.Test(700) {
+		t.Errorf("AddSorted on a retained set = %v", got.Kind())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("AddSorted should panic on unsorted indices")
+		}
+	}()
+	AddSorted(s, []uint32{5, 2})
+}
+
+func TestAppendWords(t *testing.T) {
+	for _, ts := range testSets() {
+		words := AppendWords([]uint64{42}, ts.s)
+		if words[0] != 42 {
+			t.Fatalf("%s: AppendWords overwrote dst", ts.name)
+		}
+		if len(words) > 1 && words[len(words)-1] == 0 {
+			t.Errorf("%s: AppendWords appended trailing zero words", ts.name)
+		}
+		if got := UnsafeFromWords(words[1:]); got.Key() != ts.s.Key() {
+			t.Errorf("%s: words don't round trip, got %T", ts.name, got)
+		}
+	}
+
+	retained := NewWithShrinkPolicy(ShrinkNever).Set(1).Set(1000).Clear(1000)
+	if words := AppendWords(nil, retained); len(words) != 1 || words[0] != 2 {
+		t.Errorf("Expected a single word without trailing zero words, got %v", words)
+	}
+}
+
+func TestCountFullRange(t *testing.T) {
+	// The count doesn't fit in an int on 32-bit platforms
+	full := fromRuns([]run[uint32]{{0, math.MaxUint32}})
+	if got, want := full.Count(), int(min(1<<32, uint64(math.MaxInt))); got != want {
+		t.Errorf("Count of every bit = %d, want %d", got, want)
+	}
+	if got := full.Clear(5).Count(); got != int(min(1<<32-1, uint64(math.MaxInt))) {
+		t.Errorf("Count of every bit but one = %d", got)
+	}
+}
+
+func TestAppendIndices(t *testing.T) {
+	for _, ts := range testSets() {
+		prefix := []uint32{7, 8}
+		got := AppendIndices(prefix, ts.s)
+		if want := append([]uint32{7, 8}, slices.Collect(ts.s.Indices())...); !slices.Equal(got, want) {
+			t.Errorf("%s: AppendIndices returned %d indices, want %d", ts.name, len(got), len(want))
+		}
+
+		buf := make([]uint32, 0, ts.s.Count())
+		if allocs := testing.AllocsPerRun(5, func() { buf = AppendIndices(buf[:0], ts.s) }); allocs != 0 {
+			t.Errorf("%s: AppendIndices into a buffer with enough capacity allocated %v times", ts.name, allocs)
+		}
+	}
+}
+
+func TestFull(t *testing.T) {
+	for _, n := range []uint32{0, 1, 63, 64, 65, 192, 193, 1000, 1 << 20, math.MaxUint32} {
+		s := Full(n)
+		if err := CheckInvariants(s); err != nil {
+			t.Errorf("Full(%d): %v", n, err)
+		}
+		if s.Count() != clampCount(uint64(n)) {
+			t.Errorf("Full(%d) has %d bits", n, s.Count())
+		}
+		if n > 0 && !s.Test(n-1) || s.Test(n) {
+			t.Errorf("Full(%d) should have bits up to %d", n, n-1)
+		}
+	}
+
+	if got := Full(1000).Difference(FromIndices(3, 999)); got.Count() != 998 || got.Test(3) {
+		t.Errorf("subtracting from Full(1000) = %d bits", got.Count())
+	}
+}
+
+func TestFromStride(t *testing.T) {
+	cases := [][3]uint32{
+		{0, 2, 0}, {7, 0, 1}, {math.MaxUint32, math.MaxUint32, 1}, {0, 1, 1000}, {5, 2, 100}, {3, 8, 1000},
+		{100, 3, 50_000}, {1, 64, 70}, {65_000, 7, 300_000}, {10, 96, 5000}, {0, 1000, 100}, {0, 200, 3},
+		{math.MaxUint32 - 30, 10, 4},
+	}
+	for _, c := range cases {
+		start, step, count := c[0], c[1], c[2]
+		indices := make([]uint32, count)
+		for k := range indices {
+			indices[k] = start + uint32(k)*step
+		}
+
+		got := FromStride(start, step, count)
+		if got.Key() != FromIndices(indices...).Key() {
+			t.Errorf("FromStride(%d, %d, %d) has %d bits, want %d", start, step, count, got.Count(), count)
+		}
+		if err := CheckInvariants(got); err != nil {
+			t.Errorf("FromStride(%d, %d, %d): %v", start, step, count, err)
+		}
+	}
+
+	for _, c := range [][3]uint32{{0, 0, 2}, {10, 1 << 31, 3}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("FromStride(%d, %d, %d) should panic", c[0], c[1], c[2])
+				}
+			}()
+			FromStride(c[0], c[1], c[2])
+		}()
+	}
+}
+
+func TestFromIndices(t *testing.T) {
+	bs := FromIndices(2_000_000, 5, 5, 3_000_000_000, 1)
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet for scattered indices, but got %T", bs)
+	}
+	if bs.Count() != 4 || !bs.Test(1) || !bs.Test(5) || !bs.Test(2_000_000) || !bs.Test(3_000_000_000) {
+		t.Error("Set from indices has incorrect bits")
+	}
+
+	if bs := FromIndices(3, 1, 2); bs != bitSet64(0b1110) {
+		t.Errorf("Expected bitSet64, but got %T %v", bs, bs)
+	}
+	if bs := FromIndices(); bs != bitSet64(0) {
+		t.Errorf("Expected empty bitSet64, but got %T %v", bs, bs)
+	}
+}
+
+func TestIndices(t *testing.T) {
+	for _, ts := range testSets() {
+		n, prev := 0, -1
+		for i := range ts.s.Indices() {
+			if int(i) <= prev || !ts.has(i) {
+				t.Fatalf("%s: unexpected bit %d after %d", ts.name, i, prev)
+			}
+			n, prev = n+1, int(i)
+		}
+		if n != ts.s.Count() {
+			t.Errorf("%s: expected %d bits, got %d", ts.name, ts.s.Count(), n)
+		}
+
+		// Stopping early
+		n = 0
+		for range ts.s.Indices() {
+			if n++; n == 2 {
+				break
+			}
+		}
+		if want := min(2, ts.s.Count()); n != want {
+			t.Errorf("%s: expected to stop after %d bits, got %d", ts.name, want, n)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrOutOfRange is returned when a bit index is outside the universe of a Bounded set.
+var ErrOutOfRange = errors.New("bitset: bit index out of range")
+
+// bitset.Bounded is an immutable bit set whose bit indices are limited to a fixed universe [0, size).
+//
+// Setting a bit outside the universe returns ErrOutOfRange, or panics in strict mode,
+// instead of silently growing the set, so that corrupted indices are caught early.
+type Bounded struct {
+	s      Set
+	size   uint32
+	strict bool
+}
+
+// NewBounded creates and returns a new empty bitset.Bounded with bit indices in [0, size).
+// Operations that would set a bit outside this range return ErrOutOfRange.
+func NewBounded(size uint32) Bounded {
+	return Bounded{s: New(), size: size}
+}
+
+// NewStrictBounded creates and returns a new empty bitset.Bounded with bit indices in [0, size).
+// Operations that would set a bit outside this range panic instead of returning an error.
+func NewStrictBounded(size uint32) Bounded {
+	return Bounded{s: New(), size: size, strict: true}
+}
+
+// Domain returns the universe of b, to mint other sets in the same universe.
+func (b Bounded) Domain() Domain {
+	return Domain{size: b.size, strict: b.strict}
+}
+
+// Size returns the number of bit indices in the universe of b.
+func (b Bounded) Size() uint32 {
+	return b.size
+}
+
+// Bits returns the bits of b as a bitset.Set.
+func (b Bounded) Bits() Set {
+	return b.s
+}
+
+// Test reports whether the bit for the given bit index is set.
+func (b Bounded) Test(bitIndex uint32) bool {
+	return b.s.Test(bitIndex)
+}
+
+// Set returns a new bitset.Bounded with the bit for the given bit index set,
+// or ErrOutOfRange if the bit index is outside the universe of b.
+// The original bitset.Bounded is not modified.
+func (b Bounded) Set(bitIndex uint32) (Bounded, error) {
+	if bitIndex >= b.size {
+		return b, b.fail(fmt.Errorf("%w: %d is not in [0, %d)", ErrOutOfRange, bitIndex, b.size))
+	}
+
+	b.s = b.s.Set(bitIndex)
+	return b, nil
+}
+
+// With returns a new bitset.Bounded with all the bits for the given bit indices set,
+// or ErrOutOfRange if any bit index is outside the universe of b, in which case none of them are set.
+// The original bitset.Bounded is not modified.
+func (b Bounded) With(bitIndices ...uint32) (Bounded, error) {
+	for _, i := range bitIndices {
+		if i >= b.size {
+			return b, b.fail(fmt.Errorf("%w: %d is not in [0, %d)", ErrOutOfRange, i, b.size))
+		}
+	}
+
+	b.s = b.s.Union(FromIndices(bitIndices...))
+	return b, nil
+}
+
+// Clear returns a new bitset.Bounded with the bit for the given bit index cleared,
+// or ErrOutOfRange if the bit index is outside the universe of b.
+// The original bitset.Bounded is not modified.
+func (b Bounded) Clear(bitIndex uint32) (Bounded, error) {
+	if bitIndex >= b.size {
+		return b, b.fail(fmt.Errorf("%w: %d is not in [0, %d)", ErrOutOfRange, bitIndex, b.size))
+	}
+
+	b.s = b.s.Clear(bitIndex)
+	return b, nil
+}
+
+// Complement returns a new bitset.Bounded with the bits of the universe of b that are not set in b.
+// The original bitset.Bounded is not modified.
+func (b Bounded) Complement() Bounded {
+	b.s = Full(b.size).Difference(b.s)
+	return b
+}
+
+// IsFull reports whether every bit in the universe of b is set.
+func (b Bounded) IsFull() bool {
+	// b has no bits outside its universe, so it is full if it has as many bits, unless Count is clamped on 32-bit platforms
+	if uint64(b.size) < math.MaxInt {
+		return b.s.Count() == int(b.size)
+	}
+	return isEmpty(b.Complement().s)
+}
+
+// Union returns a new bitset.Bounded with the bits that are set in either b or other,
+// or ErrOutOfRange if other has bits outside the universe of b.
+// Neither set is modified.
+func (b Bounded) Union(other Set) (Bounded, error) {
+	if err := b.check(other); err != nil {
+		return b, err
+	}
+
+	b.s = b.s.Union(other)
+	return b, nil
+}
+
+// Intersect returns a new bitset.Bounded with the bits that are set in both b and other.
+// Neither set is modified.
+func (b Bounded) Intersect(other Set) Bounded {
+	b.s = b.s.Intersect(other)
+	return b
+}
+
+// Difference returns a new bitset.Bounded with the bits that are set in b but not in other.
+// Neither set is modified.
+func (b Bounded) Difference(other Set) Bounded {
+	b.s = b.s.Difference(other)
+	return b
+}
+
+// SymmetricDifference returns a new bitset.Bounded with the bits that are set in exactly one of b and other,
+// or ErrOutOfRange if other has bits outside the universe of b.
+// Neither set is modified.
+func (b Bounded) SymmetricDifference(other Set) (Bounded, error) {
+	if err := b.check(other); err != nil {
+		return b, err
+	}
+
+	b.s = b.s.SymmetricDifference(other)
+	return b, nil
+}
+
+// check returns ErrOutOfRange if s has bits outside the universe of b.
+func (b Bounded) check(s Set) error {
+	if !isEmpty(s.Difference(Full(b.size))) {
+		return b.fail(fmt.Errorf("%w: set has bits outside [0, %d)", ErrOutOfRange, b.size))
+	}
+	return nil
+}
+
+// fail returns err, or panics with it if b is strict.
+func (b Bounded) fail(err error) error {
+	if b.strict {
+		panic(err)
+	}
+	return err
+}
+
+// bitset.Domain is a fixed universe of bit indices [0, size), e.g. the ids of a table with a known number of rows.
+//
+// It mints Bounded sets in its universe, which can be complemented and checked for being full,
+// unlike sets without an explicit universe.
+type Domain struct {
+	size   uint32
+	strict bool
+}
+
+// NewDomain returns a bitset.Domain with bit indices in [0, size), whose sets return ErrOutOfRange for bit indices
+// outside it.
+func NewDomain(size uint32) Domain {
+	return Domain{size: size}
+}
+
+// NewStrictDomain returns a bitset.Domain with bit indices in [0, size), whose sets panic for bit indices outside it.
+func NewStrictDomain(size uint32) Domain {
+	return Domain{size: size, strict: true}
+}
+
+// Size returns the number of bit indices in d.
+func (d Domain) Size() uint32 {
+	return d.size
+}
+
+// Empty returns a new empty bitset.Bounded in d.
+func (d Domain) Empty() Bounded {
+	return Bounded{s: New(), size: d.size, strict: d.strict}
+}
+
+// Full returns a new bitset.Bounded in d with every bit set.
+func (d Domain) Full() Bounded {
+	return Bounded{s: Full(d.size), size: d.size, strict: d.strict}
+}
+
+// Of returns a new bitset.Bounded in d with the bits of s, or ErrOutOfRange if s has bits outside d.
+func (d Domain) Of(s Set) (Bounded, error) {
+	return d.Empty().Union(s)
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestBounded(t *testing.T) {
+	b := NewBounded(100)
+
+	b2, err := b.Set(99)
+	if err != nil {
+		t.Fatalf("Setting a bit inside the universe should not fail: %v", err)
+	}
+	if !b2.Test(99) || b.Test(99) {
+		t.Error("Set should return a new set with the bit set, without modifying the original")
+	}
+
+	b3, err := b2.Set(100)
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+	if b3.Test(100) || !b3.Test(99) {
+		t.Error("A failed Set should return the set unchanged")
+	}
+	if _, err := b2.Clear(1_000_000); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange from Clear, got %v", err)
+	}
+
+	b4, err := b2.Clear(99)
+	if err != nil || b4.Test(99) {
+		t.Errorf("Clear inside the universe failed: %v", err)
+	}
+}
+
+func TestBoundedWith(t *testing.T) {
+	b := NewBounded(100)
+
+	b2, err := b.With(5, 99, 5, 0)
+	if err != nil || b2.Bits().Count() != 3 || !b2.Test(99) || b.Test(5) {
+		t.Errorf("With inside the universe = %v, %v", slices.Collect(b2.Bits().Indices()), err)
+	}
+
+	b3, err := b2.With(1, 4_000_000_000, 2)
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+	if b3.Test(1) || b3.Test(2) || b3.Bits().Count() != 3 {
+		t.Error("A failed With should not set any of the bits")
+	}
+}
+
+func TestBoundedOperations(t *testing.T) {
+	b, _ := NewBounded(1000).Set(10)
+
+	if _, err := b.Union(New().Set(5).Set(999)); err != nil {
+		t.Errorf("Union within the universe should not fail: %v", err)
+	}
+	if _, err := b.Union(New().Set(5).Set(1000)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange from Union, got %v", err)
+	}
+	if _, err := b.SymmetricDifference(fromIndices([]uint32{5, 3_000_000})); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange from SymmetricDifference, got %v", err)
+	}
+
+	// Intersect and Difference can never set bits outside the universe
+	if i := b.Intersect(New().Set(10).Set(5000)); !i.Test(10) || i.Test(5000) {
+		t.Error("Intersect has incorrect bits")
+	}
+	if d := b.Difference(New().Set(10)); d.Test(10) || d.Size() != 1000 {
+		t.Error("Difference has incorrect bits or size")
+	}
+
+	if _, err := b.Union(NewWithShrinkPolicy(ShrinkNever).Set(2000).Clear(2000)); err != nil {
+		t.Errorf("Union with an empty set that has trailing zero words should not fail: %v", err)
+	}
+	if _, err := NewBounded(0).Union(New().Set(0)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange for an empty universe, got %v", err)
+	}
+}
+
+func TestStrictBounded(t *testing.T) {
+	b := NewStrictBounded(64)
+	if _, err := b.Set(63); err != nil {
+		t.Fatalf("Setting a bit inside the universe should not fail: %v", err)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Expected panic with ErrOutOfRange, got %v", err)
+		}
+	}()
+	b.Set(64)
+	t.Error("Setting a bit outside the universe of a strict set should panic")
+}
+
+func TestDomain(t *testing.T) {
+	d := NewDomain(100)
+	if d.Size() != 100 || d.Empty().Bits().Count() != 0 {
+		t.Fatalf("Empty set of a domain of size %d has %d bits", d.Size(), d.Empty().Bits().Count())
+	}
+
+	full := d.Full()
+	if !full.IsFull() || full.Bits().Count() != 100 || full.Test(100) {
+		t.Errorf("Full set of a domain has %d bits", full.Bits().Count())
+	}
+	if d.Empty().IsFull() || !NewDomain(0).Empty().IsFull() {
+		t.Error("Only the empty set of an empty domain should be full")
+	}
+
+	b, err := d.Of(FromIndices(1, 50, 99))
+	if err != nil {
+		t.Fatalf("Of inside the domain failed: %v", err)
+	}
+	c := b.Complement()
+	if c.Bits().Count() != 97 || c.Test(50) || !c.Test(0) || c.Test(100) {
+		t.Errorf("Complement = %v", slices.Collect(c.Bits().Indices()))
+	}
+	if c.Domain() != d || c.Complement().Bits().Key() != b.Bits().Key() {
+		t.Error("Complement should stay in the domain and be its own inverse")
+	}
+	if u, _ := b.Union(c.Bits()); !u.IsFull() {
+		t.Error("A set and its complement should cover the domain")
+	}
+
+	if _, err := d.Of(FromIndices(5, 100)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange from Of, got %v", err)
+	}
+	if _, err := b.Set(100); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange from Set, got %v", err)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Expected panic with ErrOutOfRange, got %v", err)
+		}
+	}()
+	NewStrictDomain(10).Full().Set(10)
+	t.Error("Sets of a strict domain should panic outside the universe")
+}
@@ -0,0 +1,557 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+	"slices"
+	"unsafe"
+)
+
+const (
+	chunkWords   = 1 << 16 / 64 // words in a 2^16 bit chunk
+	chunkBytes   = chunkWords * 8
+	maxArrayLen  = 4096 // an arrayContainer with more bits would be larger than a bitmapContainer
+	maxSparseLen = 4096 // a sparseBitSet with more bits is upgraded to a chunkedBitSet
+)
+
+// Chunked (many bits spread over a wide range, Roaring-style)
+type chunkedBitSet struct {
+	n      int     // number of set bits, always > maxSparseLen
+	r      int     // number of runs of set bits
+	chunks []chunk // sorted by key - immutable, always copied on modification
+}
+
+// chunk holds the bits of one 2^16 bit range.
+type chunk struct {
+	key uint16 // high 16 bits of the bit indices in c
+	c   container
+}
+
+// container holds the low 16 bits of the bit indices in a chunk.
+// There are three kinds, and a chunk always uses whichever is the smallest for its contents:
+//   - arrayContainer: sorted slice of bit indices, for few bits
+//   - bitmapContainer: fixed size bitmap, for many bits
+//   - runContainer: sorted slice of runs, for long runs of consecutive bits
+type container interface {
+	test(lo uint16) bool
+	// with returns a new container with lo set. lo must not already be set.
+	with(lo uint16) container
+	// without returns a new container with lo cleared. lo must be set.
+	without(lo uint16) container
+	card() int
+	runs() int
+	max() uint16
+	// fill ORs the bits of the container into words, which must be long enough to hold max().
+	fill(words []uint64)
+	appendTo(dst []uint32, base uint32) []uint32
+	appendRuns(dst []run[uint32], base uint32) []run[uint32]
+	// size returns the number of bytes of memory used by the container.
+	size() int
+	// appendKey appends an encoding of the kind and the contents of the container to dst.
+	appendKey(dst []byte) []byte
+	// each calls yield with base plus every bit in the container in ascending order, until yield returns false.
+	// It reports whether yield always returned true.
+	each(base uint32, yield func(uint32) bool) bool
+	// next returns the lowest bit in the container that is >= lo, or false if there is none.
+	next(lo uint16) (uint16, bool)
+}
+
+func (b chunkedBitSet) Test(bitIndex uint32) bool {
+	i, found := b.find(uint16(bitIndex >> 16))
+	return found && b.chunks[i].c.test(uint16(bitIndex))
+}
+
+func (b chunkedBitSet) Set(bitIndex uint32) Set {
+	key, lo := uint16(bitIndex>>16), uint16(bitIndex)
+	i, found := b.find(key)
+	if found && b.chunks[i].c.test(lo) {
+		return b
+	}
+
+	var newChunks []chunk
+	if found {
+		newChunks = slices.Clone(b.chunks)
+		newChunks[i].c = optimize(b.chunks[i].c.with(lo))
+	} else {
+		newChunks = slices.Insert(slices.Clip(b.chunks), i, chunk{key, arrayContainer{lo}})
+	}
+	return chunkedBitSet{b.n + 1, b.r + 1 - b.neighbors(bitIndex), newChunks}.normalize()
+}
+
+func (b chunkedBitSet) Clear(bitIndex uint32) Set {
+	key, lo := uint16(bitIndex>>16), uint16(bitIndex)
+	i, found := b.find(key)
+	if !found || !b.chunks[i].c.test(lo) {
+		return b
+	}
+
+	var newChunks []chunk
+	if c := optimize(b.chunks[i].c.without(lo)); c != nil {
+		newChunks = slices.Clone(b.chunks)
+		newChunks[i].c = c
+	} else {
+		newChunks = slices.Delete(slices.Clone(b.chunks), i, i+1)
+	}
+	return chunkedBitSet{b.n - 1, b.r - 1 + b.neighbors(bitIndex), newChunks}.normalize()
+}
+
+func (b chunkedBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b chunkedBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b chunkedBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b chunkedBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+// Count converts n through uint, as it wraps around on 32-bit platforms when there are more than 2^31-1 bits.
+func (b chunkedBitSet) Count() int {
+	return clampCount(uint64(uint(b.n)))
+}
+
+func (b chunkedBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for _, ch := range b.chunks {
+			if !ch.c.each(uint32(ch.key)<<16, yield) {
+				return
+			}
+		}
+	}
+}
+
+func (b chunkedBitSet) SizeInBytes() int {
+	size := int(unsafe.Sizeof(b)) + int(unsafe.Sizeof(chunk{}))*cap(b.chunks)
+	for _, ch := range b.chunks {
+		size += ch.c.size()
+	}
+	return size
+}
+
+func (b chunkedBitSet) Kind() Kind {
+	return KindChunked
+}
+
+func (b chunkedBitSet) Key() Key {
+	key := newKey(KindChunked, 0)
+	for _, ch := range b.chunks {
+		key = binary.LittleEndian.AppendUint16(key, ch.key)
+		key = ch.c.appendKey(key)
+	}
+	return Key(key)
+}
+
+// neighbors returns how many of the bits adjacent to the given bit index are set.
+func (b chunkedBitSet) neighbors(bitIndex uint32) int {
+	n := 0
+	if bitIndex > 0 && b.Test(bitIndex-1) {
+		n++
+	}
+	if bitIndex < 1<<32-1 && b.Test(bitIndex+1) {
+		n++
+	}
+	return n
+}
+
+func (b chunkedBitSet) find(key uint16) (int, bool) {
+	return slices.BinarySearchFunc(b.chunks, key, func(c chunk, key uint16) int {
+		return int(c.key) - int(key)
+	})
+}
+
+// normalize returns b, or the equivalent Set of another representation if that is more suitable.
+func (b chunkedBitSet) normalize() Set {
+	switch w := b.wordLen(); {
+	case preferRuns(b.n, b.r, w):
+		return runBitSet(b.toRuns())
+	case !preferSparse(b.n, w):
+		return fromWords(b.words())
+	case b.n <= maxSparseLen:
+		return sparseBitSet(b.indices())
+	}
+	return b
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b chunkedBitSet) wordLen() int {
+	if len(b.chunks) == 0 {
+		return 0
+	}
+	last := b.chunks[len(b.chunks)-1]
+	return int(last.key)*chunkWords + int(last.c.max()/64) + 1
+}
+
+// words returns the bits of b as a newly allocated word slice.
+func (b chunkedBitSet) words() []uint64 {
+	words := make([]uint64, b.wordLen())
+	for _, ch := range b.chunks {
+		start := int(ch.key) * chunkWords
+		ch.c.fill(words[start:min(start+chunkWords, len(words))])
+	}
+	return words
+}
+
+// indices returns the bit indices of all the set bits in b.
+func (b chunkedBitSet) indices() []uint32 {
+	s := make([]uint32, 0, b.n)
+	for _, ch := range b.chunks {
+		s = ch.c.appendTo(s, uint32(ch.key)<<16)
+	}
+	return s
+}
+
+// toRuns returns the runs of set bits in b.
+func (b chunkedBitSet) toRuns() []run[uint32] {
+	runs := make([]run[uint32], 0, b.r)
+	for _, ch := range b.chunks {
+		runs = ch.c.appendRuns(runs, uint32(ch.key)<<16)
+	}
+	return runs
+}
+
+// chunkedFromWords returns the bits in words as a chunkedBitSet.
+func chunkedFromWords(words []uint64) chunkedBitSet {
+	var b chunkedBitSet
+	for start := 0; start < len(words); start += chunkWords {
+		bm := new(bitmapContainer)
+		if copy(bm[:], words[start:]) == 0 || bm.card() == 0 {
+			continue
+		}
+		b.n += bm.card()
+		b.chunks = append(b.chunks, chunk{uint16(start / chunkWords), optimize(bm)})
+	}
+	b.r = runCount(words)
+	return b
+}
+
+// chunkedFromIndices returns the given sorted bit indices as a chunkedBitSet.
+func chunkedFromIndices(indices []uint32) chunkedBitSet {
+	b := chunkedBitSet{n: len(indices), r: indexRunCount(indices)}
+	for len(indices) > 0 {
+		key := uint16(indices[0] >> 16)
+		end, _ := slices.BinarySearch(indices, uint32(key)<<16+1<<16)
+		if uint32(key) == 1<<16-1 {
+			end = len(indices)
+		}
+
+		arr := make(arrayContainer, end)
+		for i, idx := range indices[:end] {
+			arr[i] = uint16(idx)
+		}
+		b.chunks = append(b.chunks, chunk{key, optimize(arr)})
+		indices = indices[end:]
+	}
+	return b
+}
+
+// optimize returns c converted to the smallest container kind for its contents, or nil if c is empty.
+func optimize(c container) container {
+	n := c.card()
+	if n == 0 {
+		return nil
+	}
+
+	// Sizes in bytes are 2*n for an arrayContainer, chunkBytes for a bitmapContainer and 4*runs for a runContainer
+	r := c.runs()
+	var bm *bitmapContainer
+	switch c := c.(type) {
+	case arrayContainer:
+		if 4*r >= 2*n && n <= maxArrayLen {
+			return c
+		}
+	case *bitmapContainer:
+		if 4*r >= chunkBytes && n > maxArrayLen {
+			return c
+		}
+		bm = c
+	case runContainer:
+		if 4*r < min(2*n, chunkBytes) {
+			return c
+		}
+	}
+
+	if bm == nil {
+		bm = new(bitmapContainer)
+		c.fill(bm[:])
+	}
+
+	switch {
+	case 4*r < min(2*n, chunkBytes):
+		return bm.toRuns(r)
+	case n <= maxArrayLen:
+		return bm.toArray(n)
+	}
+	return bm
+}
+
+// Array container
+type arrayContainer []uint16 // sorted, at most maxArrayLen entries
+
+func (c arrayContainer) test(lo uint16) bool {
+	_, found := slices.BinarySearch(c, lo)
+	return found
+}
+
+func (c arrayContainer) with(lo uint16) container {
+	i, _ := slices.BinarySearch(c, lo)
+	return arrayContainer(slices.Insert(slices.Clip(c), i, lo))
+}
+
+func (c arrayContainer) without(lo uint16) container {
+	i, _ := slices.BinarySearch(c, lo)
+	return arrayContainer(slices.Delete(slices.Clone(c), i, i+1))
+}
+
+func (c arrayContainer) card() int {
+	return len(c)
+}
+
+func (c arrayContainer) runs() int {
+	r := 0
+	for i, lo := range c {
+		if i == 0 || c[i-1] != lo-1 {
+			r++
+		}
+	}
+	return r
+}
+
+func (c arrayContainer) max() uint16 {
+	return c[len(c)-1]
+}
+
+func (c arrayContainer) fill(words []uint64) {
+	for _, lo := range c {
+		words[lo/64] |= 1 << (lo % 64)
+	}
+}
+
+func (c arrayContainer) appendTo(dst []uint32, base uint32) []uint32 {
+	for _, lo := range c {
+		dst = append(dst, base|uint32(lo))
+	}
+	return dst
+}
+
+func (c arrayContainer) appendRuns(dst []run[uint32], base uint32) []run[uint32] {
+	for _, lo := range c {
+		dst = appendRun(dst, base|uint32(lo), base|uint32(lo))
+	}
+	return dst
+}
+
+func (c arrayContainer) size() int {
+	return int(unsafe.Sizeof(c)) + 2*cap(c)
+}
+
+func (c arrayContainer) appendKey(dst []byte) []byte {
+	dst = binary.LittleEndian.AppendUint32(append(dst, 'a'), uint32(len(c)))
+	for _, lo := range c {
+		dst = binary.LittleEndian.AppendUint16(dst, lo)
+	}
+	return dst
+}
+
+func (c arrayContainer) each(base uint32, yield func(uint32) bool) bool {
+	for _, lo := range c {
+		if !yield(base + uint32(lo)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c arrayContainer) next(lo uint16) (uint16, bool) {
+	i, _ := slices.BinarySearch(c, lo)
+	if i == len(c) {
+		return 0, false
+	}
+	return c[i], true
+}
+
+// Bitmap container
+type bitmapContainer [chunkWords]uint64 // more than maxArrayLen bits
+
+func (c *bitmapContainer) test(lo uint16) bool {
+	return c[lo/64]&(1<<(lo%64)) != 0
+}
+
+func (c *bitmapContainer) with(lo uint16) container {
+	newC := *c
+	newC[lo/64] |= 1 << (lo % 64)
+	return &newC
+}
+
+func (c *bitmapContainer) without(lo uint16) container {
+	newC := *c
+	newC[lo/64] &^= 1 << (lo % 64)
+	return &newC
+}
+
+func (c *bitmapContainer) card() int {
+	return popCount(c[:])
+}
+
+func (c *bitmapContainer) runs() int {
+	return runCount(c[:])
+}
+
+func (c *bitmapContainer) max() uint16 {
+	i := len(c) - 1
+	for c[i] == 0 {
+		i--
+	}
+	return uint16(i*64 + 63 - bits.LeadingZeros64(c[i]))
+}
+
+func (c *bitmapContainer) fill(words []uint64) {
+	for i := range words {
+		words[i] |= c[i]
+	}
+}
+
+func (c *bitmapContainer) appendTo(dst []uint32, base uint32) []uint32 {
+	for i, w := range c {
+		dst = appendBits(dst, w, base+uint32(i*64))
+	}
+	return dst
+}
+
+func (c *bitmapContainer) toArray(n int) arrayContainer {
+	arr := make(arrayContainer, 0, n)
+	for i, w := range c {
+		for w != 0 {
+			arr = append(arr, uint16(i*64+bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+	return arr
+}
+
+func (c *bitmapContainer) appendRuns(dst []run[uint32], base uint32) []run[uint32] {
+	return appendWordRuns(dst, c[:], base)
+}
+
+func (c *bitmapContainer) size() int {
+	return int(unsafe.Sizeof(*c))
+}
+
+func (c *bitmapContainer) appendKey(dst []byte) []byte {
+	return appendWords(append(dst, 'b'), c[:])
+}
+
+func (c *bitmapContainer) each(base uint32, yield func(uint32) bool) bool {
+	return yieldWords(c[:], base, yield)
+}
+
+func (c *bitmapContainer) next(lo uint16) (uint16, bool) {
+	i, ok := nextInWords(c[:], uint32(lo))
+	return uint16(i), ok
+}
+
+func (c *bitmapContainer) toRuns(r int) runContainer {
+	runs := make(runContainer, 0, r)
+	for _, r := range appendWordRuns(make([]run[uint32], 0, r), c[:], 0) {
+		runs = append(runs, run[uint16]{uint16(r.start), uint16(r.last)})
+	}
+	return runs
+}
+
+// Run container
+type runContainer []run[uint16] // sorted, non-overlapping and non-adjacent
+
+func (c runContainer) test(lo uint16) bool {
+	_, found := searchRuns(c, lo)
+	return found
+}
+
+func (c runContainer) with(lo uint16) container {
+	return runContainer(withRun(c, lo))
+}
+
+func (c runContainer) without(lo uint16) container {
+	return runContainer(withoutRun(c, lo))
+}
+
+func (c runContainer) card() int {
+	n := 0
+	for _, r := range c {
+		n += int(r.last-r.start) + 1
+	}
+	return n
+}
+
+func (c runContainer) runs() int {
+	return len(c)
+}
+
+func (c runContainer) max() uint16 {
+	return c[len(c)-1].last
+}
+
+func (c runContainer) fill(words []uint64) {
+	for _, r := range c {
+		fillRange(words, uint32(r.start), uint32(r.last))
+	}
+}
+
+func (c runContainer) appendTo(dst []uint32, base uint32) []uint32 {
+	for _, r := range c {
+		for i := uint32(r.start); i <= uint32(r.last); i++ {
+			dst = append(dst, base|i)
+		}
+	}
+	return dst
+}
+
+func (c runContainer) appendRuns(dst []run[uint32], base uint32) []run[uint32] {
+	for _, r := range c {
+		dst = appendRun(dst, base|uint32(r.start), base|uint32(r.last))
+	}
+	return dst
+}
+
+func (c runContainer) size() int {
+	return int(unsafe.Sizeof(c)) + int(unsafe.Sizeof(run[uint16]{}))*cap(c)
+}
+
+func (c runContainer) appendKey(dst []byte) []byte {
+	dst = binary.LittleEndian.AppendUint32(append(dst, 'r'), uint32(len(c)))
+	for _, r := range c {
+		dst = binary.LittleEndian.AppendUint16(dst, r.start)
+		dst = binary.LittleEndian.AppendUint16(dst, r.last)
+	}
+	return dst
+}
+
+func (c runContainer) each(base uint32, yield func(uint32) bool) bool {
+	for _, r := range c {
+		for lo := uint32(r.start); lo <= uint32(r.last); lo++ {
+			if !yield(base + lo) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c runContainer) next(lo uint16) (uint16, bool) {
+	i, found := searchRuns(c, lo)
+	switch {
+	case found:
+		return lo, true
+	case i < len(c):
+		return c[i].start, true
+	}
+	return 0, false
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestChunkedBitSet(t *testing.T) {
+	// 5000 bits, one every 1000 indices, is too many for a sparseBitSet but too sparse for a largeBitSet
+	b := NewBuilder(0)
+	for i := uint32(0); i < 5000; i++ {
+		b = b.With(i * 1000)
+	}
+	bs := b.Build()
+
+	cbs, ok := bs.(chunkedBitSet)
+	if !ok {
+		t.Fatalf("Expected chunkedBitSet, but got %T", bs)
+	}
+	if cbs.n != 5000 {
+		t.Errorf("chunkedBitSet should have 5000 bits, got %d", cbs.n)
+	}
+	for i := uint32(0); i < 5000; i++ {
+		if !bs.Test(i*1000) || bs.Test(i*1000+1) {
+			t.Fatalf("chunkedBitSet has incorrect bits around %d", i*1000)
+		}
+	}
+
+	// Test immutability on Set
+	bs2 := bs.Set(1)
+	if bs.Test(1) {
+		t.Error("Original chunkedBitSet should not be modified after Set")
+	}
+	if !bs2.Test(1) || !bs2.Test(1000) {
+		t.Error("New chunkedBitSet should have old and new bits")
+	}
+
+	// Test immutability on Clear
+	bs3 := bs2.Clear(1000)
+	if !bs2.Test(1000) {
+		t.Error("Original chunkedBitSet should not be modified after Clear")
+	}
+	if bs3.Test(1000) || !bs3.Test(1) {
+		t.Error("New chunkedBitSet should have correct bits after removal")
+	}
+
+	// Clearing down to maxSparseLen bits downgrades to sparseBitSet
+	var s Set = bs
+	for i := uint32(0); i < 5000-maxSparseLen; i++ {
+		s = s.Clear(i * 1000)
+	}
+	if _, ok := s.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet after clearing, but got %T", s)
+	}
+	if s.Test(0) || !s.Test(4_999_000) {
+		t.Error("Downgraded set has incorrect bits")
+	}
+}
+
+func TestChunkedBitSetContainers(t *testing.T) {
+	// One far away sparse chunk keeps the set chunked, while the first chunk changes container kinds
+	b := NewBuilder(0)
+	for i := uint32(0); i < 5000; i++ {
+		b = b.With(i)
+	}
+	for i := uint32(0); i < 4000; i++ {
+		b = b.With(100_000_000 + i*7)
+	}
+	bs := b.Build()
+
+	cbs, ok := bs.(chunkedBitSet)
+	if !ok {
+		t.Fatalf("Expected chunkedBitSet, but got %T", bs)
+	}
+	if _, ok := cbs.chunks[0].c.(runContainer); !ok {
+		t.Fatalf("Expected a runContainer for a single run of bits, but got %T", cbs.chunks[0].c)
+	}
+	if _, ok := cbs.chunks[1].c.(arrayContainer); !ok {
+		t.Fatalf("Expected an arrayContainer for scattered bits, but got %T", cbs.chunks[1].c)
+	}
+
+	// Clearing every other bit makes the run container too fragmented
+	s := bs
+	for i := uint32(0); i < 5000; i += 2 {
+		s = s.Clear(i)
+	}
+	if _, ok := s.(chunkedBitSet).chunks[0].c.(arrayContainer); !ok {
+		t.Fatalf("Expected an arrayContainer for 2500 scattered bits, but got %T", s.(chunkedBitSet).chunks[0].c)
+	}
+
+	// Setting every third bit in the whole chunk needs a bitmap
+	for i := uint32(0); i < 1<<16; i += 3 {
+		s = s.Set(i)
+	}
+	if _, ok := s.(chunkedBitSet).chunks[0].c.(*bitmapContainer); !ok {
+		t.Fatalf("Expected a bitmapContainer for a dense chunk, but got %T", s.(chunkedBitSet).chunks[0].c)
+	}
+
+	for i := uint32(0); i < 1<<16; i++ {
+		want := i%3 == 0 || (i < 5000 && i%2 == 1)
+		if s.Test(i) != want {
+			t.Fatalf("Bit %d: want %v, got %v", i, want, s.Test(i))
+		}
+	}
+}
+
+func TestChunkedBitSetRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	want := map[uint32]bool{}
+	var bs Set = New()
+	for range 20_000 {
+		// Mostly clustered bits in a few chunks, with some scattered far away
+		i := uint32(r.IntN(3))<<20 | uint32(r.IntN(1<<15))
+		if r.IntN(10) == 0 {
+			i = r.Uint32()
+		}
+
+		if r.IntN(3) == 0 {
+			bs = bs.Clear(i)
+			delete(want, i)
+		} else {
+			bs = bs.Set(i)
+			want[i] = true
+		}
+	}
+
+	if _, ok := bs.(chunkedBitSet); !ok {
+		t.Fatalf("Expected chunkedBitSet, but got %T", bs)
+	}
+	if n := bs.(chunkedBitSet).n; n != len(want) {
+		t.Errorf("chunkedBitSet should have %d bits, got %d", len(want), n)
+	}
+	for i := range want {
+		if !bs.Test(i) {
+			t.Fatalf("Bit %d should be set", i)
+		}
+	}
+	for range 10_000 {
+		i := r.Uint32()
+		if bs.Test(i) != want[i] {
+			t.Fatalf("Bit %d: want %v, got %v", i, want[i], bs.Test(i))
+		}
+	}
+}
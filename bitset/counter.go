@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// AddUint64 returns a new bitset.Set with x added to s, treating s as an unsigned binary number where bit i is
+// worth 2^i, e.g. to use a set as a counter wider than 64 bits without converting it to a big.Int.
+// A carry out of the highest bit index is dropped, so the counter wraps around to zero.
+// The original bitset.Set is not modified.
+func AddUint64(s Set, x uint64) Set {
+	if x == 0 {
+		return s
+	}
+
+	sum, carry := bits.Add64(uint64(slice(s, 0, 64).(bitSet64)), x, 0)
+	// The bits below end are replaced by the sum
+	end := uint64(64)
+	if carry != 0 {
+		// The carry clears the run of ones from bit 64 up, and sets the bit after it
+		for _, r := range runsOf(s) {
+			if uint64(r.start) > end {
+				break
+			}
+			if uint64(r.last) >= end {
+				end = uint64(r.last) + 1
+				break
+			}
+		}
+	}
+
+	out := s.Difference(fromRuns([]run[uint32]{{0, uint32(end - 1)}})).Union(bitSet64(sum))
+	if carry != 0 && end < 1<<32 {
+		out = out.Set(uint32(end))
+	}
+	return out
+}
+
+// Increment returns a new bitset.Set with 1 added to s, treating s as a binary counter, as described by AddUint64.
+// The original bitset.Set is not modified.
+func Increment(s Set) Set {
+	return AddUint64(s, 1)
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// bigOf returns the bits of s as a big.Int, where bit i is worth 2^i.
+func bigOf(s Set) *big.Int {
+	words := AppendWords(nil, s)
+	buf := make([]byte, 8*len(words))
+	for i, w := range words {
+		binary.BigEndian.PutUint64(buf[8*(len(words)-1-i):], w)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+func TestAddUint64(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	sets := testSets()
+	sets = append(sets,
+		testSet{name: "carry chain", s: Full(64 * 300)},
+		testSet{name: "carry into gap", s: Full(128).Union(FromIndices(1000, 5000))},
+	)
+	for _, ts := range sets {
+		for _, x := range []uint64{0, 1, 3, math.MaxUint64, r.Uint64()} {
+			got := AddUint64(ts.s, x)
+			want := new(big.Int).Add(bigOf(ts.s), new(big.Int).SetUint64(x))
+			if bigOf(got).Cmp(want) != 0 {
+				t.Errorf("%s + %d: wrong sum", ts.name, x)
+			}
+			checkCanonical(t, got)
+		}
+	}
+
+	if got := Increment(Increment(New())); got.Key() != FromIndices(1).Key() {
+		t.Errorf("Expected 2, got bits %v", FormatRangeList(got))
+	}
+	if got := Increment(Full(64*3 - 1)); got.Key() != FromIndices(64*3-1).Key() {
+		t.Errorf("Expected the carry to ripple to the top bit, got bits %v", FormatRangeList(got))
+	}
+}
+
+func TestAddUint64Overflow(t *testing.T) {
+	if got := Increment(Full(math.MaxUint32).Set(math.MaxUint32)); !isEmpty(got) {
+		t.Errorf("Expected the counter to wrap around to zero, got %d bits", got.Count())
+	}
+}
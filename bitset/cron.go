@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	monthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// ParseMinutes returns a bitset.Set with the minutes of the hour in a cron field, e.g. "*/15" or "0,30".
+// A field is a comma separated list of values, inclusive ranges like "10-20", and "*" for every value,
+// where ranges and "*" may be followed by a step like "/5".
+func ParseMinutes(field string) (Set, error) {
+	return parseCronField(field, 0, 59, nil)
+}
+
+// ParseHours returns a bitset.Set with the hours of the day in a cron field like "9-17", in the syntax of ParseMinutes.
+func ParseHours(field string) (Set, error) {
+	return parseCronField(field, 0, 23, nil)
+}
+
+// ParseDaysOfMonth returns a bitset.Set with the days of the month, from 1, in a cron field like "1,15",
+// in the syntax of ParseMinutes.
+func ParseDaysOfMonth(field string) (Set, error) {
+	return parseCronField(field, 1, 31, nil)
+}
+
+// ParseMonths returns a bitset.Set with the months, from 1 for January, in a cron field like "JAN-JUN" or "*/3",
+// in the syntax of ParseMinutes. Months can also be given by their first three letters, in any case.
+func ParseMonths(field string) (Set, error) {
+	return parseCronField(field, 1, 12, monthNames)
+}
+
+// ParseWeekdays returns a bitset.Set with the days of the week, from 0 for Sunday like time.Weekday, in a cron field
+// like "MON-FRI", in the syntax of ParseMinutes. Days can also be given by their first three letters, in any case,
+// and 7 is Sunday as well.
+func ParseWeekdays(field string) (Set, error) {
+	s, err := parseCronField(field, 0, 7, weekdayNames)
+	if err != nil || !s.Test(7) {
+		return s, err
+	}
+	return s.Clear(7).Set(0), nil
+}
+
+// parseCronField returns a bitset.Set with the values in [lo, hi] in a cron field, where names[i] is the value lo+i.
+func parseCronField(field string, lo, hi uint32, names []string) (Set, error) {
+	if field == "" {
+		return nil, fmt.Errorf("bitset: invalid cron field %q", field)
+	}
+
+	parseValue := func(v string) (uint32, error) {
+		if i := slices.Index(names, strings.ToUpper(v)); i >= 0 {
+			return lo + uint32(i), nil
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || uint32(n) < lo || uint32(n) > hi {
+			return 0, fmt.Errorf("bitset: invalid cron field %q: %s is not in [%d, %d]", field, v, lo, hi)
+		}
+		return uint32(n), nil
+	}
+
+	s := New()
+	for item := range strings.SplitSeq(field, ",") {
+		item, stepStr, hasStep := strings.Cut(item, "/")
+		step := uint64(1)
+		if hasStep {
+			var err error
+			if step, err = strconv.ParseUint(stepStr, 10, 32); err != nil || step == 0 {
+				return nil, fmt.Errorf("bitset: invalid cron field %q: invalid step %q", field, stepStr)
+			}
+		}
+
+		first, last := lo, hi
+		if item != "*" {
+			firstStr, lastStr, isRange := strings.Cut(item, "-")
+			var err error
+			if first, err = parseValue(firstStr); err != nil {
+				return nil, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseValue(lastStr); err != nil {
+					return nil, err
+				}
+				if last < first {
+					return nil, fmt.Errorf("bitset: invalid cron field %q: range %s is reversed", field, item)
+				}
+			} else if hasStep {
+				// A single value with a step, like "5/15", runs to the end of the range
+				last = hi
+			}
+		}
+		s = s.Union(FromStride(first, uint32(step), uint32((uint64(last)-uint64(first))/step)+1))
+	}
+	return s, nil
+}
+
+// bitset.Schedule is the set of times matched by a cron schedule, as one bitset.Set per time field.
+// A nil field matches nothing, so the zero value never matches.
+type Schedule struct {
+	Minutes     Set // minutes of the hour, 0-59
+	Hours       Set // hours of the day, 0-23
+	DaysOfMonth Set // days of the month, 1-31
+	Months      Set // months, 1-12
+	Weekdays    Set // days of the week, 0-6 from Sunday
+}
+
+// ParseSchedule returns a bitset.Schedule for a cron schedule of five fields, e.g. "*/15 9-17 * * MON-FRI",
+// for the minutes, hours, days of the month, months and days of the week, in the syntax of ParseMinutes.
+func ParseSchedule(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("bitset: cron schedule %q does not have 5 fields", spec)
+	}
+
+	var sched Schedule
+	fieldSets := []struct {
+		dst   *Set
+		parse func(string) (Set, error)
+	}{
+		{&sched.Minutes, ParseMinutes},
+		{&sched.Hours, ParseHours},
+		{&sched.DaysOfMonth, ParseDaysOfMonth},
+		{&sched.Months, ParseMonths},
+		{&sched.Weekdays, ParseWeekdays},
+	}
+	for i, f := range fieldSets {
+		var err error
+		if *f.dst, err = f.parse(fields[i]); err != nil {
+			return Schedule{}, err
+		}
+	}
+	return sched, nil
+}
+
+// Matches reports whether t, to the minute, is one of the times of s.
+// Like cron, if both the days of the month and the days of the week are restricted, i.e. neither has every day,
+// t matches if either of them does.
+func (s Schedule) Matches(t time.Time) bool {
+	if !Has(s.Minutes, uint32(t.Minute())) || !Has(s.Hours, uint32(t.Hour())) || !Has(s.Months, uint32(t.Month())) {
+		return false
+	}
+
+	day, weekday := Has(s.DaysOfMonth, uint32(t.Day())), Has(s.Weekdays, uint32(t.Weekday()))
+	if Count(s.DaysOfMonth) < 31 && Count(s.Weekdays) < 7 {
+		return day || weekday
+	}
+	return day && weekday
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseCronFields(t *testing.T) {
+	tests := []struct {
+		parse func(string) (Set, error)
+		field string
+		want  []uint32
+	}{
+		{ParseMinutes, "*/15", []uint32{0, 15, 30, 45}},
+		{ParseMinutes, "0,30", []uint32{0, 30}},
+		{ParseMinutes, "10-13,50/4", []uint32{10, 11, 12, 13, 50, 54, 58}},
+		{ParseMinutes, "0-20/10", []uint32{0, 10, 20}},
+		{ParseHours, "9-17/3", []uint32{9, 12, 15}},
+		{ParseHours, "23", []uint32{23}},
+		{ParseDaysOfMonth, "1,15,31", []uint32{1, 15, 31}},
+		{ParseDaysOfMonth, "*/10", []uint32{1, 11, 21, 31}},
+		{ParseMonths, "jan-Mar,DEC", []uint32{1, 2, 3, 12}},
+		{ParseMonths, "*/6", []uint32{1, 7}},
+		{ParseWeekdays, "MON-FRI", []uint32{1, 2, 3, 4, 5}},
+		{ParseWeekdays, "sat,7", []uint32{0, 6}},
+		{ParseWeekdays, "*", []uint32{0, 1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		s, err := tt.parse(tt.field)
+		if err != nil {
+			t.Errorf("Parsing %q returned error: %v", tt.field, err)
+			continue
+		}
+		if got := slices.Collect(s.Indices()); !slices.Equal(got, tt.want) {
+			t.Errorf("Parsing %q = %v, expected %v", tt.field, got, tt.want)
+		}
+	}
+
+	for _, field := range []string{"", "60", "*/0", "5-1", "1,,2", "MON", "a-b", "*/x", "-1"} {
+		if _, err := ParseMinutes(field); err == nil {
+			t.Errorf("Expected an error for %q", field)
+		}
+	}
+	if _, err := ParseDaysOfMonth("0"); err == nil {
+		t.Error("Expected an error for day 0")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	s, err := ParseSchedule("*/15 9-17 * * MON-FRI")
+	if err != nil {
+		t.Fatalf("ParseSchedule returned error: %v", err)
+	}
+
+	// 2025-06-02 is a Monday
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2025, 6, 2, 9, 15, 59, 0, time.UTC), true},
+		{time.Date(2025, 6, 2, 9, 16, 0, 0, time.UTC), false},
+		{time.Date(2025, 6, 2, 18, 0, 0, 0, time.UTC), false},
+		{time.Date(2025, 6, 7, 9, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := s.Matches(tt.t); got != tt.want {
+			t.Errorf("Matches(%v) = %v, expected %v", tt.t, got, tt.want)
+		}
+	}
+
+	// With both days restricted, either matches
+	s, err = ParseSchedule("0 0 1 * SUN")
+	if err != nil {
+		t.Fatalf("ParseSchedule returned error: %v", err)
+	}
+	for _, day := range []int{1, 8, 15} {
+		if !s.Matches(time.Date(2025, 6, day, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("June %d should match the first of the month or Sundays", day)
+		}
+	}
+	if s.Matches(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("June 2 is neither the first nor a Sunday")
+	}
+
+	if (Schedule{}).Matches(time.Now()) {
+		t.Error("The zero Schedule should never match")
+	}
+	for _, spec := range []string{"* * * *", "* * * * * *", "* 24 * * *"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+// bitset.ChangeSet is the difference between two sets: the bits that were added, and the bits that were removed.
+// Added and Removed never share bits when created by Diff. A nil field is treated as an empty set.
+type ChangeSet struct {
+	Added, Removed Set
+}
+
+// Diff returns the minimal ChangeSet that turns old into new.
+// Neither set is modified.
+func Diff(old, new Set) ChangeSet {
+	return ChangeSet{Added: new.Difference(old), Removed: old.Difference(new)}
+}
+
+// Apply returns a new bitset.Set with the changes in c applied to s, i.e. with the bits in Removed cleared
+// and the bits in Added set. Applying Diff(old, new) to old returns new.
+// The original bitset.Set is not modified.
+func (c ChangeSet) Apply(s Set) Set {
+	if c.Removed != nil {
+		s = s.Difference(c.Removed)
+	}
+	if c.Added != nil {
+		s = s.Union(c.Added)
+	}
+	return s
+}
+
+// Invert returns the ChangeSet that undoes c, i.e. with Added and Removed swapped.
+func (c ChangeSet) Invert() ChangeSet {
+	return ChangeSet{Added: c.Removed, Removed: c.Added}
+}
+
+// IsEmpty reports whether c has no changes.
+func (c ChangeSet) IsEmpty() bool {
+	return (c.Added == nil || isEmpty(c.Added)) && (c.Removed == nil || isEmpty(c.Removed))
+}
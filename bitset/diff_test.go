@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := FromIndices(1, 2, 3, 500)
+	new := FromIndices(2, 3, 4, 1000)
+
+	c := Diff(old, new)
+	if got := slices.Collect(c.Added.Indices()); !slices.Equal(got, []uint32{4, 1000}) {
+		t.Errorf("Expected added [4 1000], got %v", got)
+	}
+	if got := slices.Collect(c.Removed.Indices()); !slices.Equal(got, []uint32{1, 500}) {
+		t.Errorf("Expected removed [1 500], got %v", got)
+	}
+
+	if got := c.Apply(old); got.Key() != new.Key() {
+		t.Error("Applying the diff should give the new set")
+	}
+	if got := c.Invert().Apply(new); got.Key() != old.Key() {
+		t.Error("Applying the inverted diff should give the old set")
+	}
+	if c.IsEmpty() || !Diff(old, old).IsEmpty() {
+		t.Error("IsEmpty returned incorrect result")
+	}
+}
+
+func TestDiffAllSets(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			c := Diff(a.s, b.s)
+			got := c.Apply(a.s)
+			checkCanonical(t, got)
+			if got.Key() != b.s.Key() {
+				t.Errorf("Diff(%s, %s) does not apply", a.name, b.name)
+			}
+			if got := c.Invert().Apply(b.s); got.Key() != a.s.Key() {
+				t.Errorf("Diff(%s, %s) does not invert", a.name, b.name)
+			}
+		}
+	}
+}
+
+func TestChangeSetZeroValue(t *testing.T) {
+	var c ChangeSet
+	s := FromIndices(1, 2)
+	if !c.IsEmpty() || c.Apply(s).Key() != s.Key() || c.Invert().Apply(s).Key() != s.Key() {
+		t.Error("The zero ChangeSet should have no changes")
+	}
+}
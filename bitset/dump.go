@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Dump writes a description of s to w for debugging: its representation, number of bits, runs, words and size,
+// followed by every nonzero word with the range of bit indices it holds, in hex, and in binary with the highest bit
+// on the left, e.g.
+//
+//	bitset: Medium, 3 bits in 3 runs over 3 words, 24 bytes
+//	word 0  bits 0-63     0x0000000000000022  00000000 00000000 00000000 00000000 00000000 00000000 00000000 00100010
+//	word 2  bits 128-191  0x0000000000000001  00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000001
+//
+// Zero words are skipped, so sparse sets spanning millions of words stay short. It returns the first write error.
+func Dump(w io.Writer, s Set) error {
+	info := Inspect(s)
+	if _, err := fmt.Fprintf(w, "bitset: %v, %d bits in %d runs over %d words, %d bytes\n",
+		info.Kind, s.Count(), len(runsOf(s)), info.Words, info.SizeInBytes); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var bin strings.Builder
+	for _, iw := range nonzeroWords(s) {
+		bin.Reset()
+		for b := 7; b >= 0; b-- {
+			fmt.Fprintf(&bin, "%08b", uint8(iw.w>>(b*8)))
+			if b > 0 {
+				bin.WriteByte(' ')
+			}
+		}
+		first := uint64(iw.i) * 64
+		fmt.Fprintf(tw, "word %d\tbits %d-%d\t0x%016x\t%s\n", iw.i, first, first+63, iw.w, bin.String())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	var sb strings.Builder
+	if err := Dump(&sb, FromIndices(1, 5, 128)); err != nil {
+		t.Fatal(err)
+	}
+	want := `bitset: Medium, 3 bits in 3 runs over 3 words, 24 bytes
+word 0  bits 0-63     0x0000000000000022  00000000 00000000 00000000 00000000 00000000 00000000 00000000 00100010
+word 2  bits 128-191  0x0000000000000001  00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000001
+`
+	if sb.String() != want {
+		t.Errorf("Dump =\n%s\nexpected\n%s", sb.String(), want)
+	}
+
+	for _, ts := range testSets() {
+		sb.Reset()
+		if err := Dump(&sb, ts.s); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(sb.String(), "\n"); lines != len(nonzeroWords(ts.s))+1 {
+			t.Errorf("%s: Dump wrote %d lines for %d nonzero words", ts.name, lines, len(nonzeroWords(ts.s)))
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestDumpWriteError(t *testing.T) {
+	if err := Dump(failingWriter{}, FromIndices(1, 100)); err == nil {
+		t.Error("Dump should return the write error")
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var errCorrupt = errors.New("bitset: invalid encoded set")
+
+// The header of an encoded set is formatMagic, then the format version, then the Encoding of the rest of the data.
+const (
+	formatMagic   = "bset"
+	formatVersion = 1
+)
+
+// bitset.Encoding is a binary format for a Set, written by Encoder and read by Decode.
+// The encoding is stored in the header of the data, so Decode reads every encoding.
+type Encoding uint8
+
+const (
+	EncodingAuto   Encoding = iota // whichever of the other encodings is expected to be smaller
+	EncodingWords                  // little-endian uint64 words, with a size proportional to the highest bit
+	EncodingDeltas                 // the gaps between the sorted bit indices as varints, with a size proportional to the number of bits
+	EncodingRuns                   // the gaps between and lengths of the runs of consecutive bits as varints, with a size proportional to the number of runs
+)
+
+// bitset.Encoder encodes sets in a versioned binary format that Decode reads back, e.g. to send them over the wire
+// or store them. Data written by older versions of this package stays readable.
+// The zero value is ready to use, and picks the smaller encoding for each set.
+type Encoder struct {
+	// Encoding is the format to encode sets in. EncodingAuto uses EncodingRuns for sets stored as runs,
+	// and EncodingDeltas for other sets that are sparse enough for it to be smaller than EncodingWords.
+	Encoding Encoding
+}
+
+// Encode returns the bits of s in e's encoding.
+// It panics if e.Encoding is not a valid Encoding.
+func (e Encoder) Encode(s Set) []byte {
+	enc := e.Encoding
+	if enc == EncodingAuto {
+		enc = EncodingWords
+		if s.Kind() == KindRuns {
+			enc = EncodingRuns
+		} else if n := s.Count(); n > 0 {
+			// Every gap takes about as many bytes as the varint of the average gap between the bits
+			words := Inspect(s).Words
+			if n*uvarintLen(uint64(words)*64/uint64(n)) < words*8 {
+				enc = EncodingDeltas
+			}
+		}
+	}
+
+	data := append([]byte(formatMagic), formatVersion, byte(enc))
+	switch enc {
+	case EncodingWords:
+		return appendWords(data, AppendWords(nil, s))
+	case EncodingDeltas:
+		data = binary.AppendUvarint(data, uint64(s.Count()))
+		next := uint64(0) // the lowest index the next bit can have
+		for i := range s.Indices() {
+			data = binary.AppendUvarint(data, uint64(i)-next)
+			next = uint64(i) + 1
+		}
+		return data
+	case EncodingRuns:
+		runs := runsOf(s)
+		data = binary.AppendUvarint(data, uint64(len(runs)))
+		next := uint64(0) // the lowest index the next run can start at, since runs are never adjacent
+		for _, r := range runs {
+			data = binary.AppendUvarint(data, uint64(r.start)-next)
+			data = binary.AppendUvarint(data, uint64(r.last-r.start))
+			next = uint64(r.last) + 2
+		}
+		return data
+	}
+	panic(fmt.Sprintf("bitset: invalid encoding %d", e.Encoding))
+}
+
+// Encode returns the bits of s in the smallest encoding, as described by EncodingAuto.
+// It is short for Encoder{}.Encode(s).
+func Encode(s Set) []byte {
+	return Encoder{}.Encode(s)
+}
+
+// Decode returns a bitset.Set with the bits of data, in any of the encodings written by Encoder.
+// It returns an error if data is not a valid encoded set, or was written by a newer version of this package
+// in a format version it doesn't know.
+func Decode(data []byte) (Set, error) {
+	if !strings.HasPrefix(string(data), formatMagic) {
+		// Data written before the header was added starts directly with the encoding
+		if len(data) > 0 && (Encoding(data[0]) == EncodingWords || Encoding(data[0]) == EncodingDeltas) {
+			return decodePayload(Encoding(data[0]), data[1:])
+		}
+		return nil, errCorrupt
+	}
+
+	data = data[len(formatMagic):]
+	if len(data) < 2 {
+		return nil, errCorrupt
+	}
+	if version := data[0]; version == 0 || version > formatVersion {
+		return nil, fmt.Errorf("bitset: unsupported format version %d", version)
+	}
+	return decodePayload(Encoding(data[1]), data[2:])
+}
+
+// decodePayload decodes the data following the header of a set in the given encoding.
+func decodePayload(enc Encoding, data []byte) (Set, error) {
+	switch enc {
+	case EncodingWords:
+		if len(data)%8 != 0 || len(data)/8 > numWords {
+			return nil, errCorrupt
+		}
+		words := make([]uint64, len(data)/8)
+		for i := range words {
+			words[i] = binary.LittleEndian.Uint64(data[i*8:])
+		}
+		return fromWords(words), nil
+	case EncodingDeltas:
+		gaps, err := readUvarints(data, 1)
+		if err != nil {
+			return nil, err
+		}
+		indices := make([]uint32, len(gaps))
+		next := uint64(0)
+		for k, gap := range gaps {
+			if gap > math.MaxUint32 || next+gap > math.MaxUint32 {
+				return nil, errCorrupt
+			}
+			indices[k] = uint32(next + gap)
+			next += gap + 1
+		}
+		return fromIndices(indices), nil
+	case EncodingRuns:
+		values, err := readUvarints(data, 2)
+		if err != nil {
+			return nil, err
+		}
+		runs := make([]run[uint32], len(values)/2)
+		next := uint64(0)
+		for k := range runs {
+			gap, length := values[2*k], values[2*k+1]
+			if gap > math.MaxUint32 || length > math.MaxUint32 || next+gap+length > math.MaxUint32 {
+				return nil, errCorrupt
+			}
+			runs[k] = run[uint32]{uint32(next + gap), uint32(next + gap + length)}
+			next += gap + length + 2
+		}
+		return fromRuns(runs), nil
+	}
+	return nil, fmt.Errorf("bitset: unknown encoding %d", enc)
+}
+
+// readUvarints reads a varint count followed by count groups of the given number of varints, which must make up
+// all of data.
+func readUvarints(data []byte, group int) ([]uint64, error) {
+	n, size := binary.Uvarint(data)
+	// Every varint takes at least a byte
+	if size <= 0 || n > uint64(len(data)-size)/uint64(group) {
+		return nil, errCorrupt
+	}
+	data = data[size:]
+
+	values := make([]uint64, int(n)*group)
+	for k := range values {
+		v, size := binary.Uvarint(data)
+		if size <= 0 {
+			return nil, errCorrupt
+		}
+		values[k] = v
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return nil, errCorrupt
+	}
+	return values, nil
+}
+
+// uvarintLen returns the number of bytes binary.AppendUvarint takes for x.
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	sets := testSets()
+	sets = append(sets, testSet{name: "highest", s: FromIndices(0, math.MaxUint32)})
+	for _, ts := range sets {
+		for _, enc := range []Encoding{EncodingAuto, EncodingWords, EncodingDeltas, EncodingRuns} {
+			if enc == EncodingWords && ts.name == "highest" {
+				continue // 512 MiB
+			}
+			got, err := Decode(Encoder{Encoding: enc}.Encode(ts.s))
+			if err != nil {
+				t.Fatalf("%s, encoding %d: %v", ts.name, enc, err)
+			}
+			if got.Key() != ts.s.Key() {
+				t.Errorf("%s, encoding %d: set does not round trip, got %d bits", ts.name, enc, got.Count())
+			}
+		}
+	}
+}
+
+func TestEncodeDeltas(t *testing.T) {
+	got := Encoder{Encoding: EncodingDeltas}.Encode(FromIndices(3, 4, 200))
+	want := []byte{'b', 's', 'e', 't', 1, byte(EncodingDeltas), 3, 3, 0, 0xc3, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+
+	got = Encoder{Encoding: EncodingRuns}.Encode(FromIndices(3, 4, 5, 200))
+	want = []byte{'b', 's', 'e', 't', 1, byte(EncodingRuns), 2, 3, 2, 0xc1, 0x01, 0}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+
+	// A very sparse set is far smaller than its words
+	s := FromIndices(1, 1<<20, 1<<25)
+	if got := Encode(s); got[5] != byte(EncodingDeltas) || len(got) > 20 {
+		t.Errorf("Expected a short delta encoding, got %d bytes in encoding %d", len(got), got[5])
+	}
+	if got := Encode(FromStride(0, 2, 1000)); got[5] != byte(EncodingWords) {
+		t.Errorf("Expected words for a dense set, got encoding %d", got[5])
+	}
+	if got := Encode(Full(math.MaxUint32)); got[5] != byte(EncodingRuns) || len(got) > 16 {
+		t.Errorf("Expected a short run encoding, got %d bytes in encoding %d", len(got), got[5])
+	}
+}
+
+func TestDecodeUnversioned(t *testing.T) {
+	// Sets encoded before the header was added start directly with the encoding
+	for _, data := range [][]byte{
+		{byte(EncodingDeltas), 3, 3, 0, 0xc3, 0x01},
+		appendWords([]byte{byte(EncodingWords)}, []uint64{0x18, 0, 0, 1 << 8}),
+	} {
+		got, err := Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Key() != FromIndices(3, 4, 200).Key() {
+			t.Errorf("Decode(% x) returned the wrong bits: %v", data, FormatRangeList(got))
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{0},
+		{99},
+		[]byte("bset"),
+		[]byte("bset\x01"),
+		[]byte("bset\x02\x01"),             // unknown version
+		[]byte("bset\x01\x63"),             // unknown encoding
+		[]byte("bset\x01\x01\x01\x02\x03"), // partial word
+		[]byte("bset\x01\x02"),             // missing count
+		[]byte("bset\x01\x02\x02\x01"),     // missing gap
+		[]byte("bset\x01\x02\x01\x01\x00"), // trailing bytes
+		[]byte("bset\x01\x02\x80"),         // truncated count
+		[]byte("bset\x01\x02\xff\x01"),     // count larger than the data
+		[]byte("bset\x01\x02\x02\xff\xff\xff\xff\x0f\x00"), // past the highest bit index
+		[]byte("bset\x01\x03\x01\x01"),                     // missing run length
+		[]byte("bset\x01\x03\x01\x01\xff\xff\xff\xff\x0f"), // run past the highest bit index
+		{byte(EncodingRuns), 1, 1, 1},                      // runs were never written without a header
+	} {
+		if _, err := Decode(data); err == nil {
+			t.Errorf("Decode(% x) should fail", data)
+		}
+	}
+}
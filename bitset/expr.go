@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// bitset.Expr is a lazy boolean expression over sets, e.g. And(Of(a), Or(Of(b), Not(Of(c), n))).
+//
+// Chaining Union and Intersect allocates every intermediate result. Eval instead computes each word of the result
+// from the corresponding words of all the sets in a single pass, and only allocates the result.
+// It reads the words of each set in its own representation, and skips the words that are 0 in any operand of And,
+// or in the first operand of AndNot, so sparse sets spread over a wide range are never expanded into words.
+type Expr interface {
+	// Eval returns the result of the expression as a bitset.Set.
+	Eval() Set
+
+	// compile returns the words of the result of the expression.
+	compile() compiled
+}
+
+// compiled gives access to the words of the result of an expression, which must be read in ascending order.
+type compiled struct {
+	span int                // the number of words the result can span
+	word func(i int) uint64 // returns word i
+	next func(i int) int    // returns the first index at or after i whose word may be nonzero, or noWord
+}
+
+// noWord is returned by compiled.next when there are no more nonzero words.
+const noWord = math.MaxInt
+
+// Of returns an expression for the bits of s.
+func Of(s Set) Expr {
+	return setExpr{s}
+}
+
+// And returns an expression for the bits that are set in every one of xs. And() is empty.
+func And(xs ...Expr) Expr {
+	return opExpr{op: and, xs: slices.Clone(xs), span: slices.Min[[]int], absorbing: len(xs)}
+}
+
+// Or returns an expression for the bits that are set in any of xs. Or() is empty.
+func Or(xs ...Expr) Expr {
+	return opExpr{op: or, xs: slices.Clone(xs), span: slices.Max[[]int]}
+}
+
+// Xor returns an expression for the bits that are set in an odd number of xs. Xor() is empty.
+func Xor(xs ...Expr) Expr {
+	return opExpr{op: xor, xs: slices.Clone(xs), span: slices.Max[[]int]}
+}
+
+// AndNot returns an expression for the bits that are set in x but not in y.
+func AndNot(x, y Expr) Expr {
+	return opExpr{op: andNot, xs: []Expr{x, y}, span: func(lens []int) int { return lens[0] }, absorbing: 1}
+}
+
+// Not returns an expression for the bits in [0, size) that are not set in x.
+func Not(x Expr, size uint32) Expr {
+	return notExpr{x, size}
+}
+
+type setExpr struct{ s Set }
+
+type opExpr struct {
+	op        wordOp
+	xs        []Expr
+	span      func(lens []int) int // the number of words the result can span, given those of xs
+	absorbing int                  // the number of leading xs whose zero words make the result 0
+}
+
+type notExpr struct {
+	x    Expr
+	size uint32
+}
+
+func (e setExpr) Eval() Set { return e.s }
+func (e opExpr) Eval() Set  { return evalExpr(e) }
+func (e notExpr) Eval() Set { return evalExpr(e) }
+
+func (e setExpr) compile() compiled {
+	s := materialized(e.s)
+	if words, ok := denseWords(s); ok {
+		return compiled{
+			span: len(words),
+			word: func(i int) uint64 { return wordAt(words, i) },
+			next: memoNext(func(i int) int {
+				for ; i < len(words); i++ {
+					if words[i] != 0 {
+						return i
+					}
+				}
+				return noWord
+			}),
+		}
+	}
+
+	switch s := s.(type) {
+	case trieBitSet:
+		return compiled{
+			span: s.w,
+			word: func(i int) uint64 {
+				if i >= s.w {
+					return 0
+				}
+				return s.word(i)
+			},
+			next: memoNext(func(i int) int {
+				if i >= s.w {
+					return noWord
+				}
+				if j, ok := nextSetBit(s, uint32(i*64)); ok {
+					return int(j / 64)
+				}
+				return noWord
+			}),
+		}
+	case runBitSet:
+		return compileRuns(s)
+	}
+	// Sparse and chunked sets have at most a few words per set bit
+	return compileWordList(nonzeroWords(s))
+}
+
+// memoNext returns next, but without searching again while the last index it returned is still ahead,
+// so a search that skips far ahead isn't repeated for every word before that.
+func memoNext(next func(i int) int) func(i int) int {
+	last := -1
+	return func(i int) int {
+		if last < i {
+			last = next(i)
+		}
+		return last
+	}
+}
+
+// compileRuns returns the words of the set with the given runs, filling each word from the runs that overlap it.
+func compileRuns(runs []run[uint32]) compiled {
+	span := 0
+	if len(runs) > 0 {
+		span = int(runs[len(runs)-1].last/64) + 1
+	}
+	return compiled{
+		span: span,
+		word: func(i int) uint64 {
+			lo := uint64(i) * 64
+			if lo >= 1<<32 {
+				return 0
+			}
+			k, _ := searchRuns(runs, uint32(lo))
+			runs = runs[k:] // words are read in ascending order
+			var w [1]uint64
+			for _, r := range runs {
+				if uint64(r.start) >= lo+64 {
+					break
+				}
+				fillRange(w[:], uint32(max(uint64(r.start), lo)-lo), uint32(min(uint64(r.last), lo+63)-lo))
+			}
+			return w[0]
+		},
+		next: func(i int) int {
+			lo := uint64(i) * 64
+			if lo >= 1<<32 {
+				return noWord
+			}
+			k, _ := searchRuns(runs, uint32(lo))
+			if k == len(runs) {
+				return noWord
+			}
+			return max(i, int(runs[k].start/64))
+		},
+	}
+}
+
+// compileWordList returns the words of a set with the given nonzero words.
+func compileWordList(list []indexedWord) compiled {
+	span := 0
+	if len(list) > 0 {
+		span = list[len(list)-1].i + 1
+	}
+	// seek drops the words before i from list, since words are read in ascending order
+	seek := func(i int) {
+		k, _ := slices.BinarySearchFunc(list, i, func(iw indexedWord, i int) int { return cmp.Compare(iw.i, i) })
+		list = list[k:]
+	}
+	return compiled{
+		span: span,
+		word: func(i int) uint64 {
+			seek(i)
+			if len(list) > 0 && list[0].i == i {
+				return list[0].w
+			}
+			return 0
+		},
+		next: func(i int) int {
+			seek(i)
+			if len(list) == 0 {
+				return noWord
+			}
+			return list[0].i
+		},
+	}
+}
+
+func (e opExpr) compile() compiled {
+	if len(e.xs) == 0 {
+		return compiled{word: func(int) uint64 { return 0 }, next: func(int) int { return noWord }}
+	}
+
+	lens := make([]int, len(e.xs))
+	cs := make([]compiled, len(e.xs))
+	for i, x := range e.xs {
+		cs[i] = x.compile()
+		lens[i] = cs[i].span
+	}
+
+	op, absorbing := e.op, e.absorbing
+	word := func(i int) uint64 {
+		w := cs[0].word(i)
+		for _, c := range cs[1:] {
+			if absorbing > 0 && w == 0 {
+				break
+			}
+			w = op(w, c.word(i))
+		}
+		return w
+	}
+
+	next := func(i int) int {
+		// The result may be nonzero wherever any operand may be
+		j := noWord
+		for _, c := range cs {
+			j = min(j, c.next(i))
+		}
+		return j
+	}
+	if absorbing > 0 {
+		next = func(i int) int {
+			// The result may only be nonzero where every absorbing operand may be
+			for i != noWord {
+				j := i
+				for _, c := range cs[:absorbing] {
+					if j = c.next(j); j == noWord {
+						return noWord
+					}
+				}
+				if j == i {
+					return i
+				}
+				i = j
+			}
+			return noWord
+		}
+	}
+	return compiled{span: e.span(lens), word: word, next: next}
+}
+
+func (e notExpr) compile() compiled {
+	c := e.x.compile()
+	n := int((uint64(e.size) + 63) / 64)
+	return compiled{
+		span: n,
+		word: func(i int) uint64 {
+			w := ^c.word(i)
+			if rem := e.size % 64; i == n-1 && rem != 0 {
+				w &= 1<<rem - 1
+			}
+			return w
+		},
+		next: func(i int) int {
+			if i < n {
+				return i
+			}
+			return noWord
+		},
+	}
+}
+
+// evalExpr returns the result of e, computed word by word from the words that may be nonzero,
+// and stored in the most suitable representation.
+func evalExpr(e Expr) Set {
+	c := e.compile()
+	var list []indexedWord
+	for i := c.next(0); i < c.span; i = c.next(i + 1) {
+		if w := c.word(i); w != 0 {
+			list = append(list, indexedWord{i, w})
+		}
+	}
+	return fromWordList(list)
+}
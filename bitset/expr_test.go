@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	a := FromIndices(1, 2, 3, 100, 200)
+	b := FromIndices(2, 3, 4, 200, 300)
+	c := FromIndices(3, 150, 200)
+
+	notA := New()
+	for i := range uint32(70) {
+		if !a.Test(i) {
+			notA = notA.Set(i)
+		}
+	}
+
+	tests := []struct {
+		name string
+		e    Expr
+		want Set
+	}{
+		{"Of", Of(a), a},
+		{"And", And(Of(a), Of(b), Of(c)), a.Intersect(b).Intersect(c)},
+		{"Or", Or(Of(a), Of(b), Of(c)), a.Union(b).Union(c)},
+		{"Xor", Xor(Of(a), Of(b)), a.SymmetricDifference(b)},
+		{"AndNot", AndNot(Of(a), Of(b)), a.Difference(b)},
+		{"Not", Not(Of(a), 70), notA},
+		{"nested", And(Of(a), Or(Of(b), Not(Of(c), 400))), a.Intersect(b.Union(FromIndices(1, 2, 100)))},
+		{"empty And", And(), New()},
+		{"empty Or", Or(), New()},
+	}
+	for _, tt := range tests {
+		got := tt.e.Eval()
+		checkCanonical(t, got)
+		if got.Key() != tt.want.Key() {
+			t.Errorf("%s: expected %v, got %v", tt.name, slices.Collect(tt.want.Indices()), slices.Collect(got.Indices()))
+		}
+	}
+}
+
+func TestExprAllSets(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			want := a.s.Intersect(b.s).Union(a.s.SymmetricDifference(b.s))
+			got := Or(And(Of(a.s), Of(b.s)), Xor(Of(a.s), Of(b.s))).Eval()
+			if got.Key() != want.Key() {
+				t.Errorf("%s, %s: expression has incorrect result", a.name, b.name)
+			}
+			if got := AndNot(Of(a.s), Of(b.s)).Eval(); got.Key() != a.s.Difference(b.s).Key() {
+				t.Errorf("%s, %s: AndNot has incorrect result", a.name, b.name)
+			}
+		}
+	}
+}
+
+func TestExprWideSparse(t *testing.T) {
+	a, b := FromIndices(1, 4e9), FromIndices(1, 5, 4e9)
+	c := NewRangeSet(Range{0, 3e9}).Bits()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	and := And(Of(a), Of(b), Of(c)).Eval()
+	or := Or(Of(a), Of(b)).Eval()
+	andNot := AndNot(Of(b), Of(a)).Eval()
+	runtime.ReadMemStats(&after)
+
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<16 {
+		t.Errorf("Evaluating expressions over sparse sets allocated %d bytes", n)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want Set
+	}{
+		{"And", and, a.Intersect(b).Intersect(c)},
+		{"Or", or, a.Union(b)},
+		{"AndNot", andNot, b.Difference(a)},
+	} {
+		checkCanonical(t, tt.got)
+		if !Equal(tt.got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, slices.Collect(tt.want.Indices()), slices.Collect(tt.got.Indices()))
+		}
+	}
+}
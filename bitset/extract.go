@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// Extract returns a new bitset.Set with the bits of s at the positions of the set bits of mask, packed into the lowest
+// bits, like the PEXT instruction: bit k of the result is the bit of s at the position of the k-th set bit of mask.
+// It uses PEXT on amd64 CPUs that support BMI2.
+// Neither set is modified.
+func Extract(s, mask Set) Set {
+	sList, maskList := nonzeroWords(s), nonzeroWords(mask)
+	total := 0
+	for _, m := range maskList {
+		total += bits.OnesCount64(m.w)
+	}
+
+	out := make([]uint64, (total+63)/64)
+	pos, j := 0, 0
+	for _, m := range maskList {
+		for j < len(sList) && sList[j].i < m.i {
+			j++
+		}
+		if j < len(sList) && sList[j].i == m.i {
+			if x := pextWord(sList[j].w, m.w); x != 0 {
+				out[pos/64] |= x << (pos % 64)
+				if pos%64 != 0 && pos/64+1 < len(out) {
+					out[pos/64+1] |= x >> (64 - pos%64)
+				}
+			}
+		}
+		pos += bits.OnesCount64(m.w)
+	}
+	return fromWords(out)
+}
+
+// Deposit returns a new bitset.Set with the lowest bits of s placed at the positions of the set bits of mask,
+// like the PDEP instruction: the bit at the position of the k-th set bit of mask is bit k of s.
+// It is the inverse of Extract, so Deposit(Extract(s, mask), mask) is s.Intersect(mask).
+// It uses PDEP on amd64 CPUs that support BMI2.
+// Neither set is modified.
+func Deposit(s, mask Set) Set {
+	sList, maskList := nonzeroWords(s), nonzeroWords(mask)
+	var out []indexedWord
+	pos, j := 0, 0
+	for _, m := range maskList {
+		n := bits.OnesCount64(m.w)
+
+		// Read the n bits of s starting at pos, which span at most two words
+		lo := pos / 64
+		for j < len(sList) && sList[j].i < lo {
+			j++
+		}
+		var x uint64
+		k := j
+		if k < len(sList) && sList[k].i == lo {
+			x = sList[k].w >> (pos % 64)
+			k++
+		}
+		if pos%64 != 0 && k < len(sList) && sList[k].i == lo+1 {
+			x |= sList[k].w << (64 - pos%64)
+		}
+		if n < 64 {
+			x &= 1<<n - 1
+		}
+
+		if w := pdepWord(x, m.w); w != 0 {
+			out = append(out, indexedWord{m.i, w})
+		}
+		pos += n
+	}
+	return fromWordList(out)
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractDeposit(t *testing.T) {
+	sets := testSets()
+	for _, s := range sets {
+		for _, mask := range sets {
+			maskIndices := slices.Collect(mask.s.Indices())
+
+			var want []uint32
+			for k, i := range maskIndices {
+				if s.s.Test(i) {
+					want = append(want, uint32(k))
+				}
+			}
+			extracted := Extract(s.s, mask.s)
+			if got := slices.Collect(extracted.Indices()); !slices.Equal(got, want) {
+				t.Errorf("Extract(%s, %s) returned %d bits, want %d", s.name, mask.name, len(got), len(want))
+			}
+			checkCanonical(t, extracted)
+
+			want = want[:0]
+			for k, i := range maskIndices {
+				if s.s.Test(uint32(k)) {
+					want = append(want, i)
+				}
+			}
+			deposited := Deposit(s.s, mask.s)
+			if got := slices.Collect(deposited.Indices()); !slices.Equal(got, want) {
+				t.Errorf("Deposit(%s, %s) returned %d bits, want %d", s.name, mask.name, len(got), len(want))
+			}
+			checkCanonical(t, deposited)
+
+			if Deposit(extracted, mask.s).Key() != s.s.Intersect(mask.s).Key() {
+				t.Errorf("Deposit(Extract(%s, %s)) should be their intersection", s.name, mask.name)
+			}
+		}
+	}
+}
+
+func TestExtractExample(t *testing.T) {
+	// The squares on the b file of a chess board, and the occupied squares
+	file := FromIndices(1, 9, 17, 25, 33, 41, 49, 57)
+	occupied := FromIndices(1, 2, 17, 40, 57)
+	if got := slices.Collect(Extract(occupied, file).Indices()); !slices.Equal(got, []uint32{0, 2, 7}) {
+		t.Errorf("Extract = %v, want [0 2 7]", got)
+	}
+	if got := slices.Collect(Deposit(FromIndices(0, 7), file).Indices()); !slices.Equal(got, []uint32{1, 57}) {
+		t.Errorf("Deposit = %v, want [1 57]", got)
+	}
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bitset.FlagNames is a registry of the names of the values of a flag type E, for use with FlagSet.
+type FlagNames[E Index] struct {
+	values []E // sorted
+	names  []string
+	byName map[string]E
+}
+
+// NewFlagNames creates and returns a new bitset.FlagNames with the given name for each flag.
+// It panics if two flags have the same name, or if a name is empty or contains '|'.
+func NewFlagNames[E Index](names map[E]string) *FlagNames[E] {
+	n := &FlagNames[E]{byName: make(map[string]E, len(names))}
+	for v := range names {
+		mustBitIndex(v)
+		n.values = append(n.values, v)
+	}
+	slices.Sort(n.values)
+
+	for _, v := range n.values {
+		name := names[v]
+		if name == "" || strings.Contains(name, "|") {
+			panic(fmt.Sprintf("bitset: invalid flag name %q", name))
+		}
+		if _, ok := n.byName[name]; ok {
+			panic(fmt.Sprintf("bitset: duplicate flag name %q", name))
+		}
+		n.names = append(n.names, name)
+		n.byName[name] = v
+	}
+	return n
+}
+
+// BitName returns the name of the flag with the given bit index, or an empty string if it has none,
+// so a FlagNames is a Namer.
+func (n *FlagNames[E]) BitName(bitIndex uint32) string {
+	if uint32(E(bitIndex)) != bitIndex {
+		return "" // not a value of E
+	}
+	if k, ok := slices.BinarySearch(n.values, E(bitIndex)); ok {
+		return n.names[k]
+	}
+	return ""
+}
+
+// New creates and returns a new bitset.FlagSet with the given flags set.
+func (n *FlagNames[E]) New(flags ...E) FlagSet[E] {
+	return FlagSet[E]{NewTyped(flags...), n}
+}
+
+// ParseNames returns a bitset.FlagSet with the flags named in s, separated by '|', e.g. "read|write".
+// Whitespace around names is ignored, and an empty string returns an empty set.
+func (n *FlagNames[E]) ParseNames(s string) (FlagSet[E], error) {
+	fs := n.New()
+	if strings.TrimSpace(s) == "" {
+		return fs, nil
+	}
+
+	for name := range strings.SplitSeq(s, "|") {
+		v, ok := n.byName[strings.TrimSpace(name)]
+		if !ok {
+			return FlagSet[E]{}, fmt.Errorf("bitset: unknown flag name %q", strings.TrimSpace(name))
+		}
+		fs.t = fs.t.Set(v)
+	}
+	return fs, nil
+}
+
+// bitset.FlagSet is an immutable set of flags of type E, which knows the names of its flags.
+// The zero value is an empty set without any flag names.
+type FlagSet[E Index] struct {
+	t     Typed[E]
+	names *FlagNames[E]
+}
+
+// Typed returns the flags of f as a bitset.Typed.
+func (f FlagSet[E]) Typed() Typed[E] {
+	return f.t
+}
+
+// Test reports whether the given flag is set.
+func (f FlagSet[E]) Test(flag E) bool {
+	return f.t.Test(flag)
+}
+
+// Set returns a new bitset.FlagSet with the given flag set.
+// The original bitset.FlagSet is not modified.
+func (f FlagSet[E]) Set(flag E) FlagSet[E] {
+	f.t = f.t.Set(flag)
+	return f
+}
+
+// Clear returns a new bitset.FlagSet with the given flag cleared.
+// The original bitset.FlagSet is not modified.
+func (f FlagSet[E]) Clear(flag E) FlagSet[E] {
+	f.t = f.t.Clear(flag)
+	return f
+}
+
+// Union returns a new bitset.FlagSet with the flags that are set in either f or other.
+// Neither set is modified.
+func (f FlagSet[E]) Union(other FlagSet[E]) FlagSet[E] {
+	f.t = f.t.Union(other.t)
+	return f
+}
+
+// Intersect returns a new bitset.FlagSet with the flags that are set in both f and other.
+// Neither set is modified.
+func (f FlagSet[E]) Intersect(other FlagSet[E]) FlagSet[E] {
+	f.t = f.t.Intersect(other.t)
+	return f
+}
+
+// Difference returns a new bitset.FlagSet with the flags that are set in f but not in other.
+// Neither set is modified.
+func (f FlagSet[E]) Difference(other FlagSet[E]) FlagSet[E] {
+	f.t = f.t.Difference(other.t)
+	return f
+}
+
+// SymmetricDifference returns a new bitset.FlagSet with the flags that are set in exactly one of f and other.
+// Neither set is modified.
+func (f FlagSet[E]) SymmetricDifference(other FlagSet[E]) FlagSet[E] {
+	f.t = f.t.SymmetricDifference(other.t)
+	return f
+}
+
+// Names returns the names of the flags set in f, in ascending order of their values.
+// Flags without a registered name are returned as their decimal value.
+func (f FlagSet[E]) Names() []string {
+	var names []string
+	for _, i := range indicesOf(f.t.Bits()) {
+		if f.names != nil {
+			if k, ok := slices.BinarySearch(f.names.values, E(i)); ok {
+				names = append(names, f.names.names[k])
+				continue
+			}
+		}
+		names = append(names, strconv.FormatUint(uint64(i), 10))
+	}
+	return names
+}
+
+// String returns the names of the flags set in f separated by '|', e.g. "read|write".
+func (f FlagSet[E]) String() string {
+	return strings.Join(f.Names(), "|")
+}
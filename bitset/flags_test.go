@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+type testPerm uint8
+
+const (
+	permRead testPerm = iota
+	permWrite
+	permExec
+)
+
+var testPermNames = NewFlagNames(map[testPerm]string{
+	permRead:  "read",
+	permWrite: "write",
+	permExec:  "exec",
+})
+
+func TestFlagSet(t *testing.T) {
+	f := testPermNames.New(permExec, permRead)
+	if !f.Test(permRead) || f.Test(permWrite) || !f.Test(permExec) {
+		t.Error("FlagSet has incorrect flags")
+	}
+	if got := f.Names(); !slices.Equal(got, []string{"read", "exec"}) {
+		t.Errorf("Expected names in value order, got %v", got)
+	}
+	if s := f.String(); s != "read|exec" {
+		t.Errorf("Expected read|exec, got %s", s)
+	}
+
+	// Flags without a name are printed as numbers
+	if s := f.Set(9).Clear(permRead).String(); s != "exec|9" {
+		t.Errorf("Expected exec|9, got %s", s)
+	}
+	if s := (FlagSet[testPerm]{}).Set(permWrite).String(); s != "1" {
+		t.Errorf("Expected 1 for a FlagSet without names, got %s", s)
+	}
+	if s := testPermNames.New().String(); s != "" {
+		t.Errorf("Expected an empty string for an empty FlagSet, got %q", s)
+	}
+
+	other := testPermNames.New(permWrite, permExec)
+	if s := f.Union(other).String(); s != "read|write|exec" {
+		t.Errorf("Union: got %s", s)
+	}
+	if s := f.Intersect(other).String(); s != "exec" {
+		t.Errorf("Intersect: got %s", s)
+	}
+	if s := f.Difference(other).String(); s != "read" {
+		t.Errorf("Difference: got %s", s)
+	}
+	if s := f.SymmetricDifference(other).String(); s != "read|write" {
+		t.Errorf("SymmetricDifference: got %s", s)
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	f, err := testPermNames.ParseNames("write | read")
+	if err != nil {
+		t.Fatalf("ParseNames failed: %v", err)
+	}
+	if s := f.String(); s != "read|write" {
+		t.Errorf("Expected read|write, got %s", s)
+	}
+
+	if f, err := testPermNames.ParseNames(" "); err != nil || f.String() != "" {
+		t.Errorf("Expected an empty set, got %v, %v", f, err)
+	}
+	if _, err := testPermNames.ParseNames("read|delete"); err == nil {
+		t.Error("Expected an error for an unknown flag name")
+	}
+	if _, err := testPermNames.ParseNames("read||write"); err == nil {
+		t.Error("Expected an error for an empty flag name")
+	}
+}
+
+func TestNewFlagNamesPanics(t *testing.T) {
+	for _, names := range []map[testPerm]string{
+		{permRead: "r", permWrite: "r"},
+		{permRead: ""},
+		{permRead: "a|b"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected NewFlagNames(%v) to panic", names)
+				}
+			}()
+			NewFlagNames(names)
+		}()
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "slices"
+
+// bitset.History records successive versions of a Set, with undo and redo.
+//
+// Only the newest version is stored in full. Every older version is stored as the bits that changed since it,
+// so a long history of small edits to a big set takes little memory.
+//
+// A History is not safe for concurrent use.
+type History struct {
+	latest  Set    // the newest version
+	deltas  []Set  // deltas[i] has the bits that differ between versions oldest+i and oldest+i+1
+	oldest  uint64 // the oldest retained version
+	version uint64 // the current version, which Undo and Redo move from
+	current Set    // the set at the current version
+}
+
+// NewHistory creates and returns a new bitset.History with s at version 0.
+func NewHistory(s Set) *History {
+	return &History{latest: s, current: s}
+}
+
+// Current returns the set at the current version, and the current version.
+func (h *History) Current() (Set, uint64) {
+	return h.current, h.version
+}
+
+// Oldest returns the oldest version that can be retrieved with At.
+func (h *History) Oldest() uint64 {
+	return h.oldest
+}
+
+// Newest returns the newest version that can be retrieved with At.
+func (h *History) Newest() uint64 {
+	return h.oldest + uint64(len(h.deltas))
+}
+
+// Record adds s as a new version after the current one, makes it the current version, and returns its version.
+// If versions were undone, they are discarded and can no longer be redone, like in a text editor.
+func (h *History) Record(s Set) uint64 {
+	if h.version < h.Newest() {
+		h.deltas = h.deltas[:h.version-h.oldest]
+	}
+
+	h.deltas = append(h.deltas, h.current.SymmetricDifference(s))
+	h.latest, h.current = s, s
+	h.version++
+	return h.version
+}
+
+// At returns the set at the given version, or false if the version is not retained.
+func (h *History) At(version uint64) (Set, bool) {
+	if version < h.oldest || version > h.Newest() {
+		return nil, false
+	}
+
+	s := h.latest
+	for v := h.Newest(); v > version; v-- {
+		s = s.SymmetricDifference(h.deltas[v-1-h.oldest])
+	}
+	return s, true
+}
+
+// Undo moves the current version back by one and returns its set, or false if the current version is the oldest one.
+func (h *History) Undo() (Set, bool) {
+	if h.version == h.oldest {
+		return h.current, false
+	}
+
+	h.version--
+	h.current = h.current.SymmetricDifference(h.deltas[h.version-h.oldest])
+	return h.current, true
+}
+
+// Redo moves the current version forward by one and returns its set, or false if the current version is the newest one.
+func (h *History) Redo() (Set, bool) {
+	if h.version == h.Newest() {
+		return h.current, false
+	}
+
+	h.current = h.current.SymmetricDifference(h.deltas[h.version-h.oldest])
+	h.version++
+	return h.current, true
+}
+
+// Compact discards all but the newest keep versions, to free the memory of their changes.
+// At least the newest version is always kept. If the current version is discarded,
+// the oldest remaining version becomes the current one.
+func (h *History) Compact(keep int) {
+	drop := len(h.deltas) + 1 - max(keep, 1)
+	if drop <= 0 {
+		return
+	}
+
+	h.deltas = slices.Clone(h.deltas[drop:])
+	h.oldest += uint64(drop)
+	if h.version < h.oldest {
+		h.current, _ = h.At(h.oldest)
+		h.version = h.oldest
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "testing"
+
+// historyOf returns a history with versions 0 to n, where version v has the bits 0, 100, ..., (v-1)*100 set.
+func historyOf(n int) *History {
+	h := NewHistory(New())
+	s := New()
+	for i := range n {
+		s = s.Set(uint32(i) * 100)
+		h.Record(s)
+	}
+	return h
+}
+
+func TestHistoryAt(t *testing.T) {
+	h := historyOf(5)
+	if h.Oldest() != 0 || h.Newest() != 5 {
+		t.Fatalf("Expected versions [0, 5], got [%d, %d]", h.Oldest(), h.Newest())
+	}
+	for v := range uint64(6) {
+		s, ok := h.At(v)
+		if !ok || s.Count() != int(v) || (v > 0 && !s.Test(uint32(v-1)*100)) {
+			t.Errorf("At(%d) returned incorrect set", v)
+		}
+	}
+	if _, ok := h.At(6); ok {
+		t.Error("At should fail for a future version")
+	}
+	if s, v := h.Current(); v != 5 || s.Count() != 5 {
+		t.Errorf("Expected current version 5, got %d", v)
+	}
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	h := historyOf(3)
+
+	s, ok := h.Undo()
+	if !ok || s.Count() != 2 || s.Test(200) {
+		t.Error("Undo should return the previous version")
+	}
+	h.Undo()
+	if s, ok := h.Undo(); !ok || s.Count() != 0 {
+		t.Error("Undo should reach version 0")
+	}
+	if _, ok := h.Undo(); ok {
+		t.Error("Undo should fail at the oldest version")
+	}
+
+	if s, ok := h.Redo(); !ok || s.Count() != 1 {
+		t.Error("Redo should return the next version")
+	}
+	if _, v := h.Current(); v != 1 {
+		t.Errorf("Expected current version 1, got %d", v)
+	}
+
+	// Recording after undo discards the undone versions
+	if v := h.Record(FromIndices(7)); v != 2 {
+		t.Errorf("Expected version 2, got %d", v)
+	}
+	if h.Newest() != 2 {
+		t.Errorf("Expected newest version 2, got %d", h.Newest())
+	}
+	if _, ok := h.Redo(); ok {
+		t.Error("Redo should fail after recording a new version")
+	}
+	if s, _ := h.At(1); s.Count() != 1 || !s.Test(0) {
+		t.Error("Older versions should be unchanged")
+	}
+}
+
+func TestHistoryCompact(t *testing.T) {
+	h := historyOf(10)
+	h.Undo()
+	h.Compact(3)
+	if h.Oldest() != 8 || h.Newest() != 10 {
+		t.Fatalf("Expected versions [8, 10], got [%d, %d]", h.Oldest(), h.Newest())
+	}
+	if _, ok := h.At(7); ok {
+		t.Error("Compacted versions should be discarded")
+	}
+	if s, ok := h.At(8); !ok || s.Count() != 8 {
+		t.Error("Retained versions should be unchanged")
+	}
+	if s, v := h.Current(); v != 9 || s.Count() != 9 {
+		t.Errorf("Expected current version 9, got %d", v)
+	}
+
+	h.Compact(0)
+	if s, v := h.Current(); v != 10 || s.Count() != 10 || h.Oldest() != 10 {
+		t.Errorf("Expected only version 10 to remain, got current version %d", v)
+	}
+	if _, ok := h.Undo(); ok {
+		t.Error("Undo should fail after compacting everything")
+	}
+}
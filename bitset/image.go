@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// WritePBM writes s to w as a binary PBM image, with width pixels per row, where the pixel for bit i is in row
+// i/width and column i%width, and is black if the bit is set, e.g. to eyeball the allocation pattern of a huge set.
+// The image has as many rows as it takes to show the highest set bit, and at least one.
+func WritePBM(w io.Writer, s Set, width int) error {
+	height, err := imageHeight(s, width)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P4\n%d %d\n", width, height)
+	// Every row is padded to a whole byte, with the leftmost pixel in the most significant bit
+	row := make([]byte, (width+7)/8)
+	y := 0
+	for i := range s.Indices() {
+		for ; y < int(uint64(i)/uint64(width)); y++ {
+			bw.Write(row)
+			clear(row)
+		}
+		x := int(uint64(i) % uint64(width))
+		row[x/8] |= 0x80 >> (x % 8)
+	}
+	for ; y < height; y++ {
+		bw.Write(row)
+		clear(row)
+	}
+	return bw.Flush()
+}
+
+// WritePNG writes s to w as a 1-bit PNG image, in the layout described by WritePBM.
+func WritePNG(w io.Writer, s Set, width int) error {
+	height, err := imageHeight(s, width)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.White, color.Black})
+	for i := range s.Indices() {
+		img.SetColorIndex(int(uint64(i)%uint64(width)), int(uint64(i)/uint64(width)), 1)
+	}
+	return png.Encode(w, img)
+}
+
+// imageHeight returns the number of rows of the given width it takes to show the highest set bit of s, and at least one.
+func imageHeight(s Set, width int) (int, error) {
+	if width <= 0 {
+		return 0, fmt.Errorf("bitset: invalid image width %d", width)
+	}
+	n := s.Count()
+	if n == 0 {
+		return 1, nil
+	}
+	return int(uint64(nthBit(s, n-1))/uint64(width)) + 1, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestWritePBM(t *testing.T) {
+	var buf bytes.Buffer
+	// 10 pixels per row, so every row takes 2 bytes
+	if err := WritePBM(&buf, FromIndices(0, 9, 12, 25), 10); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte("P4\n10 3\n"), 0x80, 0x40, 0x20, 0x00, 0x04, 0x00)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Expected % x, got % x", want, buf.Bytes())
+	}
+
+	buf.Reset()
+	if err := WritePBM(&buf, New(), 8); err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte("P4\n8 1\n"), 0); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Expected one empty row for an empty set, got % x", buf.Bytes())
+	}
+
+	if err := WritePBM(&buf, New(), 0); err == nil {
+		t.Error("Expected an error for a zero width")
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	for _, ts := range testSets() {
+		var buf bytes.Buffer
+		if err := WritePNG(&buf, ts.s, 1000); err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+
+		bounds := img.Bounds()
+		b := NewBuilder(0)
+		for y := range bounds.Dy() {
+			for x := range bounds.Dx() {
+				if r, _, _, _ := img.At(x, y).RGBA(); r == 0 {
+					b = b.With(uint32(y*1000 + x))
+				}
+			}
+		}
+		if got := b.Build(); got.Key() != ts.s.Key() {
+			t.Errorf("%s: image has %d black pixels, expected %d", ts.name, got.Count(), ts.s.Count())
+		}
+	}
+}
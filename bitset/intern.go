@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"runtime"
+	"slices"
+	"sync"
+	"unsafe"
+	"weak"
+)
+
+// internSeed is the seed of the hashes of the sets in every Interner.
+var internSeed = maphash.MakeSeed()
+
+// bitset.Interner maps sets with the same bits to a single shared instance, so that a workload holding many copies
+// of the same few sets, e.g. entity archetypes or permission templates, only keeps one of each in memory.
+//
+// The interner stores the sets by hash, and only holds them weakly, so it doesn't keep a copy of their bits,
+// and a set is removed some time after it is no longer used anywhere else, like with the unique package.
+// Sets of at most 192 bits are stored inline, so they have no memory to share, and are returned as is.
+// The zero value is an empty interner. An Interner is safe for concurrent use.
+type Interner struct {
+	mu      sync.RWMutex
+	buckets map[uint64][]internEntry // by the hash of the set
+	n       int                      // number of entries in buckets
+}
+
+// internEntry is a set in an Interner, without its memory, which the entry only points to weakly.
+type internEntry struct {
+	data  weak.Pointer[byte] // the start of the memory of the set
+	n     int                // the length of the memory of the set, in elements
+	shell Set                // the set with its memory removed
+}
+
+// internRef identifies an entry of an Interner, to remove it once its memory is unreachable.
+type internRef struct {
+	h    uint64
+	data weak.Pointer[byte]
+}
+
+// NewInterner creates and returns a new empty bitset.Interner.
+func NewInterner() *Interner {
+	return &Interner{}
+}
+
+// Intern returns the set in x with the same bits as s, adding s to x if it has none yet.
+// Sets returned by the same Interner for equal sets are the same instance, so they share their memory.
+// Sets backed by memory that is not owned by the set, from FromMappedBytes or UnsafeFromWords, are copied first.
+func (x *Interner) Intern(s Set) Set {
+	s = materialized(s)
+	if _, ok := s.(mappedBitSet); ok {
+		// The memory may not be in the Go heap, so the interner can't point to it weakly
+		s = fromWords(AppendWords(nil, s))
+	}
+	p, n, shell := splitMemory(s)
+	if p == nil {
+		return s
+	}
+
+	h := hashSet(s)
+	x.mu.RLock()
+	canonical, ok := x.find(h, s)
+	x.mu.RUnlock()
+	if ok {
+		return canonical
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if canonical, ok := x.find(h, s); ok {
+		return canonical
+	}
+	if x.buckets == nil {
+		x.buckets = make(map[uint64][]internEntry)
+	}
+	e := internEntry{weak.Make(p), n, shell}
+	x.buckets[h] = append(x.buckets[h], e)
+	x.n++
+	runtime.AddCleanup(p, x.remove, internRef{h, e.data})
+	return s
+}
+
+// Lookup returns the set in x with the same bits as s, or false if there is none.
+func (x *Interner) Lookup(s Set) (Set, bool) {
+	h := hashSet(s)
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.find(h, s)
+}
+
+// Len returns the number of distinct sets in x.
+// It may include sets that are no longer used anywhere else, but haven't been removed yet.
+func (x *Interner) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.n
+}
+
+// find returns the set with hash h in x that is equal to s, if it is still in use.
+func (x *Interner) find(h uint64, s Set) (Set, bool) {
+	for _, e := range x.buckets[h] {
+		if p := e.data.Value(); p != nil {
+			if canonical := joinMemory(e.shell, p, e.n); Equal(canonical, s) {
+				return canonical, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// remove removes the entry ref from x, once its memory is no longer reachable.
+func (x *Interner) remove(ref internRef) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	bucket := x.buckets[ref.h]
+	i := slices.IndexFunc(bucket, func(e internEntry) bool { return e.data == ref.data })
+	if i < 0 {
+		return
+	}
+	if bucket = slices.Delete(bucket, i, i+1); len(bucket) == 0 {
+		delete(x.buckets, ref.h)
+	} else {
+		x.buckets[ref.h] = bucket
+	}
+	x.n--
+}
+
+// hashSet returns the hash of the bits of s, which is the same for all of its representations.
+func hashSet(s Set) uint64 {
+	var h maphash.Hash
+	h.SetSeed(internSeed)
+	var buf [16]byte
+	for _, iw := range nonzeroWords(s) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(iw.i))
+		binary.LittleEndian.PutUint64(buf[8:], iw.w)
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// splitMemory returns the start and length of the memory s points to, and s without that memory,
+// so joinMemory can put them back together. It returns a nil pointer if s doesn't point to any memory.
+func splitMemory(s Set) (*byte, int, Set) {
+	switch s := s.(type) {
+	case largeBitSet:
+		return startOf(s), len(s), largeBitSet(nil)
+	case sparseBitSet:
+		return startOf(s), len(s), sparseBitSet(nil)
+	case runBitSet:
+		return startOf(s), len(s), runBitSet(nil)
+	case retainedBitSet:
+		p, n := startOf(s.words), len(s.words)
+		s.words = nil
+		return p, n, s
+	case chunkedBitSet:
+		p, n := startOf(s.chunks), len(s.chunks)
+		s.chunks = nil
+		return p, n, s
+	case trieBitSet:
+		p := (*byte)(unsafe.Pointer(s.root))
+		s.root = nil
+		return p, 0, s
+	}
+	return nil, 0, s
+}
+
+// joinMemory returns the set split by splitMemory into shell, and its memory p of length n.
+func joinMemory(shell Set, p *byte, n int) Set {
+	switch s := shell.(type) {
+	case largeBitSet:
+		return largeBitSet(unsafe.Slice((*uint64)(unsafe.Pointer(p)), n))
+	case sparseBitSet:
+		return sparseBitSet(unsafe.Slice((*uint32)(unsafe.Pointer(p)), n))
+	case runBitSet:
+		return runBitSet(unsafe.Slice((*run[uint32])(unsafe.Pointer(p)), n))
+	case retainedBitSet:
+		s.words = unsafe.Slice((*uint64)(unsafe.Pointer(p)), n)
+		return s
+	case chunkedBitSet:
+		s.chunks = unsafe.Slice((*chunk)(unsafe.Pointer(p)), n)
+		return s
+	case trieBitSet:
+		s.root = (*trieNode)(unsafe.Pointer(p))
+		return s
+	}
+	return shell
+}
+
+// startOf returns a pointer to the first element of s, or nil if s is empty.
+func startOf[T any](s []T) *byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return (*byte)(unsafe.Pointer(&s[0]))
+}
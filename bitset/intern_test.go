@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInterner(t *testing.T) {
+	var x Interner
+	a := FromIndices(1, 1000, 100000)
+	b := New().Set(100000).Set(1).Set(1000)
+
+	if _, ok := x.Lookup(a); ok {
+		t.Error("Lookup should not find sets that weren't interned")
+	}
+	if got := x.Intern(a); &got.(sparseBitSet)[0] != &a.(sparseBitSet)[0] {
+		t.Error("Expected the first set to be interned as is")
+	}
+	got := x.Intern(b)
+	if &got.(sparseBitSet)[0] != &a.(sparseBitSet)[0] {
+		t.Error("Expected an equal set to be interned as the first one")
+	}
+	if got, ok := x.Lookup(b); !ok || got.Key() != a.Key() {
+		t.Error("Expected Lookup to find the interned set")
+	}
+
+	x.Intern(a.Set(5))
+	if x.Len() != 2 {
+		t.Errorf("Expected 2 sets, got %d", x.Len())
+	}
+
+	// Views are materialized before they're stored
+	v := UnionView(a, FromIndices(5))
+	got = NewInterner().Intern(v)
+	if _, ok := got.(viewSet); ok {
+		t.Error("Expected a view to be materialized")
+	}
+	checkCanonical(t, got)
+
+	// Inline sets have no memory to share
+	if got := x.Intern(FromIndices(1, 100)); got != FromIndices(1, 100) || x.Len() != 2 {
+		t.Error("Expected an inline set to be returned as is, without adding it")
+	}
+
+	// Mapped sets are copied, as their memory may not be in the Go heap
+	words := make([]uint64, 8)
+	words[7] = 1
+	m, err := FromMappedBytes(mappedBytes(words))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = x.Intern(m)
+	if _, ok := got.(mappedBitSet); ok || !Equal(got, m) {
+		t.Errorf("Expected a mapped set to be interned as a copy, got %T", got)
+	}
+	if again := x.Intern(FromIndices(7 * 64)); again.Key() != got.Key() {
+		t.Error("Expected an equal set to be interned as the copy of the mapped set")
+	}
+}
+
+func TestInternerConcurrent(t *testing.T) {
+	x := NewInterner()
+	sets := testSets()
+	var wg sync.WaitGroup
+	results := make([][]Set, 8)
+	for g := range results {
+		wg.Go(func() {
+			for _, tc := range sets {
+				results[g] = append(results[g], x.Intern(tc.s))
+			}
+		})
+	}
+	wg.Wait()
+
+	for i := range sets {
+		for g := range results {
+			if results[g][i].Key() != results[0][i].Key() {
+				t.Fatalf("%s: goroutines interned different sets", sets[i].name)
+			}
+		}
+	}
+	want := 0
+	for _, tc := range sets {
+		if p, _, _ := splitMemory(tc.s); p != nil {
+			want++
+		}
+	}
+	if x.Len() != want {
+		t.Errorf("Expected %d sets, got %d", want, x.Len())
+	}
+	runtime.KeepAlive(results)
+}
+
+func TestInternerRemovesUnusedSets(t *testing.T) {
+	x := NewInterner()
+	kept := x.Intern(FromStride(0, 2, 10000))
+	func() {
+		for i := range 10 {
+			x.Intern(FromStride(uint32(i)+1, 3, 10000))
+		}
+	}()
+
+	for range 100 {
+		runtime.GC()
+		if x.Len() == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if x.Len() != 1 {
+		t.Fatalf("Expected the unused sets to be removed, got %d sets", x.Len())
+	}
+	if got, ok := x.Lookup(FromStride(0, 2, 10000)); !ok || got.Key() != kept.Key() {
+		t.Error("Expected the set in use to stay interned")
+	}
+	runtime.KeepAlive(kept)
+}
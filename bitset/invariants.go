@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// CheckInvariants returns an error describing the first internal invariant of s that does not hold, or nil if s is valid.
+//
+// It checks that s is in canonical form, i.e. stored in the representation its bits call for, and that the
+// representation itself is consistent, e.g. that sorted indices are sorted and cached counts are correct.
+// Sets returned by this package always pass, so this is meant for tests and debug builds, e.g. of code that
+// decodes sets from untrusted input. It takes time proportional to the size of s.
+func CheckInvariants(s Set) error {
+	switch s := s.(type) {
+	case nil:
+		return errors.New("bitset: set is nil")
+	case viewSet:
+		if err := CheckInvariants(s.a); err != nil {
+			return err
+		}
+		return CheckInvariants(s.b)
+	case retainedBitSet:
+		// Trailing zero words are allowed, and any number of words is a valid representation
+		return nil
+	case mappedBitSet:
+		if len(s.words) == 0 || s.words[len(s.words)-1] == 0 {
+			return errors.New("bitset: mapped set has trailing zero words")
+		}
+		return nil
+	case largeBitSet:
+		if len(s) > 0 && s[len(s)-1] == 0 {
+			return errors.New("bitset: dense set has trailing zero words")
+		}
+	case bitSet192:
+		if s[1] == 0 && s[2] == 0 {
+			return errors.New("bitset: medium set only has bits below 64")
+		}
+	case sparseBitSet:
+		for i := 1; i < len(s); i++ {
+			if s[i-1] >= s[i] {
+				return fmt.Errorf("bitset: sparse set is not strictly ascending at %d", i)
+			}
+		}
+	case runBitSet:
+		if err := checkRuns(s); err != nil {
+			return err
+		}
+	case chunkedBitSet:
+		if err := s.checkInvariants(); err != nil {
+			return err
+		}
+	case trieBitSet:
+		if err := s.checkInvariants(); err != nil {
+			return err
+		}
+	}
+
+	n, r, w := s.Count(), len(runsOf(s)), 0
+	if s, ok := s.(interface{ wordLen() int }); ok {
+		w = s.wordLen()
+	}
+	if want := canonicalKind(n, r, w); s.Kind() != want {
+		return fmt.Errorf("bitset: set with %d bits in %d runs over %d words is stored as %v, want %v", n, r, w, s.Kind(), want)
+	}
+	return nil
+}
+
+// canonicalKind returns the representation of a set with n bits set in r runs, spanning w words.
+// It must match the choices made by fromWords and fromIndices.
+func canonicalKind(n, r, w int) Kind {
+	switch {
+	case w <= 1:
+		return KindSmall
+	case w <= 3:
+		return KindMedium
+	case preferRuns(n, r, w):
+		return KindRuns
+	case !preferSparse(n, w) && w >= trieMinWords:
+		return KindTrie
+	case !preferSparse(n, w):
+		return KindDense
+	case n > maxSparseLen:
+		return KindChunked
+	}
+	return KindSparse
+}
+
+// checkRuns returns an error if runs are not sorted, non-overlapping and non-adjacent.
+func checkRuns[T uint16 | uint32](runs []run[T]) error {
+	for i, r := range runs {
+		if r.start > r.last {
+			return fmt.Errorf("bitset: run %d ends before it starts", i)
+		}
+		if i > 0 && uint64(runs[i-1].last)+1 >= uint64(r.start) {
+			return fmt.Errorf("bitset: run %d overlaps or touches the previous one", i)
+		}
+	}
+	return nil
+}
+
+func (b chunkedBitSet) checkInvariants() error {
+	n, r := 0, len(b.toRuns())
+	for i, ch := range b.chunks {
+		if i > 0 && b.chunks[i-1].key >= ch.key {
+			return fmt.Errorf("bitset: chunk keys are not strictly ascending at %d", i)
+		}
+		switch c := ch.c.(type) {
+		case arrayContainer:
+			for j := 1; j < len(c); j++ {
+				if c[j-1] >= c[j] {
+					return fmt.Errorf("bitset: array container of chunk %d is not strictly ascending", ch.key)
+				}
+			}
+		case runContainer:
+			if err := checkRuns(c); err != nil {
+				return fmt.Errorf("bitset: chunk %d: %w", ch.key, err)
+			}
+		}
+		if opt := optimize(ch.c); opt == nil || reflect.TypeOf(opt) != reflect.TypeOf(ch.c) {
+			return fmt.Errorf("bitset: chunk %d is not stored in the smallest container", ch.key)
+		}
+		n += ch.c.card()
+	}
+
+	if b.n != n || b.r != r {
+		return fmt.Errorf("bitset: chunked set caches %d bits in %d runs, has %d bits in %d runs", b.n, b.r, n, r)
+	}
+	return nil
+}
+
+func (b trieBitSet) checkInvariants() error {
+	if b.root == nil || b.height < 1 {
+		return errors.New("bitset: trie has no root")
+	}
+	if b.height > 1 && trieCapacity(b.height-1) >= b.w {
+		return fmt.Errorf("bitset: trie of %d words has more than %d levels", b.w, b.height)
+	}
+	if err := checkTrieNode(b.root, b.height); err != nil {
+		return err
+	}
+
+	n := 0
+	b.forEachLeaf(func(_ int, leaf *trieLeaf) {
+		n += popCount(leaf[:])
+	})
+	if r, w := len(b.toRuns()), b.highestWord()+1; b.n != n || b.r != r || b.w != w {
+		return fmt.Errorf("bitset: trie caches %d bits in %d runs over %d words, has %d bits in %d runs over %d words",
+			b.n, b.r, b.w, n, r, w)
+	}
+	return nil
+}
+
+// checkTrieNode returns an error if the subtrie rooted at child, which is at the given height, has empty nodes or leaves.
+func checkTrieNode(child any, height int) error {
+	if height == 0 {
+		if leaf := child.(*trieLeaf); !slices.ContainsFunc(leaf[:], func(w uint64) bool { return w != 0 }) {
+			return errors.New("bitset: trie has an empty leaf")
+		}
+		return nil
+	}
+
+	empty := true
+	for _, c := range child.(*trieNode) {
+		if c == nil {
+			continue
+		}
+		empty = false
+		if err := checkTrieNode(c, height-1); err != nil {
+			return err
+		}
+	}
+	if empty {
+		return errors.New("bitset: trie has an empty node")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "testing"
+
+func TestCheckInvariants(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		if err := CheckInvariants(a.s); err != nil {
+			t.Errorf("%s: %v", a.name, err)
+		}
+		for _, b := range sets {
+			for _, s := range []Set{a.s.Union(b.s), a.s.Intersect(b.s), a.s.SymmetricDifference(b.s), UnionView(a.s, b.s)} {
+				if err := CheckInvariants(s); err != nil {
+					t.Errorf("%s, %s: %v", a.name, b.name, err)
+				}
+			}
+		}
+	}
+	if err := CheckInvariants(NewWithShrinkPolicy(ShrinkNever).Set(1000).Clear(1000)); err != nil {
+		t.Errorf("Retained set: %v", err)
+	}
+}
+
+func TestCheckInvariantsInvalid(t *testing.T) {
+	trie := everyThird(1100 * 64).(trieBitSet)
+	trie.n++
+	chunked := testSets()[6].s.(chunkedBitSet)
+	chunked.r--
+
+	tests := []struct {
+		name string
+		s    Set
+	}{
+		{"nil", nil},
+		{"unsorted sparse", sparseBitSet{1_000_000, 3}},
+		{"small sparse", sparseBitSet{1, 2}},
+		{"trailing zero words", largeBitSet{1, 0, 0, 1, 0}},
+		{"medium with only low bits", bitSet192{1, 0, 0}},
+		{"adjacent runs", runBitSet{{0, 1_000_000}, {1_000_001, 2_000_000}}},
+		{"wrong trie count", trie},
+		{"wrong chunked runs", chunked},
+		{"invalid view operand", UnionView(New(), sparseBitSet{5, 5})},
+	}
+	for _, tt := range tests {
+		if err := CheckInvariants(tt.s); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
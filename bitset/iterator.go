@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// bitset.Iterator iterates over the set bits of a Set in ascending order, and can skip ahead to any bit index,
+// e.g. to merge-join several sets without visiting every bit of each one.
+//
+// An Iterator is not safe for concurrent use.
+type Iterator struct {
+	s    Set
+	next uint32 // the next set bit, if ok
+	ok   bool
+}
+
+// NewIterator creates and returns a new bitset.Iterator positioned at the lowest set bit of s.
+func NewIterator(s Set) *Iterator {
+	it := &Iterator{s: materialized(s)}
+	it.next, it.ok = nextSetBit(it.s, 0)
+	return it
+}
+
+// HasNext reports whether there are more set bits.
+func (it *Iterator) HasNext() bool {
+	return it.ok
+}
+
+// PeekNext returns the next set bit without advancing past it. It must only be called if HasNext returns true.
+func (it *Iterator) PeekNext() uint32 {
+	return it.next
+}
+
+// Next returns the next set bit and advances past it. It must only be called if HasNext returns true.
+func (it *Iterator) Next() uint32 {
+	i := it.next
+	if i == math.MaxUint32 {
+		it.ok = false
+	} else {
+		it.next, it.ok = nextSetBit(it.s, i+1)
+	}
+	return i
+}
+
+// AdvanceIfNeeded skips ahead to the first set bit that is >= minIndex, if the next set bit is lower than that.
+func (it *Iterator) AdvanceIfNeeded(minIndex uint32) {
+	if it.ok && it.next < minIndex {
+		it.next, it.ok = nextSetBit(it.s, minIndex)
+	}
+}
+
+// nextSetBit returns the lowest set bit in s that is >= from, or false if there is none.
+func nextSetBit(s Set, from uint32) (uint32, bool) {
+	switch s := s.(type) {
+	case sparseBitSet:
+		i, _ := slices.BinarySearch(s, from)
+		if i == len(s) {
+			return 0, false
+		}
+		return s[i], true
+	case runBitSet:
+		i, found := searchRuns(s, from)
+		switch {
+		case found:
+			return from, true
+		case i < len(s):
+			return s[i].start, true
+		}
+		return 0, false
+	case chunkedBitSet:
+		i, found := s.find(uint16(from >> 16))
+		if found {
+			if lo, ok := s.chunks[i].c.next(uint16(from)); ok {
+				return uint32(s.chunks[i].key)<<16 | uint32(lo), true
+			}
+			i++
+		}
+		if i == len(s.chunks) {
+			return 0, false
+		}
+		lo, _ := s.chunks[i].c.next(0)
+		return uint32(s.chunks[i].key)<<16 | uint32(lo), true
+	case trieBitSet:
+		if int(from/64) >= s.w {
+			return 0, false
+		}
+		return nextInTrie(s.root, s.height, 0, from)
+	}
+
+	words, _ := denseWords(s)
+	return nextInWords(words, from)
+}
+
+// nextClearBit returns the lowest clear bit in s that is >= from, or false if every bit from there up is set.
+// It skips runs of set bits a word or a run at a time.
+func nextClearBit(s Set, from uint32) (uint32, bool) {
+	switch s := materialized(s).(type) {
+	case sparseBitSet:
+		i, found := slices.BinarySearch(s, from)
+		if !found {
+			return from, true
+		}
+		for i+1 < len(s) && s[i+1] == s[i]+1 {
+			i++
+		}
+		return s[i] + 1, s[i] != math.MaxUint32
+	case runBitSet:
+		i, found := searchRuns(s, from)
+		if !found {
+			return from, true
+		}
+		return s[i].last + 1, s[i].last != math.MaxUint32
+	case chunkedBitSet:
+		i, found := s.find(uint16(from >> 16))
+		var words [chunkWords]uint64
+		for ; found; i++ {
+			key := s.chunks[i].key
+			clear(words[:])
+			s.chunks[i].c.fill(words[:])
+			if lo, ok := nextClearInWords(words[:], from&(1<<16-1)); ok {
+				return uint32(key)<<16 | lo, true
+			}
+			// The chunk is full from from up, so the next clear bit is in the next chunk, if it is stored
+			if key == math.MaxUint16 {
+				return 0, false
+			}
+			from = uint32(key+1) << 16
+			found = i+1 < len(s.chunks) && s.chunks[i+1].key == key+1
+		}
+		return from, true
+	case trieBitSet:
+		end := uint64(s.w) * 64
+		if uint64(from) >= end {
+			return from, true
+		}
+		for wordIdx := int(from / 64); wordIdx < s.w; wordIdx++ {
+			w := ^s.word(wordIdx)
+			if wordIdx == int(from/64) {
+				w &= ^uint64(0) << (from % 64)
+			}
+			if w != 0 {
+				return uint32(wordIdx*64 + bits.TrailingZeros64(w)), true
+			}
+		}
+		return uint32(end), end < 1<<32
+	}
+
+	words, _ := denseWords(s)
+	end := uint64(len(words)) * 64
+	if uint64(from) >= end {
+		return from, true
+	}
+	if i, ok := nextClearInWords(words, from); ok {
+		return i, true
+	}
+	return uint32(end), end < 1<<32
+}
+
+// nextClearInWords returns the lowest clear bit in words that is >= from, or false if there is none within words.
+func nextClearInWords(words []uint64, from uint32) (uint32, bool) {
+	for i := int(from / 64); i < len(words); i++ {
+		w := ^words[i]
+		if i == int(from/64) {
+			w &= ^uint64(0) << (from % 64)
+		}
+		if w != 0 {
+			return uint32(i*64 + bits.TrailingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+// nextInWords returns the lowest set bit in words that is >= from, or false if there is none.
+func nextInWords(words []uint64, from uint32) (uint32, bool) {
+	i := int(from / 64)
+	if i >= len(words) {
+		return 0, false
+	}
+
+	w := words[i] & (^uint64(0) << (from % 64))
+	for w == 0 {
+		if i++; i == len(words) {
+			return 0, false
+		}
+		w = words[i]
+	}
+	return uint32(i*64 + bits.TrailingZeros64(w)), true
+}
+
+// nextInTrie returns the lowest set bit that is >= from in the subtrie rooted at child, or false if there is none.
+// child is at the given height, and its first word has index wordIdx.
+func nextInTrie(child any, height, wordIdx int, from uint32) (uint32, bool) {
+	if height == 0 {
+		start := uint32(wordIdx * 64)
+		i, ok := nextInWords(child.(*trieLeaf)[:], max(from, start)-start)
+		return start + i, ok
+	}
+
+	capacity := trieCapacity(height - 1)
+	first := max(int(from/64)-wordIdx, 0) / capacity
+	for i, c := range child.(*trieNode)[first:] {
+		if c == nil {
+			continue
+		}
+		if next, ok := nextInTrie(c, height-1, wordIdx+(first+i)*capacity, from); ok {
+			return next, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	it := NewIterator(FromIndices(1, 5, 100, 1000))
+	var got []uint32
+	for it.HasNext() {
+		got = append(got, it.Next())
+	}
+	if !slices.Equal(got, []uint32{1, 5, 100, 1000}) {
+		t.Errorf("Unexpected bits %v", got)
+	}
+
+	it = NewIterator(FromIndices(1, 5, 100, 1000))
+	it.AdvanceIfNeeded(6)
+	if !it.HasNext() || it.PeekNext() != 100 {
+		t.Errorf("Expected to advance to 100, got %d", it.PeekNext())
+	}
+	it.AdvanceIfNeeded(50) // already past it
+	if it.Next() != 100 {
+		t.Error("AdvanceIfNeeded should not move backwards")
+	}
+	it.AdvanceIfNeeded(1001)
+	if it.HasNext() {
+		t.Error("Expected no more bits")
+	}
+
+	if NewIterator(New()).HasNext() {
+		t.Error("Empty set should have no bits")
+	}
+
+	it = NewIterator(FromIndices(math.MaxUint32))
+	if it.Next() != math.MaxUint32 || it.HasNext() {
+		t.Error("Iterating should stop after the last bit index")
+	}
+}
+
+func TestIteratorAllSets(t *testing.T) {
+	sets := testSets()
+	sets = append(sets, testSet{name: "retained", s: WithShrinkPolicy(FromIndices(3, 70, 500), ShrinkNever)})
+
+	for _, ts := range sets {
+		indices := slices.Collect(ts.s.Indices())
+
+		it := NewIterator(ts.s)
+		var got []uint32
+		for it.HasNext() {
+			got = append(got, it.Next())
+		}
+		if !slices.Equal(got, indices) {
+			t.Errorf("%s: iterator returned different bits than Indices", ts.name)
+		}
+
+		// Seek to every 97th bit index up to past the highest bit
+		limit := uint32(100)
+		if len(indices) > 0 {
+			limit += indices[len(indices)-1]
+		}
+		for from := uint32(0); from < limit; from += 97 {
+			it := NewIterator(ts.s)
+			it.AdvanceIfNeeded(from)
+			k, _ := slices.BinarySearch(indices, from)
+			if k == len(indices) {
+				if it.HasNext() {
+					t.Fatalf("%s: AdvanceIfNeeded(%d) should reach the end", ts.name, from)
+				}
+				continue
+			}
+			if !it.HasNext() || it.PeekNext() != indices[k] {
+				t.Fatalf("%s: AdvanceIfNeeded(%d) = %d, expected %d", ts.name, from, it.PeekNext(), indices[k])
+			}
+		}
+	}
+}
+
+func TestNextClearBit(t *testing.T) {
+	sets := testSets()
+	for _, s := range []Set{
+		Full(1 << 17).Union(FromIndices(1<<17 + 5)),
+		chunkedFromIndices(slices.Collect(FromStride(0, 1, 70_000).Union(FromStride(131_072, 3, 200_000)).Indices())),
+		NewRangeSet(Range{0, math.MaxUint32}).Bits(),
+		FromIndices(math.MaxUint32-1, math.MaxUint32),
+	} {
+		sets = append(sets, testSet{name: FormatRangeList(s), s: s})
+	}
+
+	for _, ts := range sets {
+		// Check the bits around the boundaries between set and clear bits
+		runs := runsOf(ts.s)
+		var froms []uint32
+		for _, r := range runs[:min(len(runs), 200)] {
+			for _, i := range []uint32{r.start - 1, r.start, r.start + 1, r.last - 1, r.last, r.last + 1} {
+				froms = append(froms, i)
+			}
+		}
+		for _, from := range append(froms, 0, 1<<20) {
+			// The next clear bit is after the end of the run that from is in, if any
+			want, wantOK := from, true
+			if k, found := searchRuns(runs, from); found {
+				want, wantOK = runs[k].last+1, runs[k].last != math.MaxUint32
+			}
+			got, ok := nextClearBit(ts.s, from)
+			if ok != wantOK || (ok && got != want) {
+				t.Errorf("%s: nextClearBit(%d) = %d, %v, expected %d, %v", ts.name, from, got, ok, want, wantOK)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "encoding/binary"
+
+// ToJavaLongs returns the bits of s in the layout of java.util.BitSet.toLongArray:
+// bit i is bit i%64 of element i/64, and the result ends at the element with the highest set bit.
+func ToJavaLongs(s Set) []int64 {
+	s = materialized(s)
+	hi, ok := Max(s)
+	if !ok {
+		return []int64{}
+	}
+
+	longs := make([]int64, hi/64+1)
+	if words, ok := denseWords(s); ok {
+		for i := range longs {
+			longs[i] = int64(words[i])
+		}
+		return longs
+	}
+	for _, iw := range nonzeroWords(s) {
+		longs[iw.i] = int64(iw.w)
+	}
+	return longs
+}
+
+// FromJavaLongs returns a bitset.Set with the bits of longs in the layout described by ToJavaLongs,
+// the same as java.util.BitSet.valueOf(long[]).
+func FromJavaLongs(longs []int64) Set {
+	words := make([]uint64, len(longs))
+	for i, l := range longs {
+		words[i] = uint64(l)
+	}
+	return fromWords(words)
+}
+
+// ToJavaBytes returns the bits of s in the layout of java.util.BitSet.toByteArray:
+// bit i is bit i%8 of byte i/8, and the result ends at the byte with the highest set bit.
+func ToJavaBytes(s Set) []byte {
+	s = materialized(s)
+	hi, ok := Max(s)
+	if !ok {
+		return []byte{}
+	}
+
+	data := make([]byte, hi/8+1)
+	putWord := func(i int, w uint64) {
+		if i*8+8 <= len(data) {
+			binary.LittleEndian.PutUint64(data[i*8:], w)
+			return
+		}
+		for k := i * 8; k < len(data); k++ {
+			data[k] = byte(w >> (8 * (k % 8)))
+		}
+	}
+	if words, ok := denseWords(s); ok {
+		for i, w := range words {
+			putWord(i, w)
+		}
+		return data
+	}
+	for _, iw := range nonzeroWords(s) {
+		putWord(iw.i, iw.w)
+	}
+	return data
+}
+
+// FromJavaBytes returns a bitset.Set with the bits of data in the layout described by ToJavaBytes,
+// the same as java.util.BitSet.valueOf(byte[]).
+func FromJavaBytes(data []byte) Set {
+	words := make([]uint64, (len(data)+7)/8)
+	for i, b := range data {
+		words[i/8] |= uint64(b) << (8 * (i % 8))
+	}
+	return fromWords(words)
+}
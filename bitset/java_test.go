@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestJavaLongs(t *testing.T) {
+	// new BitSet() with bits 1, 63 and 64 set
+	s := FromIndices(1, 63, 64)
+	if got, want := ToJavaLongs(s), []int64{-9223372036854775806, 1}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := ToJavaLongs(New()); len(got) != 0 {
+		t.Errorf("Expected no longs for an empty set, got %v", got)
+	}
+
+	s = FromJavaLongs([]int64{-1, 0, 0})
+	if s.Count() != 64 || !s.Test(63) || s.Test(64) {
+		t.Errorf("Set from longs has incorrect bits")
+	}
+
+	for _, ts := range testSets() {
+		if got := FromJavaLongs(ToJavaLongs(ts.s)); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip", ts.name)
+		}
+	}
+}
+
+func TestJavaBytes(t *testing.T) {
+	s := FromIndices(0, 9, 15)
+	if got, want := ToJavaBytes(s), []byte{0x01, 0x82}; !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+	if got := ToJavaBytes(New()); len(got) != 0 {
+		t.Errorf("Expected no bytes for an empty set, got % x", got)
+	}
+
+	s = FromJavaBytes([]byte{0x80, 0, 0})
+	if s.Count() != 1 || !s.Test(7) {
+		t.Errorf("Set from bytes has incorrect bits")
+	}
+
+	for _, ts := range testSets() {
+		if got := FromJavaBytes(ToJavaBytes(ts.s)); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip", ts.name)
+		}
+	}
+}
+
+func TestJavaWide(t *testing.T) {
+	s := FromIndices(3, 1<<20+9)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	longs := ToJavaLongs(s)
+	data := ToJavaBytes(s)
+	runtime.ReadMemStats(&after)
+
+	// Only the results are allocated, not the words of the set as well
+	if alloc, size := after.TotalAlloc-before.TotalAlloc, uint64(8*len(longs)+len(data)); alloc > size+size/4 {
+		t.Errorf("Expected about %d bytes to be allocated, got %d", size, alloc)
+	}
+	if len(longs) != 1<<14+1 || longs[0] != 8 || longs[1<<14] != 1<<9 {
+		t.Error("Incorrect longs for a wide set")
+	}
+	if len(data) != 1<<17+2 || data[0] != 8 || data[1<<17+1] != 2 {
+		t.Error("Incorrect bytes for a wide set")
+	}
+
+	for _, ts := range testSets() {
+		if got := FromJavaLongs(ToJavaLongs(ts.s)); !Equal(got, ts.s) {
+			t.Errorf("%s: does not round trip through longs", ts.name)
+		}
+		if got := FromJavaBytes(ToJavaBytes(ts.s)); !Equal(got, ts.s) {
+			t.Errorf("%s: does not round trip through bytes", ts.name)
+		}
+	}
+}
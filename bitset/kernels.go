@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// wordKernel sets dst[i] to the result of a wordOp applied to x[i] and y[i], for every i < len(dst).
+// x and y must be at least as long as dst.
+type wordKernel func(dst, x, y []uint64)
+
+// The kernels used for the bulk word loops. Platforms with SIMD implementations replace them in init,
+// unless built with the purego tag.
+var (
+	andWords      wordKernel = andWordsGeneric
+	orWords       wordKernel = orWordsGeneric
+	xorWords      wordKernel = xorWordsGeneric
+	andNotWords   wordKernel = andNotWordsGeneric
+	popCountWords            = popCountGeneric
+	pextWord                 = pextGeneric
+	pdepWord                 = pdepGeneric
+)
+
+// kernelFor returns the kernel for op, identified by its truth table, or nil if there is none.
+func kernelFor(op wordOp) wordKernel {
+	switch op(0b1100, 0b1010) {
+	case 0b1000:
+		return andWords
+	case 0b1110:
+		return orWords
+	case 0b0110:
+		return xorWords
+	case 0b0100:
+		return andNotWords
+	}
+	return nil
+}
+
+func andWordsGeneric(dst, x, y []uint64) {
+	x, y = x[:len(dst)], y[:len(dst)]
+	for i := range dst {
+		dst[i] = x[i] & y[i]
+	}
+}
+
+func orWordsGeneric(dst, x, y []uint64) {
+	x, y = x[:len(dst)], y[:len(dst)]
+	for i := range dst {
+		dst[i] = x[i] | y[i]
+	}
+}
+
+func xorWordsGeneric(dst, x, y []uint64) {
+	x, y = x[:len(dst)], y[:len(dst)]
+	for i := range dst {
+		dst[i] = x[i] ^ y[i]
+	}
+}
+
+func andNotWordsGeneric(dst, x, y []uint64) {
+	x, y = x[:len(dst)], y[:len(dst)]
+	for i := range dst {
+		dst[i] = x[i] &^ y[i]
+	}
+}
+
+func popCountGeneric(words []uint64) int {
+	n := 0
+	for _, w := range words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// pextGeneric returns the bits of x at the positions of the set bits of mask, packed into the low bits,
+// like the PEXT instruction.
+func pextGeneric(x, mask uint64) uint64 {
+	var out uint64
+	for k := 0; mask != 0; k++ {
+		low := mask & -mask
+		if x&low != 0 {
+			out |= 1 << k
+		}
+		mask &^= low
+	}
+	return out
+}
+
+// pdepGeneric returns the low bits of x placed at the positions of the set bits of mask, like the PDEP instruction.
+func pdepGeneric(x, mask uint64) uint64 {
+	var out uint64
+	for k := 0; mask != 0; k++ {
+		low := mask & -mask
+		if x&(1<<k) != 0 {
+			out |= low
+		}
+		mask &^= low
+	}
+	return out
+}
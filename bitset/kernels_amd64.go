@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+//go:build !purego
+
+package bitset
+
+import "encoding/binary"
+
+// The AVX2 kernels process blocks of 4 words. Popcount is left to math/bits, which already uses POPCNT.
+
+func init() {
+	if hasFastBMI2() {
+		pextWord = pextBMI2
+		pdepWord = pdepBMI2
+	}
+
+	if !hasAVX2() {
+		return
+	}
+	andWords = avx2Kernel(andAVX2, andWordsGeneric)
+	orWords = avx2Kernel(orAVX2, orWordsGeneric)
+	xorWords = avx2Kernel(xorAVX2, xorWordsGeneric)
+	andNotWords = avx2Kernel(andNotAVX2, andNotWordsGeneric)
+}
+
+// avx2Kernel returns a kernel that runs asm on whole blocks of 4 words, and generic on the rest.
+func avx2Kernel(asm func(dst, x, y *uint64, blocks int), generic wordKernel) wordKernel {
+	return func(dst, x, y []uint64) {
+		x, y = x[:len(dst)], y[:len(dst)]
+		n := len(dst) &^ 3
+		if n > 0 {
+			asm(&dst[0], &x[0], &y[0], n/4)
+		}
+		generic(dst[n:], x[n:], y[n:])
+	}
+}
+
+// hasAVX2 reports whether the CPU and OS support AVX2.
+func hasAVX2() bool {
+	if maxLeaf, _ := cpuVendor(); maxLeaf < 7 {
+		return false
+	}
+	_, _, ecx1, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx1&osxsave == 0 || ecx1&avx == 0 {
+		return false
+	}
+	if xcr0, _ := xgetbv(); xcr0&0b110 != 0b110 { // XMM and YMM state enabled by the OS
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<5) != 0
+}
+
+// hasFastBMI2 reports whether the CPU supports BMI2, for PEXT and PDEP, and runs them in hardware.
+func hasFastBMI2() bool {
+	maxLeaf, vendor := cpuVendor()
+	if maxLeaf < 7 {
+		return false
+	}
+	eax1, _, _, _ := cpuid(1, 0)
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<8) != 0 && !microcodedBMI2(vendor, cpuFamily(eax1))
+}
+
+// microcodedBMI2 reports whether CPUs of the given vendor and family implement PEXT and PDEP in microcode,
+// which takes up to hundreds of cycles depending on the mask, and is slower than the portable loops.
+// That is AMD before Zen 3, and the Hygon CPUs based on Zen 1.
+func microcodedBMI2(vendor string, family uint32) bool {
+	switch vendor {
+	case "AuthenticAMD":
+		return family < 0x19
+	case "HygonGenuine":
+		return true
+	}
+	return false
+}
+
+// cpuVendor returns the highest standard CPUID leaf the CPU supports, and its vendor string.
+func cpuVendor() (maxLeaf uint32, vendor string) {
+	maxLeaf, ebx, ecx, edx := cpuid(0, 0)
+	var b [12]byte
+	binary.LittleEndian.PutUint32(b[0:], ebx)
+	binary.LittleEndian.PutUint32(b[4:], edx)
+	binary.LittleEndian.PutUint32(b[8:], ecx)
+	return maxLeaf, string(b[:])
+}
+
+// cpuFamily returns the family of the CPU from the eax of CPUID leaf 1, adding the extended family if it is 0xF.
+func cpuFamily(eax1 uint32) uint32 {
+	family := eax1 >> 8 & 0xF
+	if family == 0xF {
+		family += eax1 >> 20 & 0xFF
+	}
+	return family
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+//go:noescape
+func andAVX2(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func orAVX2(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func xorAVX2(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func andNotAVX2(dst, x, y *uint64, blocks int)
+
+func pextBMI2(x, mask uint64) uint64
+
+func pdepBMI2(x, mask uint64) uint64
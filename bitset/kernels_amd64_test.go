@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+//go:build !purego
+
+package bitset
+
+import "testing"
+
+func TestMicrocodedBMI2(t *testing.T) {
+	tests := []struct {
+		name   string
+		vendor string
+		eax1   uint32 // CPUID leaf 1 eax
+		want   bool
+	}{
+		{"Haswell", "GenuineIntel", 0x000306C3, false},
+		{"Excavator", "AuthenticAMD", 0x00660F01, true},
+		{"Zen 1", "AuthenticAMD", 0x00800F11, true},
+		{"Zen 2", "AuthenticAMD", 0x00870F10, true},
+		{"Zen 3", "AuthenticAMD", 0x00A20F10, false},
+		{"Zen 4", "AuthenticAMD", 0x00A60F12, false},
+		{"Dhyana", "HygonGenuine", 0x00900F01, true},
+	}
+	for _, tt := range tests {
+		if got := microcodedBMI2(tt.vendor, cpuFamily(tt.eax1)); got != tt.want {
+			t.Errorf("%s: microcodedBMI2 = %v, expected %v", tt.name, got, tt.want)
+		}
+	}
+
+	if maxLeaf, vendor := cpuVendor(); maxLeaf == 0 || len(vendor) != 12 {
+		t.Errorf("Unexpected CPUID leaf 0: %d, %q", maxLeaf, vendor)
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+//go:build !purego
+
+package bitset
+
+// The NEON kernels process blocks of 4 words. NEON is always available on arm64.
+
+func init() {
+	andWords = neonKernel(andNEON, andWordsGeneric)
+	orWords = neonKernel(orNEON, orWordsGeneric)
+	xorWords = neonKernel(xorNEON, xorWordsGeneric)
+	andNotWords = neonKernel(andNotNEON, andNotWordsGeneric)
+	popCountWords = popCountNEONWords
+}
+
+// neonKernel returns a kernel that runs asm on whole blocks of 4 words, and generic on the rest.
+func neonKernel(asm func(dst, x, y *uint64, blocks int), generic wordKernel) wordKernel {
+	return func(dst, x, y []uint64) {
+		x, y = x[:len(dst)], y[:len(dst)]
+		n := len(dst) &^ 3
+		if n > 0 {
+			asm(&dst[0], &x[0], &y[0], n/4)
+		}
+		generic(dst[n:], x[n:], y[n:])
+	}
+}
+
+func popCountNEONWords(words []uint64) int {
+	n := len(words) &^ 7
+	count := 0
+	if n > 0 {
+		count = popCountNEON(&words[0], n/8)
+	}
+	return count + popCountGeneric(words[n:])
+}
+
+//go:noescape
+func andNEON(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func orNEON(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func xorNEON(dst, x, y *uint64, blocks int)
+
+//go:noescape
+func andNotNEON(dst, x, y *uint64, blocks int)
+
+// popCountNEON returns the number of set bits in blocks*8 words.
+//
+//go:noescape
+func popCountNEON(words *uint64, blocks int) int
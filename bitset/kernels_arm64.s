@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+//go:build !purego
+
+#include "textflag.h"
+
+// func andNEON(dst, x, y *uint64, blocks int)
+TEXT ·andNEON(SB), NOSPLIT, $0-32
+	MOVD dst+0(FP), R0
+	MOVD x+8(FP), R1
+	MOVD y+16(FP), R2
+	MOVD blocks+24(FP), R3
+
+loop:
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VAND   V2.B16, V0.B16, V0.B16
+	VAND   V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUBS   $1, R3, R3
+	BNE    loop
+	RET
+
+// func orNEON(dst, x, y *uint64, blocks int)
+TEXT ·orNEON(SB), NOSPLIT, $0-32
+	MOVD dst+0(FP), R0
+	MOVD x+8(FP), R1
+	MOVD y+16(FP), R2
+	MOVD blocks+24(FP), R3
+
+loop:
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VORR   V2.B16, V0.B16, V0.B16
+	VORR   V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUBS   $1, R3, R3
+	BNE    loop
+	RET
+
+// func xorNEON(dst, x, y *uint64, blocks int)
+TEXT ·xorNEON(SB), NOSPLIT, $0-32
+	MOVD dst+0(FP), R0
+	MOVD x+8(FP), R1
+	MOVD y+16(FP), R2
+	MOVD blocks+24(FP), R3
+
+loop:
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VEOR   V2.B16, V0.B16, V0.B16
+	VEOR   V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUBS   $1, R3, R3
+	BNE    loop
+	RET
+
+// func andNotNEON(dst, x, y *uint64, blocks int)
+TEXT ·andNotNEON(SB), NOSPLIT, $0-32
+	MOVD dst+0(FP), R0
+	MOVD x+8(FP), R1
+	MOVD y+16(FP), R2
+	MOVD blocks+24(FP), R3
+
+loop:
+	VLD1.P 32(R1), [V0.B16, V1.B16]
+	VLD1.P 32(R2), [V2.B16, V3.B16]
+	VBIC   V2.B16, V0.B16, V0.B16
+	VBIC   V3.B16, V1.B16, V1.B16
+	VST1.P [V0.B16, V1.B16], 32(R0)
+	SUBS   $1, R3, R3
+	BNE    loop
+	RET
+
+// func popCountNEON(words *uint64, blocks int) int
+TEXT ·popCountNEON(SB), NOSPLIT, $0-24
+	MOVD words+0(FP), R0
+	MOVD blocks+8(FP), R1
+	MOVD $0, R2
+
+loop:
+	VLD1.P  64(R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VCNT    V0.B16, V0.B16
+	VCNT    V1.B16, V1.B16
+	VCNT    V2.B16, V2.B16
+	VCNT    V3.B16, V3.B16
+	VADD    V1.B16, V0.B16, V0.B16
+	VADD    V3.B16, V2.B16, V2.B16
+	VADD    V2.B16, V0.B16, V0.B16 // at most 32 per byte
+	VUADDLV V0.B16, V4
+	VMOV    V4.H[0], R3
+	ADD     R3, R2, R2
+	SUBS    $1, R1, R1
+	BNE     loop
+	MOVD    R2, ret+16(FP)
+	RET
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestKernels(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	kernels := []struct {
+		name    string
+		op      wordOp
+		generic wordKernel
+	}{
+		{"and", and, andWordsGeneric},
+		{"or", or, orWordsGeneric},
+		{"xor", xor, xorWordsGeneric},
+		{"andNot", andNot, andNotWordsGeneric},
+	}
+
+	for n := range 40 {
+		x, y := make([]uint64, n+3), make([]uint64, n+3)
+		for i := range x {
+			x[i], y[i] = r.Uint64(), r.Uint64()
+		}
+
+		for _, k := range kernels {
+			want, got := make([]uint64, n), make([]uint64, n)
+			k.generic(want, x, y)
+			kernelFor(k.op)(got, x, y)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s kernel over %d words = %x, expected %x", k.name, n, got, want)
+			}
+			for i := range want {
+				if want[i] != k.op(x[i], y[i]) {
+					t.Fatalf("Generic %s kernel is incorrect", k.name)
+				}
+			}
+		}
+
+		if got, want := popCountWords(x[:n]), popCountGeneric(x[:n]); got != want {
+			t.Errorf("popCount over %d words = %d, expected %d", n, got, want)
+		}
+	}
+
+	if kernelFor(func(x, y uint64) uint64 { return x | ^y }) != nil {
+		t.Error("Expected no kernel for an unknown op")
+	}
+}
+
+func TestPextPdep(t *testing.T) {
+	if got := pextGeneric(0b1011_0110, 0b1111_0000); got != 0b1011 {
+		t.Errorf("pextGeneric = %b, expected 1011", got)
+	}
+	if got := pdepGeneric(0b1011, 0b1111_0000); got != 0b1011_0000 {
+		t.Errorf("pdepGeneric = %b, expected 10110000", got)
+	}
+
+	r := rand.New(rand.NewPCG(5, 6))
+	for range 1000 {
+		x, mask := r.Uint64(), r.Uint64()&r.Uint64()
+		if got, want := pextWord(x, mask), pextGeneric(x, mask); got != want {
+			t.Errorf("pext(%#x, %#x) = %#x, expected %#x", x, mask, got, want)
+		}
+		if got, want := pdepWord(x, mask), pdepGeneric(x, mask); got != want {
+			t.Errorf("pdep(%#x, %#x) = %#x, expected %#x", x, mask, got, want)
+		}
+		if got := pdepGeneric(pextGeneric(x, mask), mask); got != x&mask {
+			t.Errorf("pdep(pext(x, mask), mask) = %#x, expected x & mask = %#x", got, x&mask)
+		}
+	}
+}
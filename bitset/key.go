@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "encoding/binary"
+
+// bitset.Key is a comparable representation of the bits of a Set, returned by Set.Key.
+// Its contents are an implementation detail, and may change between versions of this package.
+type Key string
+
+// newKey returns a new key buffer for a set of the given kind, with room for size more bytes.
+// Since sets are canonical, the kind is part of the contents, and prefixing it keeps keys of different kinds distinct.
+func newKey(kind Kind, size int) []byte {
+	return append(make([]byte, 0, 1+size), byte(kind))
+}
+
+// appendWords appends the little-endian encoding of words to dst.
+func appendWords(dst []byte, words []uint64) []byte {
+	for _, w := range words {
+		dst = binary.LittleEndian.AppendUint64(dst, w)
+	}
+	return dst
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	sets := testSets()
+	for i, a := range sets {
+		for j, b := range sets {
+			if (a.s.Key() == b.s.Key()) != (i == j) {
+				t.Errorf("%s and %s: keys should be equal only for the same set", a.name, b.name)
+			}
+		}
+
+		// The same bits reached in a different way have the same key
+		again := a.s.Union(New().Set(12345)).Clear(12345)
+		if !a.s.Test(12345) && again.Key() != a.s.Key() {
+			t.Errorf("%s: key changed after setting and clearing a bit", a.name)
+		}
+	}
+
+	// Chunked sets that differ in a single bit have different keys
+	chunked := sets[6].s
+	if chunked.Key() == chunked.Set(1).Key() {
+		t.Error("Different chunked sets should have different keys")
+	}
+}
+
+func TestKeyNonCanonical(t *testing.T) {
+	words := make([]uint64, 1100)
+	for i := range words {
+		words[i] = 0x1111
+	}
+	canonical := fromWords(words)
+
+	retained := WithShrinkPolicy(canonical, ShrinkNever).Set(100_000).Clear(100_000)
+	if retained.Key() != canonical.Key() {
+		t.Error("Retained set with trailing zero words should have the same key as the canonical set")
+	}
+
+	mapped, err := FromMappedBytes(mappedBytes(words))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapped.Key() != canonical.Key() {
+		t.Error("Mapped set should have the same key as the canonical set")
+	}
+
+	dedup := map[Key]Set{}
+	for _, s := range []Set{canonical, retained, mapped, New().Set(1)} {
+		dedup[s.Key()] = s
+	}
+	if len(dedup) != 2 {
+		t.Errorf("Expected 2 distinct sets, got %d", len(dedup))
+	}
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "sync"
+
+// bitset.Indexer assigns dense bit indices to arbitrary keys, in the order they are first seen,
+// so that sets of keys can be stored as bit sets. See KeyedSet.
+//
+// Keys are never unassigned, so the indexer grows with every distinct key it sees.
+// An Indexer is safe for concurrent use.
+type Indexer[K comparable] struct {
+	mu      sync.RWMutex
+	indices map[K]uint32
+	keys    []K
+}
+
+// NewIndexer creates and returns a new empty bitset.Indexer.
+func NewIndexer[K comparable]() *Indexer[K] {
+	return &Indexer[K]{indices: make(map[K]uint32)}
+}
+
+// Index returns the bit index of the given key, assigning it the next free index if it doesn't have one yet.
+// It panics if all 2^32 bit indices have been assigned.
+func (x *Indexer[K]) Index(key K) uint32 {
+	if i, ok := x.Lookup(key); ok {
+		return i
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if i, ok := x.indices[key]; ok {
+		return i
+	}
+	if uint64(len(x.keys)) == 1<<32 {
+		panic("bitset: indexer is full")
+	}
+	i := uint32(len(x.keys))
+	x.indices[key] = i
+	x.keys = append(x.keys, key)
+	return i
+}
+
+// Lookup returns the bit index of the given key, or false if it hasn't been assigned one.
+func (x *Indexer[K]) Lookup(key K) (uint32, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	i, ok := x.indices[key]
+	return i, ok
+}
+
+// Key returns the key with the given bit index, or false if no key has been assigned that index.
+func (x *Indexer[K]) Key(bitIndex uint32) (K, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if int(bitIndex) >= len(x.keys) {
+		var zero K
+		return zero, false
+	}
+	return x.keys[bitIndex], true
+}
+
+// Len returns the number of keys that have been assigned a bit index.
+func (x *Indexer[K]) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.keys)
+}
+
+// New creates and returns a new bitset.KeyedSet using x, with the given keys.
+func (x *Indexer[K]) New(keys ...K) KeyedSet[K] {
+	b := NewBuilder(0)
+	for _, k := range keys {
+		b = b.With(x.Index(k))
+	}
+	return KeyedSet[K]{b.Build(), x}
+}
+
+// bitset.KeyedSet is an immutable set of keys of type K, stored as a bit set using the indices from an Indexer.
+// Create KeyedSets with Indexer.New. Sets combined with the boolean operations must use the same Indexer.
+type KeyedSet[K comparable] struct {
+	s Set
+	x *Indexer[K]
+}
+
+// Indexer returns the bitset.Indexer used by k.
+func (k KeyedSet[K]) Indexer() *Indexer[K] {
+	return k.x
+}
+
+// Bits returns the bit indices of the keys in k as a bitset.Set.
+func (k KeyedSet[K]) Bits() Set {
+	return k.s
+}
+
+// Test reports whether the given key is in k.
+func (k KeyedSet[K]) Test(key K) bool {
+	i, ok := k.x.Lookup(key)
+	return ok && k.s.Test(i)
+}
+
+// Set returns a new bitset.KeyedSet with the given key added.
+// The original bitset.KeyedSet is not modified.
+func (k KeyedSet[K]) Set(key K) KeyedSet[K] {
+	k.s = k.s.Set(k.x.Index(key))
+	return k
+}
+
+// Clear returns a new bitset.KeyedSet with the given key removed.
+// The original bitset.KeyedSet is not modified.
+func (k KeyedSet[K]) Clear(key K) KeyedSet[K] {
+	if i, ok := k.x.Lookup(key); ok {
+		k.s = k.s.Clear(i)
+	}
+	return k
+}
+
+// Union returns a new bitset.KeyedSet with the keys that are in either k or other.
+// It panics if other uses a different Indexer. Neither set is modified.
+func (k KeyedSet[K]) Union(other KeyedSet[K]) KeyedSet[K] {
+	k.s = k.s.Union(k.same(other))
+	return k
+}
+
+// Intersect returns a new bitset.KeyedSet with the keys that are in both k and other.
+// It panics if other uses a different Indexer. Neither set is modified.
+func (k KeyedSet[K]) Intersect(other KeyedSet[K]) KeyedSet[K] {
+	k.s = k.s.Intersect(k.same(other))
+	return k
+}
+
+// Difference returns a new bitset.KeyedSet with the keys that are in k but not in other.
+// It panics if other uses a different Indexer. Neither set is modified.
+func (k KeyedSet[K]) Difference(other KeyedSet[K]) KeyedSet[K] {
+	k.s = k.s.Difference(k.same(other))
+	return k
+}
+
+// SymmetricDifference returns a new bitset.KeyedSet with the keys that are in exactly one of k and other.
+// It panics if other uses a different Indexer. Neither set is modified.
+func (k KeyedSet[K]) SymmetricDifference(other KeyedSet[K]) KeyedSet[K] {
+	k.s = k.s.SymmetricDifference(k.same(other))
+	return k
+}
+
+// Keys returns the keys in k, in the order they were first assigned an index.
+func (k KeyedSet[K]) Keys() []K {
+	indices := indicesOf(k.s)
+	keys := make([]K, len(indices))
+	for j, i := range indices {
+		keys[j], _ = k.x.Key(i)
+	}
+	return keys
+}
+
+// same returns the bits of other, and panics if it uses a different Indexer than k.
+func (k KeyedSet[K]) same(other KeyedSet[K]) Set {
+	if other.x != k.x {
+		panic("bitset: KeyedSets use different Indexers")
+	}
+	return other.s
+}
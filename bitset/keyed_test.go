@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestIndexer(t *testing.T) {
+	x := NewIndexer[string]()
+	if i := x.Index("a"); i != 0 {
+		t.Errorf("Expected index 0, got %d", i)
+	}
+	if i := x.Index("b"); i != 1 {
+		t.Errorf("Expected index 1, got %d", i)
+	}
+	if i := x.Index("a"); i != 0 {
+		t.Errorf("Expected the same index for the same key, got %d", i)
+	}
+
+	if _, ok := x.Lookup("c"); ok {
+		t.Error("Lookup should not assign indices")
+	}
+	if k, ok := x.Key(1); !ok || k != "b" {
+		t.Errorf("Expected key b, got %q", k)
+	}
+	if _, ok := x.Key(2); ok {
+		t.Error("Key should report unassigned indices")
+	}
+	if x.Len() != 2 {
+		t.Errorf("Expected 2 keys, got %d", x.Len())
+	}
+}
+
+func TestIndexerConcurrent(t *testing.T) {
+	x := NewIndexer[int]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for k := range 1000 {
+				x.Index(k)
+			}
+		})
+	}
+	wg.Wait()
+
+	if x.Len() != 1000 {
+		t.Fatalf("Expected 1000 keys, got %d", x.Len())
+	}
+	seen := map[uint32]bool{}
+	for k := range 1000 {
+		i, _ := x.Lookup(k)
+		if seen[i] {
+			t.Fatalf("Index %d was assigned twice", i)
+		}
+		seen[i] = true
+	}
+}
+
+func TestKeyedSet(t *testing.T) {
+	type id struct{ kind, n int }
+	x := NewIndexer[id]()
+
+	a := x.New(id{1, 1}, id{1, 2})
+	if !a.Test(id{1, 1}) || a.Test(id{2, 1}) {
+		t.Error("KeyedSet has incorrect keys")
+	}
+	if x.Len() != 2 {
+		t.Error("Test should not assign indices")
+	}
+
+	// Test immutability
+	b := a.Set(id{2, 1}).Clear(id{1, 1}).Clear(id{9, 9})
+	if !a.Test(id{1, 1}) || a.Test(id{2, 1}) {
+		t.Error("Original KeyedSet should not be modified")
+	}
+	if got := b.Keys(); !slices.Equal(got, []id{{1, 2}, {2, 1}}) {
+		t.Errorf("Unexpected keys %v", got)
+	}
+
+	if got := a.Union(b).Keys(); len(got) != 3 {
+		t.Errorf("Union: got %v", got)
+	}
+	if got := a.Intersect(b).Keys(); !slices.Equal(got, []id{{1, 2}}) {
+		t.Errorf("Intersect: got %v", got)
+	}
+	if got := a.Difference(b).Keys(); !slices.Equal(got, []id{{1, 1}}) {
+		t.Errorf("Difference: got %v", got)
+	}
+	if got := a.SymmetricDifference(b).Keys(); !slices.Equal(got, []id{{1, 1}, {2, 1}}) {
+		t.Errorf("SymmetricDifference: got %v", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Combining sets with different Indexers should panic")
+		} else if s := fmt.Sprint(r); s != "bitset: KeyedSets use different Indexers" {
+			t.Errorf("Unexpected panic %s", s)
+		}
+	}()
+	a.Union(NewIndexer[id]().New())
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "strconv"
+
+// bitset.Kind identifies the internal representation of a Set.
+// The representation is chosen automatically, and can change with every operation.
+type Kind uint8
+
+const (
+	KindSmall    Kind = iota // a single uint64, for bits below 64
+	KindMedium               // three inline uint64s, for bits below 192
+	KindDense                // a slice of uint64s
+	KindRetained             // a slice of uint64s that may have trailing zero words, see ShrinkPolicy
+	KindSparse               // a sorted slice of the set bit indices
+	KindChunked              // Roaring-style 2^16 bit chunks
+	KindRuns                 // a sorted slice of runs of consecutive set bits
+	KindTrie                 // a persistent trie of words
+	KindMapped               // read-only words in memory not owned by the set, see FromMappedBytes
+	KindView                 // a lazy union or intersection of two sets, see UnionView
+)
+
+var kindNames = [...]string{"Small", "Medium", "Dense", "Retained", "Sparse", "Chunked", "Runs", "Trie", "Mapped", "View"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// bitset.Info describes the internals of a Set, for debugging and performance tests.
+type Info struct {
+	Kind Kind
+
+	// Words is the number of 64-bit words the bits of the set span, from bit 0 up to its highest set bit.
+	// For KindRetained it is the number of words stored, which includes trailing zero words.
+	Words int
+
+	// SizeInBytes is the approximate number of bytes of heap memory used by the set.
+	SizeInBytes int
+}
+
+// Inspect returns a description of the internals of s.
+func Inspect(s Set) Info {
+	info := Info{Kind: s.Kind(), SizeInBytes: s.SizeInBytes()}
+	if s, ok := s.(interface{ wordLen() int }); ok {
+		info.Words = s.wordLen()
+	}
+	return info
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	want := []Kind{KindSmall, KindSmall, KindMedium, KindDense, KindSparse, KindTrie, KindChunked, KindRuns}
+	for i, ts := range testSets() {
+		if got := ts.s.Kind(); got != want[i] {
+			t.Errorf("%s: expected %v, got %v", ts.name, want[i], got)
+		}
+	}
+
+	if k := NewWithShrinkPolicy(ShrinkNever).Kind(); k != KindRetained {
+		t.Errorf("Expected %v, got %v", KindRetained, k)
+	}
+	if s := KindChunked.String(); s != "Chunked" {
+		t.Errorf("Expected Chunked, got %s", s)
+	}
+	if s := Kind(200).String(); s != "Kind(200)" {
+		t.Errorf("Expected Kind(200), got %s", s)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	wantWords := []int{0, 1, 3, 300/64 + 1, 2_000_000/64 + 1, 70_000/64 + 1, 4_999_000/64 + 1, 3_000_100/64 + 1}
+	for i, ts := range testSets() {
+		info := Inspect(ts.s)
+		if info.Kind != ts.s.Kind() || info.SizeInBytes != ts.s.SizeInBytes() {
+			t.Errorf("%s: Inspect is inconsistent with the set: %+v", ts.name, info)
+		}
+		if info.Words != wantWords[i] {
+			t.Errorf("%s: expected %d words, got %d", ts.name, wantWords[i], info.Words)
+		}
+	}
+
+	retained := NewWithShrinkPolicy(ShrinkNever).Set(1000).Clear(1000)
+	if info := Inspect(retained); info.Words != 1000/64+1 {
+		t.Errorf("Retained set should report its trailing zero words, got %+v", info)
+	}
+}
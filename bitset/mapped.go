@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"iter"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	errMisaligned = errors.New("bitset: mapped data must be 8 byte aligned, and its length a multiple of 8")
+	errBigEndian  = errors.New("bitset: mapped data is only supported on little-endian platforms")
+)
+
+// FromMappedBytes returns a read-only bitset.Set backed directly by data, without copying it,
+// where bit i is bit i%8 of data[i/8], i.e. the bits are stored as little-endian uint64 words.
+//
+// This is meant for huge precomputed sets in memory that is not owned by the Go heap, e.g. an mmap'd file.
+// data is never written to: Set and Clear copy the bits into a new set the first time they change it,
+// and every other operation only reads data.
+//
+// data must be 8 byte aligned, and its length a multiple of 8. The caller must not modify or unmap data
+// while the returned set, or any set derived from it, is in use.
+func FromMappedBytes(data []byte) (Set, error) {
+	if len(data)%8 != 0 || (len(data) > 0 && uintptr(unsafe.Pointer(&data[0]))%8 != 0) {
+		return nil, errMisaligned
+	}
+	if !littleEndian() {
+		return nil, errBigEndian
+	}
+	if len(data) == 0 {
+		return bitSet64(0), nil
+	}
+
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(&data[0])), len(data)/8)
+	lastIdx := len(words) - 1
+	for lastIdx >= 0 && words[lastIdx] == 0 {
+		lastIdx--
+	}
+	if lastIdx < 0 {
+		return bitSet64(0), nil
+	}
+	return mappedBitSet{words: words[:(lastIdx + 1)]}, nil
+}
+
+// bitset.External is a read-only set over memory owned outside the Go heap, e.g. a shared memory segment or a cgo
+// buffer, together with the function that releases that memory. Create one with UnsafeFromExternal.
+//
+// Call Release once the set, and every set derived from it, is no longer used. If an External becomes unreachable
+// without being released, the garbage collector releases it, like an os.File that is never closed.
+// The set returned by Bits, and every set that shares its memory, keeps the External reachable,
+// so it is never released by the garbage collector while they can still read that memory.
+type External struct {
+	s        Set
+	release  func()
+	released atomic.Bool
+	cleanup  runtime.Cleanup
+}
+
+// UnsafeFromExternal returns a bitset.External with a read-only set backed directly by data, without copying it,
+// as described by FromMappedBytes, which calls release when it is released, e.g. to free a cgo buffer or detach
+// a shared memory segment. release may be nil if the memory doesn't need to be released.
+//
+// data must be 8 byte aligned, and its length a multiple of 8. The caller must not modify or free data
+// before release is called.
+func UnsafeFromExternal(data []byte, release func()) (*External, error) {
+	s, err := FromMappedBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &External{s: s, release: release}
+	if m, ok := s.(mappedBitSet); ok {
+		// The set, and every set sharing its words, keeps e reachable, so e is not released while they are in use
+		m.owner = e
+		e.s = m
+	}
+	if release != nil {
+		e.cleanup = runtime.AddCleanup(e, func(release func()) { release() }, release)
+	}
+	return e, nil
+}
+
+// Bits returns the set backed by the external memory. It, and sets derived from it, may share that memory,
+// so they must not be used after e is released. Use Detach for a copy that outlives e.
+// It panics if e has been released.
+func (e *External) Bits() Set {
+	if e.released.Load() {
+		panic("bitset: use of released external set")
+	}
+	return e.s
+}
+
+// Detach returns a copy of the set in the Go heap, which stays valid after e is released.
+// It panics if e has been released.
+func (e *External) Detach() Set {
+	return fromWords(AppendWords(nil, e.Bits()))
+}
+
+// Release releases the external memory backing e, calling the release function passed to UnsafeFromExternal.
+// Releasing e more than once has no effect.
+func (e *External) Release() {
+	if e.released.Swap(true) {
+		return
+	}
+	e.cleanup.Stop()
+	if e.release != nil {
+		e.release()
+	}
+}
+
+func littleEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
+
+// Mapped (read-only words in memory not owned by the set, e.g. an mmap'd file, or adopted by UnsafeFromWords)
+type mappedBitSet struct {
+	words []uint64  // never modified - always copied on modification. No trailing zero words
+	owner *External // the External that owns words, if any, which is kept reachable for as long as the set is
+}
+
+func (b mappedBitSet) Test(bitIndex uint32) bool {
+	idx := int(bitIndex / 64)
+	if idx >= len(b.words) {
+		return false
+	}
+
+	return b.words[idx]&(1<<(bitIndex%64)) != 0
+}
+
+func (b mappedBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
+	return fromWords(withBit(b.words, bitIndex))
+}
+
+func (b mappedBitSet) Clear(bitIndex uint32) Set {
+	if !b.Test(bitIndex) {
+		return b
+	}
+
+	newBits := make([]uint64, len(b.words))
+	copy(newBits, b.words)
+	newBits[bitIndex/64] &^= 1 << (bitIndex % 64)
+	recordCopy(len(newBits))
+	return fromWords(newBits)
+}
+
+func (b mappedBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b mappedBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b mappedBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b mappedBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b mappedBitSet) Count() int {
+	return popCount(b.words)
+}
+
+func (b mappedBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldWords(b.words, 0, yield)
+	}
+}
+
+// SizeInBytes only counts the slice header, as the words are not owned by the set.
+func (b mappedBitSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(b.words))
+}
+
+func (b mappedBitSet) Kind() Kind {
+	return KindMapped
+}
+
+// Key returns the key of the equivalent canonical set.
+func (b mappedBitSet) Key() Key {
+	return fromWords(b.words).Key()
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b mappedBitSet) wordLen() int {
+	return len(b.words)
+}
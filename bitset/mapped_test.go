@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// mappedBytes returns words as a byte slice sharing their memory.
+func mappedBytes(words []uint64) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(words)*8)
+}
+
+func TestFromMappedBytes(t *testing.T) {
+	words := make([]uint64, 2000)
+	for i := range 1500 {
+		words[i] = 0x5555_5555_5555_5555
+	}
+	data := mappedBytes(words)
+
+	bs, err := FromMappedBytes(data)
+	if err != nil {
+		t.Fatalf("FromMappedBytes failed: %v", err)
+	}
+	mbs, ok := bs.(mappedBitSet)
+	if !ok {
+		t.Fatalf("Expected mappedBitSet, but got %T", bs)
+	}
+	if len(mbs.words) != 1500 || &mbs.words[0] != &words[0] {
+		t.Error("Mapped set should use the data without copying, trimmed of trailing zero words")
+	}
+	if !bs.Test(0) || bs.Test(1) || !bs.Test(1500*64-2) || bs.Test(1500*64) {
+		t.Error("Mapped set has incorrect bits")
+	}
+	if bs.SizeInBytes() != int(unsafe.Sizeof(mbs.words)) || bs.Kind() != KindMapped {
+		t.Errorf("Unexpected mapped set info: %+v", Inspect(bs))
+	}
+
+	// Modifying copies, and never writes to the data
+	set := bs.Set(1)
+	if !set.Test(1) || words[0] != 0x5555_5555_5555_5555 {
+		t.Error("Set should copy the mapped data")
+	}
+	if _, ok := set.(trieBitSet); !ok {
+		t.Errorf("Expected the modified set to be canonical, but got %T", set)
+	}
+	cleared := bs.Clear(0)
+	if cleared.Test(0) || words[0] != 0x5555_5555_5555_5555 {
+		t.Error("Clear should copy the mapped data")
+	}
+
+	// Unchanged bits don't copy
+	if m, ok := bs.Set(0).(mappedBitSet); !ok || &m.words[0] != &words[0] {
+		t.Error("Setting a bit that is already set should return the mapped set")
+	}
+	if m, ok := bs.Clear(1).(mappedBitSet); !ok || &m.words[0] != &words[0] {
+		t.Error("Clearing a bit that is not set should return the mapped set")
+	}
+
+	u := bs.Union(New().Set(3))
+	if !u.Test(0) || !u.Test(3) || u.Test(5) {
+		t.Error("Union with mapped set has incorrect bits")
+	}
+}
+
+func TestFromMappedBytesErrors(t *testing.T) {
+	data := mappedBytes(make([]uint64, 4))
+	if _, err := FromMappedBytes(data[1:9]); err == nil {
+		t.Error("Expected an error for misaligned data")
+	}
+	if _, err := FromMappedBytes(data[:12]); err == nil {
+		t.Error("Expected an error for a length that is not a multiple of 8")
+	}
+
+	if bs, err := FromMappedBytes(data); err != nil || bs != bitSet64(0) {
+		t.Errorf("Expected empty bitSet64 for zeroed data, got %v, %v", bs, err)
+	}
+	if bs, err := FromMappedBytes(nil); err != nil || bs != bitSet64(0) {
+		t.Errorf("Expected empty bitSet64 for no data, got %v, %v", bs, err)
+	}
+}
+
+func TestExternal(t *testing.T) {
+	words := []uint64{0b1010, 0, 1}
+	released := 0
+	e, err := UnsafeFromExternal(mappedBytes(words), func() { released++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := e.Bits().(mappedBitSet); !ok || &m.words[0] != &words[0] {
+		t.Errorf("Expected the set to use the external memory, got %T", e.Bits())
+	}
+
+	detached := e.Detach()
+	e.Release()
+	e.Release()
+	if released != 1 {
+		t.Errorf("Expected release to be called once, got %d", released)
+	}
+
+	words[0] = 0 // the memory may be reused once released
+	if detached.Key() != FromIndices(1, 3, 128).Key() {
+		t.Error("Detached set should not share the external memory")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Bits to panic after Release")
+		}
+	}()
+	e.Bits()
+}
+
+func TestExternalCleanup(t *testing.T) {
+	done := make(chan struct{})
+	func() {
+		_, err := UnsafeFromExternal(mappedBytes([]uint64{1}), func() { close(done) })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for range 100 {
+		runtime.GC()
+		select {
+		case <-done:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Error("Expected an unreachable External to be released")
+}
+
+func TestExternalCleanupWhileInUse(t *testing.T) {
+	words := []uint64{0b1010, 0, 1}
+	var released atomic.Bool
+	bs := func() Set {
+		e, err := UnsafeFromExternal(mappedBytes(words), func() { released.Store(true) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e.Bits().Set(1) // shares the external memory, as bit 1 is already set
+	}()
+
+	for range 10 {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if released.Load() {
+		t.Fatal("Expected the External not to be released while a set using its memory is reachable")
+	}
+	if !bs.Test(3) || !bs.Test(128) || bs.Count() != 3 {
+		t.Error("Set using the external memory has incorrect bits")
+	}
+	runtime.KeepAlive(bs)
+}
@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"math"
+)
+
+// bitset.Matrix is an immutable rows × cols matrix of bits, e.g. an adjacency matrix or an occupancy grid.
+//
+// The bits are stored row by row in a single Set, so the bit at (row, col) is bit row*cols+col of Bits.
+// Methods that take a row or column panic if it is outside the matrix, like indexing a slice.
+// The zero value is a 0×0 matrix.
+type Matrix struct {
+	s          Set
+	rows, cols uint32
+}
+
+// NewMatrix creates and returns a new bitset.Matrix with the given dimensions and no bits set.
+// It panics if the matrix has more bits than fit in a uint32 bit index.
+func NewMatrix(rows, cols uint32) Matrix {
+	if uint64(rows)*uint64(cols) > math.MaxUint32+1 {
+		panic(fmt.Sprintf("bitset: %d×%d matrix is too large", rows, cols))
+	}
+	return Matrix{s: New(), rows: rows, cols: cols}
+}
+
+// Rows returns the number of rows in m.
+func (m Matrix) Rows() uint32 {
+	return m.rows
+}
+
+// Cols returns the number of columns in m.
+func (m Matrix) Cols() uint32 {
+	return m.cols
+}
+
+// Bits returns the bits of m as a bitset.Set, row by row.
+func (m Matrix) Bits() Set {
+	if m.s == nil {
+		return New()
+	}
+	return m.s
+}
+
+// Test reports whether the bit at the given row and column is set.
+func (m Matrix) Test(row, col uint32) bool {
+	return m.Bits().Test(m.index(row, col))
+}
+
+// Set returns a new bitset.Matrix with the bit at the given row and column set.
+// The original bitset.Matrix is not modified.
+func (m Matrix) Set(row, col uint32) Matrix {
+	m.s = m.Bits().Set(m.index(row, col))
+	return m
+}
+
+// Clear returns a new bitset.Matrix with the bit at the given row and column cleared.
+// The original bitset.Matrix is not modified.
+func (m Matrix) Clear(row, col uint32) Matrix {
+	m.s = m.Bits().Clear(m.index(row, col))
+	return m
+}
+
+// Row returns the bits of the given row as a bitset.Set, where bit i is the bit at column i.
+func (m Matrix) Row(row uint32) Set {
+	m.checkRow(row)
+	return bitRange(m.Bits(), uint64(row)*uint64(m.cols), m.cols)
+}
+
+// WithRow returns a new bitset.Matrix with the given row replaced by the bits of s, where bit i is the bit at column i.
+// Bits of s at or past Cols are ignored.
+// The original bitset.Matrix is not modified.
+func (m Matrix) WithRow(row uint32, s Set) Matrix {
+	m.checkRow(row)
+	start := row * m.cols
+	b := NewBuilder(0)
+	for i := range m.Bits().Indices() {
+		if i-start >= m.cols { // also true for i < start, as it wraps around
+			b = b.With(i)
+		}
+	}
+	for col := range s.Indices() {
+		if col >= m.cols {
+			break
+		}
+		b = b.With(start + col)
+	}
+	m.s = b.Build()
+	return m
+}
+
+// Col returns the bits of the given column as a bitset.Set, where bit i is the bit at row i.
+func (m Matrix) Col(col uint32) Set {
+	if col >= m.cols {
+		panic(fmt.Sprintf("bitset: column %d is out of range [0, %d)", col, m.cols))
+	}
+
+	b := NewBuilder(0)
+	for i := range m.Bits().Indices() {
+		if i%m.cols == col {
+			b = b.With(i / m.cols)
+		}
+	}
+	return b.Build()
+}
+
+// Transpose returns a new cols × rows bitset.Matrix with the bit at (row, col) of m at (col, row).
+// The original bitset.Matrix is not modified.
+func (m Matrix) Transpose() Matrix {
+	b := NewBuilder(0)
+	for i := range m.Bits().Indices() {
+		b = b.With(i%m.cols*m.rows + i/m.cols)
+	}
+	return Matrix{s: b.Build(), rows: m.cols, cols: m.rows}
+}
+
+// Union returns a new bitset.Matrix with the bits that are set in either m or other.
+// It panics if the matrices have different dimensions.
+// Neither matrix is modified.
+func (m Matrix) Union(other Matrix) Matrix {
+	m.checkSize(other)
+	m.s = m.Bits().Union(other.Bits())
+	return m
+}
+
+// Intersect returns a new bitset.Matrix with the bits that are set in both m and other.
+// It panics if the matrices have different dimensions.
+// Neither matrix is modified.
+func (m Matrix) Intersect(other Matrix) Matrix {
+	m.checkSize(other)
+	m.s = m.Bits().Intersect(other.Bits())
+	return m
+}
+
+// Difference returns a new bitset.Matrix with the bits that are set in m but not in other.
+// It panics if the matrices have different dimensions.
+// Neither matrix is modified.
+func (m Matrix) Difference(other Matrix) Matrix {
+	m.checkSize(other)
+	m.s = m.Bits().Difference(other.Bits())
+	return m
+}
+
+// SymmetricDifference returns a new bitset.Matrix with the bits that are set in exactly one of m and other.
+// It panics if the matrices have different dimensions.
+// Neither matrix is modified.
+func (m Matrix) SymmetricDifference(other Matrix) Matrix {
+	m.checkSize(other)
+	m.s = m.Bits().SymmetricDifference(other.Bits())
+	return m
+}
+
+// index returns the bit index of the given row and column, or panics if they are outside m.
+func (m Matrix) index(row, col uint32) uint32 {
+	if row >= m.rows || col >= m.cols {
+		panic(fmt.Sprintf("bitset: (%d, %d) is out of range for a %d×%d matrix", row, col, m.rows, m.cols))
+	}
+	return row*m.cols + col
+}
+
+func (m Matrix) checkRow(row uint32) {
+	if row >= m.rows {
+		panic(fmt.Sprintf("bitset: row %d is out of range [0, %d)", row, m.rows))
+	}
+}
+
+func (m Matrix) checkSize(other Matrix) {
+	if m.rows != other.rows || m.cols != other.cols {
+		panic(fmt.Sprintf("bitset: mismatched matrix dimensions %d×%d and %d×%d", m.rows, m.cols, other.rows, other.cols))
+	}
+}
+
+// bitRange returns the n bits of s starting at bit start, shifted down so bit start is bit 0.
+func bitRange(s Set, start uint64, n uint32) Set {
+	return slice(s, start, start+uint64(n))
+}
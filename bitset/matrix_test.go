@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatrix(t *testing.T) {
+	m := NewMatrix(3, 100).Set(0, 1).Set(1, 99).Set(2, 0).Set(2, 64)
+	if m.Rows() != 3 || m.Cols() != 100 {
+		t.Errorf("Unexpected dimensions %d×%d", m.Rows(), m.Cols())
+	}
+	if !m.Test(1, 99) || m.Test(1, 98) || !m.Bits().Test(199) {
+		t.Error("Matrix has incorrect bits")
+	}
+	if m.Clear(1, 99).Test(1, 99) || !m.Test(1, 99) {
+		t.Error("Clear should return a new matrix without the bit")
+	}
+
+	wantRows := [][]uint32{{1}, {99}, {0, 64}}
+	for r, want := range wantRows {
+		if got := slices.Collect(m.Row(uint32(r)).Indices()); !slices.Equal(got, want) {
+			t.Errorf("Row(%d) = %v, expected %v", r, got, want)
+		}
+	}
+	if got := slices.Collect(m.Col(0).Indices()); !slices.Equal(got, []uint32{2}) {
+		t.Errorf("Col(0) = %v", got)
+	}
+
+	var zero Matrix
+	if zero.Union(zero).Bits().Count() != 0 {
+		t.Error("Zero matrix should be empty")
+	}
+}
+
+func TestMatrixWithRow(t *testing.T) {
+	m := NewMatrix(3, 70).Set(0, 5).Set(1, 5).Set(1, 69).Set(2, 0)
+	m2 := m.WithRow(1, FromIndices(3, 4, 70, 1000))
+	if got := slices.Collect(m2.Row(1).Indices()); !slices.Equal(got, []uint32{3, 4}) {
+		t.Errorf("Row(1) = %v, expected [3 4]", got)
+	}
+	if !m2.Test(0, 5) || !m2.Test(2, 0) || m2.Bits().Count() != 4 {
+		t.Error("WithRow should not change other rows")
+	}
+	if !m.Test(1, 69) {
+		t.Error("WithRow should not modify the original matrix")
+	}
+}
+
+func TestMatrixTranspose(t *testing.T) {
+	m := NewMatrix(2, 3).Set(0, 2).Set(1, 0).Set(1, 1)
+	tr := m.Transpose()
+	if tr.Rows() != 3 || tr.Cols() != 2 {
+		t.Fatalf("Unexpected dimensions %d×%d", tr.Rows(), tr.Cols())
+	}
+	for r := range m.Rows() {
+		for c := range m.Cols() {
+			if m.Test(r, c) != tr.Test(c, r) {
+				t.Errorf("Bit (%d, %d) was not transposed", r, c)
+			}
+		}
+	}
+	if tr.Transpose().Bits().Key() != m.Bits().Key() {
+		t.Error("Transposing twice should give the original matrix")
+	}
+}
+
+func TestMatrixOps(t *testing.T) {
+	a := NewMatrix(2, 2).Set(0, 0).Set(1, 1)
+	b := NewMatrix(2, 2).Set(0, 0).Set(0, 1)
+
+	if got := a.Union(b).Bits().Count(); got != 3 {
+		t.Errorf("Union has %d bits, expected 3", got)
+	}
+	if got := a.Intersect(b); !got.Test(0, 0) || got.Bits().Count() != 1 {
+		t.Error("Intersect has incorrect bits")
+	}
+	if got := a.Difference(b); !got.Test(1, 1) || got.Bits().Count() != 1 {
+		t.Error("Difference has incorrect bits")
+	}
+	if got := a.SymmetricDifference(b); !got.Test(0, 1) || !got.Test(1, 1) || got.Bits().Count() != 2 {
+		t.Error("SymmetricDifference has incorrect bits")
+	}
+}
+
+func TestMatrixPanics(t *testing.T) {
+	m := NewMatrix(2, 3)
+	tests := map[string]func(){
+		"Set":       func() { m.Set(0, 3) },
+		"Test":      func() { m.Test(2, 0) },
+		"Row":       func() { m.Row(2) },
+		"Col":       func() { m.Col(3) },
+		"Union":     func() { m.Union(NewMatrix(3, 2)) },
+		"NewMatrix": func() { NewMatrix(1<<16, 1<<16+1) },
+	}
+	for name, fn := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s should panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestBitRange(t *testing.T) {
+	for _, ts := range testSets() {
+		for _, start := range []uint64{0, 1, 63, 64, 130} {
+			got := bitRange(ts.s, start, 200)
+			checkCanonical(t, got)
+			for i := range uint32(200) {
+				idx := start + uint64(i)
+				if want := idx <= 0xffffffff && ts.s.Test(uint32(idx)); got.Test(i) != want {
+					t.Fatalf("%s: bitRange(%d) bit %d is %v, expected %v", ts.name, start, i, got.Test(i), want)
+				}
+			}
+			if got.Test(200) {
+				t.Errorf("%s: bitRange(%d) has bits past n", ts.name, start)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// bitset.Namer names bit indices, for rendering sets with Named.
+type Namer interface {
+	// BitName returns the name of the bit with the given bit index, or an empty string if it has none.
+	BitName(bitIndex uint32) string
+}
+
+// bitset.NamerFunc is a function that implements Namer.
+type NamerFunc func(bitIndex uint32) string
+
+// BitName returns f(bitIndex).
+func (f NamerFunc) BitName(bitIndex uint32) string {
+	return f(bitIndex)
+}
+
+// bitset.BitNames is a Namer where the name of bit i is the i-th element, e.g. []string{"READ", "WRITE", "ADMIN"}.
+type BitNames []string
+
+// BitName returns the name of the given bit index, or an empty string if it is past the end of names.
+func (names BitNames) BitName(bitIndex uint32) string {
+	if uint64(bitIndex) < uint64(len(names)) {
+		return names[bitIndex]
+	}
+	return ""
+}
+
+// bitset.Named is a Set that renders its bits with the names from Namer, e.g. "READ|WRITE|ADMIN", in String,
+// in JSON, and as a log/slog value, for logs and APIs that people read.
+// Bits without a name, or all bits if Namer is nil, are rendered as their decimal bit index.
+// The zero value has a nil Set, which is rendered as an empty set.
+type Named struct {
+	Set
+	Namer Namer
+}
+
+// Names returns the names of the bits set in n, in ascending order of their bit indices.
+func (n Named) Names() []string {
+	var names []string
+	for i := range orEmpty(n.Set).Indices() {
+		name := ""
+		if n.Namer != nil {
+			name = n.Namer.BitName(i)
+		}
+		if name == "" {
+			name = strconv.FormatUint(uint64(i), 10)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// String returns the names of the bits set in n separated by '|', e.g. "READ|WRITE".
+func (n Named) String() string {
+	return strings.Join(n.Names(), "|")
+}
+
+// LogValue implements slog.LogValuer, logging n as its String.
+func (n Named) LogValue() slog.Value {
+	return slog.StringValue(n.String())
+}
+
+// MarshalJSON implements json.Marshaler, encoding n as its String.
+func (n Named) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.String())
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	perms := BitNames{"READ", "WRITE", "", "ADMIN"}
+	tests := []struct {
+		n    Named
+		want string
+	}{
+		{Named{FromIndices(0, 1, 3), perms}, "READ|WRITE|ADMIN"},
+		{Named{FromIndices(1, 2, 70), perms}, "WRITE|2|70"},
+		{Named{FromIndices(4, 5), nil}, "4|5"},
+		{Named{Set: FromIndices(6), Namer: NamerFunc(func(i uint32) string { return "bit" + string(rune('0'+i)) })}, "bit6"},
+		{Named{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.n.String(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+
+	data, err := json.Marshal(map[string]Named{"perms": {FromIndices(0, 3), perms}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"perms":"READ|ADMIN"}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("login", "perms", Named{FromIndices(0, 1), perms})
+	if !strings.Contains(buf.String(), "perms=READ|WRITE") {
+		t.Errorf("Expected the names in the log output, got %q", buf.String())
+	}
+}
+
+func TestFlagNamesBitName(t *testing.T) {
+	names := NewFlagNames(map[uint8]string{1: "read", 2: "write"})
+	if got := (Named{FromIndices(1, 2, 3, 257), names}).String(); got != "read|write|3|257" {
+		t.Errorf("Expected read|write|3|257, got %q", got)
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "iter"
+
+// The functions in this file are the methods of Set as package-level functions that treat a nil Set as empty,
+// so struct fields of type Set can be used before they are initialized.
+
+// orEmpty returns s, or an empty set if s is nil.
+func orEmpty(s Set) Set {
+	if s == nil {
+		return New()
+	}
+	return s
+}
+
+// Has reports whether the bit for the given bit index is set in s. A nil s is empty.
+func Has(s Set, bitIndex uint32) bool {
+	return s != nil && s.Test(bitIndex)
+}
+
+// Add returns a new bitset.Set with the bits of s and the bit for the given bit index set. A nil s is empty.
+// The original bitset.Set is not modified.
+func Add(s Set, bitIndex uint32) Set {
+	return orEmpty(s).Set(bitIndex)
+}
+
+// Remove returns a new bitset.Set with the bits of s and the bit for the given bit index cleared. A nil s is empty.
+// The original bitset.Set is not modified.
+func Remove(s Set, bitIndex uint32) Set {
+	return orEmpty(s).Clear(bitIndex)
+}
+
+// Union returns a new bitset.Set with the bits that are set in either a or b. A nil set is empty.
+// Neither set is modified.
+func Union(a, b Set) Set {
+	return orEmpty(a).Union(orEmpty(b))
+}
+
+// Intersect returns a new bitset.Set with the bits that are set in both a and b. A nil set is empty.
+// Neither set is modified.
+func Intersect(a, b Set) Set {
+	return orEmpty(a).Intersect(orEmpty(b))
+}
+
+// Difference returns a new bitset.Set with the bits that are set in a but not in b. A nil set is empty.
+// Neither set is modified.
+func Difference(a, b Set) Set {
+	return orEmpty(a).Difference(orEmpty(b))
+}
+
+// SymmetricDifference returns a new bitset.Set with the bits that are set in exactly one of a and b.
+// A nil set is empty. Neither set is modified.
+func SymmetricDifference(a, b Set) Set {
+	return orEmpty(a).SymmetricDifference(orEmpty(b))
+}
+
+// Count returns the number of set bits in s. A nil s is empty.
+func Count(s Set) int {
+	if s == nil {
+		return 0
+	}
+	return s.Count()
+}
+
+// Indices returns an iterator over the bit indices of the set bits in s, in ascending order. A nil s is empty.
+func Indices(s Set) iter.Seq[uint32] {
+	return orEmpty(s).Indices()
+}
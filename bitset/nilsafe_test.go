@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNilSafe(t *testing.T) {
+	var s Set
+	if Has(s, 0) || Count(s) != 0 || len(slices.Collect(Indices(s))) != 0 {
+		t.Error("A nil set should be empty")
+	}
+	if got := Add(s, 5); !Has(got, 5) || Count(got) != 1 {
+		t.Errorf("Add to a nil set = %v", slices.Collect(Indices(got)))
+	}
+	if got := Remove(s, 5); got == nil || Count(got) != 0 {
+		t.Error("Remove from a nil set should return an empty set")
+	}
+	if got, changed := TestAndSet(s, 5); !changed || !Has(got, 5) {
+		t.Error("TestAndSet on a nil set should set the bit")
+	}
+	if _, changed := TestAndClear(s, 5); changed {
+		t.Error("TestAndClear on a nil set should not change anything")
+	}
+
+	a := FromIndices(1, 2)
+	tests := []struct {
+		name     string
+		got      Set
+		wantBits []uint32
+	}{
+		{"Union", Union(a, nil), []uint32{1, 2}},
+		{"Union", Union(nil, a), []uint32{1, 2}},
+		{"Intersect", Intersect(a, nil), nil},
+		{"Difference", Difference(a, nil), []uint32{1, 2}},
+		{"Difference", Difference(nil, a), nil},
+		{"SymmetricDifference", SymmetricDifference(nil, a), []uint32{1, 2}},
+		{"Union", Union(nil, nil), nil},
+	}
+	for _, tt := range tests {
+		if got := slices.Collect(Indices(tt.got)); !slices.Equal(got, tt.wantBits) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.wantBits)
+		}
+	}
+
+	if got := Add(a, 3); !slices.Equal(slices.Collect(got.Indices()), []uint32{1, 2, 3}) || Has(a, 3) {
+		t.Error("Add should work like Set on a non-nil set")
+	}
+}
@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// wordOp is a bitwise operation applied to corresponding words of two sets.
+// It must return 0 when both words are 0.
+type wordOp func(x, y uint64) uint64
+
+func or(x, y uint64) uint64     { return x | y }
+func and(x, y uint64) uint64    { return x & y }
+func andNot(x, y uint64) uint64 { return x &^ y }
+func xor(x, y uint64) uint64    { return x ^ y }
+
+// combine returns the result of applying op to the bits of a and b.
+func combine(a, b Set, op wordOp) Set {
+	a, b = materialized(a), materialized(b)
+	if a, ok := a.(bitSet64); ok {
+		if b, ok := b.(bitSet64); ok {
+			return bitSet64(op(uint64(a), uint64(b)))
+		}
+	}
+
+	if op(0b1100, 0b1010) == 0b1000 {
+		if s, ok := intersectSparse(a, b); ok {
+			return s
+		}
+	}
+
+	_, aRuns := a.(runBitSet)
+	_, bRuns := b.(runBitSet)
+	if aRuns || bRuns {
+		// Long runs would explode into full words, so work on the runs directly
+		return fromRuns(combineRuns(runsOf(a), runsOf(b), op))
+	}
+
+	aWords, aDense := denseWords(a)
+	bWords, bDense := denseWords(b)
+	if aDense && bDense {
+		newBits := make([]uint64, max(len(aWords), len(bWords)))
+		recordWords(len(newBits))
+		n := 0
+		if kernel := kernelFor(op); kernel != nil {
+			n = min(len(aWords), len(bWords))
+			kernel(newBits[:n], aWords, bWords)
+		}
+		for i := n; i < len(newBits); i++ {
+			var x, y uint64
+			if i < len(aWords) {
+				x = aWords[i]
+			}
+			if i < len(bWords) {
+				y = bWords[i]
+			}
+			newBits[i] = op(x, y)
+		}
+		return fromWords(newBits)
+	}
+
+	return fromWordList(combineWordLists(nonzeroWords(a), nonzeroWords(b), op))
+}
+
+// denseWords returns the words of s if s is stored as plain words.
+func denseWords(s Set) ([]uint64, bool) {
+	switch s := materialized(s).(type) {
+	case bitSet64:
+		return []uint64{uint64(s)}, true
+	case bitSet192:
+		return s[:], true
+	case largeBitSet:
+		return s, true
+	case retainedBitSet:
+		return s.words, true
+	case mappedBitSet:
+		return s.words, true
+	}
+	return nil, false
+}
+
+// runsOf returns the runs of set bits in s.
+func runsOf(s Set) []run[uint32] {
+	s = materialized(s)
+	switch s := s.(type) {
+	case runBitSet:
+		return s
+	case sparseBitSet:
+		return runsFromIndices(s)
+	case chunkedBitSet:
+		return s.toRuns()
+	case trieBitSet:
+		return s.toRuns()
+	}
+
+	words, _ := denseWords(s)
+	return appendWordRuns(nil, words, 0)
+}
+
+// combineRuns returns the runs of the result of applying op to the bits in the runs a and b.
+func combineRuns(a, b []run[uint32], op wordOp) []run[uint32] {
+	var out []run[uint32]
+	var i, j int
+	pos := uint64(0)
+	for i < len(a) || j < len(b) {
+		// Find the next position where either a or b starts or stops a run.
+		// All the bits in [pos, next) are either in a run or not for each of a and b.
+		next := uint64(1 << 32)
+		inA := i < len(a) && uint64(a[i].start) <= pos
+		if i < len(a) {
+			next = min(next, nextBoundary(a[i], inA))
+		}
+		inB := j < len(b) && uint64(b[j].start) <= pos
+		if j < len(b) {
+			next = min(next, nextBoundary(b[j], inB))
+		}
+
+		if op(mask(inA), mask(inB)) != 0 {
+			out = appendRun(out, uint32(pos), uint32(next-1))
+		}
+
+		pos = next
+		if i < len(a) && uint64(a[i].last)+1 == pos {
+			i++
+		}
+		if j < len(b) && uint64(b[j].last)+1 == pos {
+			j++
+		}
+	}
+	return out
+}
+
+func nextBoundary(r run[uint32], in bool) uint64 {
+	if in {
+		return uint64(r.last) + 1
+	}
+	return uint64(r.start)
+}
+
+func mask(set bool) uint64 {
+	if set {
+		return ^uint64(0)
+	}
+	return 0
+}
+
+// indexedWord is a nonzero word of a set, along with its index in the equivalent largeBitSet.
+type indexedWord struct {
+	i int
+	w uint64
+}
+
+// nonzeroWords returns the nonzero words of s in ascending order.
+func nonzeroWords(s Set) []indexedWord {
+	var list []indexedWord
+	appendWord := func(i int, w uint64) {
+		if n := len(list); n > 0 && list[n-1].i == i {
+			list[n-1].w |= w
+		} else if w != 0 {
+			list = append(list, indexedWord{i, w})
+		}
+	}
+
+	s = materialized(s)
+	switch s := s.(type) {
+	case sparseBitSet:
+		for _, idx := range s {
+			appendWord(int(idx/64), 1<<(idx%64))
+		}
+	case chunkedBitSet:
+		for _, ch := range s.chunks {
+			base := int(ch.key) * chunkWords
+			if bm, ok := ch.c.(*bitmapContainer); ok {
+				for i, w := range bm {
+					appendWord(base+i, w)
+				}
+				continue
+			}
+			for _, idx := range ch.c.appendTo(nil, 0) {
+				appendWord(base+int(idx/64), 1<<(idx%64))
+			}
+		}
+	case trieBitSet:
+		s.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+			for i, w := range leaf {
+				appendWord(wordIdx+i, w)
+			}
+		})
+	case runBitSet:
+		for _, r := range s {
+			for i := r.start / 64; i <= r.last/64; i++ {
+				var w [1]uint64
+				fillRange(w[:], max(r.start, i*64)-i*64, min(r.last, i*64+63)-i*64)
+				appendWord(int(i), w[0])
+			}
+		}
+	default:
+		words, _ := denseWords(s)
+		for i, w := range words {
+			appendWord(i, w)
+		}
+	}
+	return list
+}
+
+// indicesOf returns the bit indices of all the set bits in s, in ascending order.
+func indicesOf(s Set) []uint32 {
+	var indices []uint32
+	for _, iw := range nonzeroWords(s) {
+		indices = appendBits(indices, iw.w, uint32(iw.i*64))
+	}
+	return indices
+}
+
+// combineWordLists returns the nonzero words of the result of applying op to the words in a and b.
+func combineWordLists(a, b []indexedWord, op wordOp) []indexedWord {
+	out := make([]indexedWord, 0, max(len(a), len(b)))
+	var i, j int
+	for i < len(a) || j < len(b) {
+		var w uint64
+		var idx int
+		switch {
+		case j >= len(b) || (i < len(a) && a[i].i < b[j].i):
+			idx, w = a[i].i, op(a[i].w, 0)
+			i++
+		case i >= len(a) || b[j].i < a[i].i:
+			idx, w = b[j].i, op(0, b[j].w)
+			j++
+		default:
+			idx, w = a[i].i, op(a[i].w, b[j].w)
+			i++
+			j++
+		}
+
+		if w != 0 {
+			out = append(out, indexedWord{idx, w})
+		}
+	}
+	return out
+}
+
+// fromWordList returns the most suitable Set representation for the given nonzero words.
+func fromWordList(list []indexedWord) Set {
+	if len(list) == 0 {
+		return bitSet64(0)
+	}
+
+	w := list[len(list)-1].i + 1
+	if w <= 3 {
+		words := make([]uint64, w)
+		for _, iw := range list {
+			words[iw.i] = iw.w
+		}
+		return fromWords(words)
+	}
+
+	n, r := 0, 0
+	for k, iw := range list {
+		var carry uint64
+		if k > 0 && list[k-1].i == iw.i-1 {
+			carry = list[k-1].w >> 63
+		}
+		n += bits.OnesCount64(iw.w)
+		r += bits.OnesCount64(iw.w &^ (iw.w<<1 | carry))
+	}
+
+	switch {
+	case preferRuns(n, r, w):
+		runs := make([]run[uint32], 0, r)
+		for _, iw := range list {
+			runs = appendWordRuns(runs, []uint64{iw.w}, uint32(iw.i*64))
+		}
+		return runBitSet(runs)
+	case !preferSparse(n, w):
+		words := make([]uint64, w)
+		for _, iw := range list {
+			words[iw.i] = iw.w
+		}
+		return denseFromWords(words, n, r)
+	}
+
+	indices := make([]uint32, 0, n)
+	for _, iw := range list {
+		indices = appendBits(indices, iw.w, uint32(iw.i*64))
+	}
+	if n > maxSparseLen {
+		return chunkedFromIndices(indices)
+	}
+	return sparseBitSet(indices)
+}
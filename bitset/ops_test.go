@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// testSet is a Set along with the bits it is expected to contain.
+type testSet struct {
+	name string
+	s    Set
+	bits []uint32 // all the bits in s, unless runs is set
+	runs []run[uint32]
+	set  map[uint32]bool
+}
+
+func (ts testSet) has(i uint32) bool {
+	for _, r := range ts.runs {
+		if r.start <= i && i <= r.last {
+			return true
+		}
+	}
+	return ts.set[i]
+}
+
+// probes returns bit indices worth testing for ts: its bits and their neighbours, or its run boundaries.
+func (ts testSet) probes() []uint32 {
+	var p []uint32
+	add := func(i uint32) {
+		p = append(p, i, i-1, i+1)
+	}
+	for _, b := range ts.bits {
+		add(b)
+	}
+	for _, r := range ts.runs {
+		add(r.start)
+		add(r.last)
+		add(r.start + (r.last-r.start)/2)
+	}
+	return p
+}
+
+// testSets returns a set of each representation, with overlapping bits.
+func testSets() []testSet {
+	build := func(name string, bits []uint32) testSet {
+		set := map[uint32]bool{}
+		for _, b := range bits {
+			set[b] = true
+		}
+		return testSet{name: name, s: NewBuilder(0).WithMany(bits...).Build(), bits: bits, set: set}
+	}
+
+	var dense, trie, chunked []uint32
+	for i := uint32(0); i < 300; i += 3 {
+		dense = append(dense, i)
+	}
+	for i := uint32(0); i < 70_000; i += 3 {
+		trie = append(trie, i)
+	}
+	for i := uint32(0); i < 5000; i++ {
+		chunked = append(chunked, i*1000)
+	}
+	runs := []run[uint32]{{10, 1_000_000}, {3_000_000, 3_000_100}}
+
+	return []testSet{
+		{name: "empty", s: New()},
+		build("small", []uint32{1, 5, 63}),
+		build("medium", []uint32{0, 64, 150, 191}),
+		build("large", dense),
+		build("sparse", []uint32{7, 63, 100_000, 2_000_000}),
+		build("trie", trie),
+		build("chunked", chunked),
+		{name: "runs", s: fromRuns(runs), runs: runs},
+	}
+}
+
+func TestTestSets(t *testing.T) {
+	want := []string{"bitset.bitSet64", "bitset.bitSet64", "bitset.bitSet192", "bitset.largeBitSet", "bitset.sparseBitSet", "bitset.trieBitSet", "bitset.chunkedBitSet", "bitset.runBitSet"}
+	for i, ts := range testSets() {
+		if got := fmt.Sprintf("%T", ts.s); got != want[i] {
+			t.Errorf("%s: expected %s, got %s", ts.name, want[i], got)
+		}
+		for _, p := range ts.probes() {
+			if ts.s.Test(p) != ts.has(p) {
+				t.Fatalf("%s: bit %d should be %v", ts.name, p, ts.has(p))
+			}
+		}
+	}
+}
+
+func TestBooleanOperations(t *testing.T) {
+	ops := []struct {
+		name string
+		fn   func(a, b Set) Set
+		want func(x, y bool) bool
+	}{
+		{"Union", Set.Union, func(x, y bool) bool { return x || y }},
+		{"Intersect", Set.Intersect, func(x, y bool) bool { return x && y }},
+		{"Difference", Set.Difference, func(x, y bool) bool { return x && !y }},
+		{"SymmetricDifference", Set.SymmetricDifference, func(x, y bool) bool { return x != y }},
+	}
+
+	sets := testSets()
+	for _, op := range ops {
+		for _, a := range sets {
+			for _, b := range sets {
+				t.Run(fmt.Sprintf("%s/%s_%s", op.name, a.name, b.name), func(t *testing.T) {
+					res := op.fn(a.s, b.s)
+					for _, p := range append(a.probes(), b.probes()...) {
+						if want := op.want(a.has(p), b.has(p)); res.Test(p) != want {
+							t.Fatalf("Bit %d: want %v, got %v (result is %T)", p, want, res.Test(p), res)
+						}
+					}
+
+					// Operands are not modified
+					for _, p := range a.probes() {
+						if a.s.Test(p) != a.has(p) {
+							t.Fatalf("Operand was modified at bit %d", p)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+// checkCanonical fails the test if s is not stored the same way as a set with the same bits built from scratch.
+func checkCanonical(t *testing.T, s Set) {
+	t.Helper()
+	if want := fromWords(wordsOf(s)); !reflect.DeepEqual(s, want) {
+		t.Fatalf("Set is not in canonical form: got %T, want %T", s, want)
+	}
+}
+
+func TestCanonicalForm(t *testing.T) {
+	sets := testSets()
+	ops := []func(a, b Set) Set{Set.Union, Set.Intersect, Set.Difference, Set.SymmetricDifference}
+	for _, a := range sets {
+		for _, b := range sets {
+			for _, op := range ops {
+				checkCanonical(t, op(a.s, b.s))
+			}
+		}
+	}
+
+	r := rand.New(rand.NewPCG(5, 6))
+	for _, ts := range sets {
+		t.Run(ts.name, func(t *testing.T) {
+			// Bits near the top of the index range would make the full word slices in checkCanonical huge
+			probes := []uint32{0, 64, 191, 192, 1000, 1 << 20}
+			for _, p := range ts.probes() {
+				if p < 1<<22 {
+					probes = append(probes, p)
+				}
+			}
+
+			s := ts.s
+			for range 200 {
+				p := probes[r.IntN(len(probes))]
+				if r.IntN(2) == 0 {
+					s = s.Set(p)
+				} else {
+					s = s.Clear(p)
+				}
+				checkCanonical(t, s)
+			}
+		})
+	}
+}
+
+func TestBooleanOperationsOnRuns(t *testing.T) {
+	a := fromRuns([]run[uint32]{{0, 999}, {2000, 2999}, {1 << 31, 1<<32 - 1}})
+	b := fromRuns([]run[uint32]{{500, 2499}, {1 << 31, 1<<31 + 99}})
+
+	tests := []struct {
+		name string
+		got  Set
+		want []run[uint32]
+	}{
+		{"Union", a.Union(b), []run[uint32]{{0, 2999}, {1 << 31, 1<<32 - 1}}},
+		{"Intersect", a.Intersect(b), []run[uint32]{{500, 999}, {2000, 2499}, {1 << 31, 1<<31 + 99}}},
+		{"Difference", a.Difference(b), []run[uint32]{{0, 499}, {2500, 2999}, {1<<31 + 100, 1<<32 - 1}}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []run[uint32]{{0, 499}, {1000, 1999}, {2500, 2999}, {1<<31 + 100, 1<<32 - 1}}},
+	}
+	for _, tt := range tests {
+		got, ok := tt.got.(runBitSet)
+		if !ok {
+			t.Errorf("%s: expected runBitSet, but got %T", tt.name, tt.got)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected runs %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+)
+
+// Max returns the highest set bit of s, and false if s is empty.
+// It takes constant time for every representation except retained sets with trailing zero words and views,
+// since the others have no trailing zero words, or keep their highest word up to date.
+func Max(s Set) (uint32, bool) {
+	switch s := materialized(s).(type) {
+	case bitSet64:
+		if s == 0 {
+			return 0, false
+		}
+		return uint32(63 - bits.LeadingZeros64(uint64(s))), true
+	case bitSet192:
+		return maxInWords(s[:])
+	case largeBitSet:
+		return maxInWords(s)
+	case mappedBitSet:
+		return maxInWords(s.words)
+	case retainedBitSet:
+		return maxInWords(s.words)
+	case sparseBitSet:
+		if len(s) == 0 {
+			return 0, false
+		}
+		return s[len(s)-1], true
+	case runBitSet:
+		if len(s) == 0 {
+			return 0, false
+		}
+		return s[len(s)-1].last, true
+	case chunkedBitSet:
+		last := s.chunks[len(s.chunks)-1]
+		return uint32(last.key)<<16 | uint32(last.c.max()), true
+	case trieBitSet:
+		// The width of a trie is that of the equivalent largeBitSet, so its last word is nonzero
+		return uint32((s.w-1)*64 + 63 - bits.LeadingZeros64(s.word(s.w-1))), true
+	default:
+		var last uint32
+		found := false
+		for i := range s.Indices() {
+			last, found = i, true
+		}
+		return last, found
+	}
+}
+
+// Equal reports whether a and b have the same bits, whatever their representations.
+// It compares their highest bits and counts first, which take constant time for most representations,
+// and only compares their words if those are equal.
+func Equal(a, b Set) bool {
+	maxA, okA := Max(a)
+	maxB, okB := Max(b)
+	if maxA != maxB || okA != okB {
+		return false
+	}
+	if a.Count() != b.Count() {
+		return false
+	}
+	return compareWordLists(nonzeroWords(a), nonzeroWords(b)) == 0
+}
+
+// Compare returns -1 if a is less than b, 0 if they have the same bits, and +1 if a is greater than b,
+// in the total order of sets as the binary numbers they represent, where bit i is worth 2^i (see AddUint64).
+// That is, the set with the highest bit that is not in the other is the greater one, and the empty set is the least.
+// The order only depends on the bits of the sets, not on their representations, so it is deterministic.
+func Compare(a, b Set) int {
+	// The highest bit decides, unless it is the same
+	maxA, okA := Max(a)
+	maxB, okB := Max(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return +1
+	}
+	if c := cmp.Compare(maxA, maxB); c != 0 {
+		return c
+	}
+	return compareWordLists(nonzeroWords(a), nonzeroWords(b))
+}
+
+// Less reports whether a is less than b, in the order described by Compare.
+func Less(a, b Set) bool {
+	return Compare(a, b) < 0
+}
+
+// SortSets sorts sets in ascending order, as described by Compare, so equal sets are next to each other.
+// It reads the words of every set once, rather than once per comparison.
+func SortSets(sets []Set) {
+	type keyed struct {
+		s     Set
+		words []indexedWord
+	}
+	items := make([]keyed, len(sets))
+	for i, s := range sets {
+		items[i] = keyed{s, nonzeroWords(s)}
+	}
+	slices.SortStableFunc(items, func(x, y keyed) int {
+		return compareWordLists(x.words, y.words)
+	})
+	for i, item := range items {
+		sets[i] = item.s
+	}
+}
+
+// SearchSets searches for target in sets, which must be sorted as by SortSets, and returns the position where target
+// is found, or where it would be inserted to keep sets sorted, and whether it was found, like slices.BinarySearch.
+func SearchSets(sets []Set, target Set) (int, bool) {
+	words := nonzeroWords(target)
+	return slices.BinarySearchFunc(sets, words, func(s Set, words []indexedWord) int {
+		return compareWordLists(nonzeroWords(s), words)
+	})
+}
+
+// CompactSets replaces consecutive sets with the same bits with the first of them, like slices.Compact,
+// and returns the shortened slice. Call it after SortSets to remove every duplicate.
+func CompactSets(sets []Set) []Set {
+	return slices.CompactFunc(sets, func(a, b Set) bool {
+		return Compare(a, b) == 0
+	})
+}
+
+// maxInWords is Max for a set stored as words, which may have trailing zero words.
+func maxInWords(words []uint64) (uint32, bool) {
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i] != 0 {
+			return uint32(i*64 + 63 - bits.LeadingZeros64(words[i])), true
+		}
+	}
+	return 0, false
+}
+
+// compareWordLists is Compare for the nonzero words of two sets.
+func compareWordLists(a, b []indexedWord) int {
+	i, j := len(a)-1, len(b)-1
+	for ; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := cmp.Compare(a[i].i, b[j].i); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a[i].w, b[j].w); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(i, j)
+}
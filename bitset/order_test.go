@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestMax(t *testing.T) {
+	sets := testSets()
+	for _, s := range []Set{
+		FromIndices(),
+		FromIndices(0),
+		FromIndices(130),
+		Full(trieMinWords * 64),
+		Full(trieMinWords * 64).Set(1<<20 + 5),
+		IntersectView(New().Set(1), New().Set(2)),
+		UnionView(New().Set(1), New().Set(300)),
+	} {
+		sets = append(sets, testSet{name: FormatRangeList(s), s: s})
+		sets = append(sets, testSet{name: "retained " + FormatRangeList(s), s: WithShrinkPolicy(s, ShrinkNever).Set(1 << 22).Clear(1 << 22)})
+	}
+
+	for _, ts := range sets {
+		want, wantOK := uint32(0), false
+		if n := ts.s.Count(); n > 0 {
+			want, wantOK = nthBit(ts.s, n-1), true
+		}
+		if got, ok := Max(ts.s); got != want || ok != wantOK {
+			t.Errorf("%s: Max = %d, %v, expected %d, %v", ts.name, got, ok, want, wantOK)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			if got, want := Equal(a.s, b.s), a.name == b.name; got != want {
+				t.Errorf("Equal(%s, %s) = %v, expected %v", a.name, b.name, got, want)
+			}
+		}
+		for _, other := range []Set{
+			FromIndices(slices.Collect(a.s.Indices())...),
+			WithShrinkPolicy(a.s, ShrinkNever).Set(1 << 22).Clear(1 << 22),
+			UnionView(a.s, New()),
+		} {
+			if !Equal(a.s, other) || !Equal(other, a.s) {
+				t.Errorf("%s: Expected a %v set with the same bits to be equal", a.name, other.Kind())
+			}
+		}
+	}
+
+	// Same highest bit and count, different bits
+	if Equal(FromIndices(1, 100), FromIndices(2, 100)) {
+		t.Error("Expected sets with different bits to be unequal")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	sets := testSets()
+	for _, s := range []Set{
+		FromIndices(),
+		FromIndices(64),
+		FromIndices(63, 64),
+		FromIndices(0, 64),
+		Full(65),
+	} {
+		sets = append(sets, testSet{name: FormatRangeList(s), s: s})
+		sets = append(sets, testSet{name: "retained " + FormatRangeList(s), s: WithShrinkPolicy(s, ShrinkNever).Set(1 << 12).Clear(1 << 12)})
+	}
+
+	for _, a := range sets {
+		for _, b := range sets {
+			want := bigOf(a.s).Cmp(bigOf(b.s))
+			if got := Compare(a.s, b.s); got != want {
+				t.Errorf("Compare(%s, %s) = %d, expected %d", a.name, b.name, got, want)
+			}
+			if got := Less(a.s, b.s); got != (want < 0) {
+				t.Errorf("Less(%s, %s) = %v, expected %v", a.name, b.name, got, want < 0)
+			}
+		}
+	}
+}
+
+func TestSortSets(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	var sets []Set
+	for range 200 {
+		s := FromIndices(randomIndices(r, r.IntN(4), 200)...)
+		if r.IntN(2) == 0 {
+			s = WithShrinkPolicy(s, ShrinkNever).Set(1 << 12).Clear(1 << 12)
+		}
+		sets = append(sets, s)
+	}
+
+	SortSets(sets)
+	if !slices.IsSortedFunc(sets, Compare) {
+		t.Fatal("Expected the sets to be sorted")
+	}
+	for _, s := range sets {
+		i, found := SearchSets(sets, s)
+		if !found || Compare(sets[i], s) != 0 || (i > 0 && Compare(sets[i-1], s) == 0) {
+			t.Errorf("SearchSets(%s) = %d, %v, expected the first equal set", FormatRangeList(s), i, found)
+		}
+	}
+	if i, found := SearchSets(sets, FromIndices(1000)); found || i != len(sets) {
+		t.Errorf("SearchSets of a greater set = %d, %v, expected %d, false", i, found, len(sets))
+	}
+
+	n := len(sets)
+	compacted := CompactSets(sets)
+	if len(compacted) >= n {
+		t.Fatalf("Expected duplicates to be removed, got %d of %d sets", len(compacted), n)
+	}
+	for i := 1; i < len(compacted); i++ {
+		if !Less(compacted[i-1], compacted[i]) {
+			t.Errorf("Expected compacted sets to be strictly increasing at %d", i)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultParallelThreshold = 1 << 16 // words, i.e. 4Mi bits
+
+// bitset.Parallel runs operations on huge sets on multiple goroutines, so they aren't limited to a single core.
+//
+// Only sets stored as words (dense, trie, and mapped sets) that span at least Threshold words are processed in parallel.
+// Everything else is processed on the calling goroutine, the same as the equivalent Set methods.
+// Combining two tries shares the subtries the result has in common with either of them, rather than copying them.
+// The zero value is ready to use.
+type Parallel struct {
+	// Workers is the number of goroutines to use. Zero means runtime.GOMAXPROCS(0).
+	Workers int
+
+	// Threshold is the minimum number of words a set must span to be processed in parallel.
+	// Zero means 65536 words (4Mi bits).
+	Threshold int
+}
+
+// Count returns the number of set bits in s.
+func (p Parallel) Count(s Set) int {
+	words, ok := denseWords(s)
+	if !ok || len(words) < p.threshold() {
+		// The other representations keep their count
+		return s.Count()
+	}
+
+	var n atomic.Int64
+	p.split(len(words), func(lo, hi int) {
+		n.Add(int64(popCount(words[lo:hi])))
+	})
+	return int(n.Load())
+}
+
+// Union returns a new bitset.Set with the bits that are set in either a or b.
+// Neither set is modified.
+func (p Parallel) Union(a, b Set) Set {
+	return p.combine(a, b, or)
+}
+
+// Intersect returns a new bitset.Set with the bits that are set in both a and b.
+// Neither set is modified.
+func (p Parallel) Intersect(a, b Set) Set {
+	return p.combine(a, b, and)
+}
+
+// ForEach calls fn with the bit index of every set bit in s.
+// fn is called concurrently from multiple goroutines, in no particular order.
+func (p Parallel) ForEach(s Set, fn func(bitIndex uint32)) {
+	forEachBit := func(words []uint64, base int) {
+		for i, w := range words {
+			for ; w != 0; w &= w - 1 {
+				fn(uint32((base+i)*64 + bits.TrailingZeros64(w)))
+			}
+		}
+	}
+
+	if t, ok := s.(trieBitSet); ok && t.w >= p.threshold() {
+		p.split(trieFanout, func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				if c := t.root[i]; c != nil {
+					walkLeaves(c, t.height-1, i*trieCapacity(t.height-1), func(wordIdx int, leaf *trieLeaf) {
+						forEachBit(leaf[:], wordIdx)
+					})
+				}
+			}
+		})
+		return
+	}
+
+	if words, ok := denseWords(s); ok && len(words) >= p.threshold() {
+		p.split(len(words), func(lo, hi int) {
+			forEachBit(words[lo:hi], lo)
+		})
+		return
+	}
+
+	for _, iw := range nonzeroWords(s) {
+		forEachBit([]uint64{iw.w}, iw.i)
+	}
+}
+
+// combine returns the result of applying op to the bits of a and b, computed in parallel if they are big enough.
+func (p Parallel) combine(a, b Set, op wordOp) Set {
+	aLen, aOk := parallelWordLen(a)
+	bLen, bOk := parallelWordLen(b)
+	if !aOk || !bOk || max(aLen, bLen) < p.threshold() {
+		return combine(a, b, op)
+	}
+	if a, ok := a.(trieBitSet); ok {
+		if b, ok := b.(trieBitSet); ok {
+			return p.combineTries(a, b, op)
+		}
+	}
+
+	aWords, bWords := p.words(a), p.words(b)
+	words := make([]uint64, max(len(aWords), len(bWords)))
+	var n, r atomic.Int64
+	p.split(len(words), func(lo, hi int) {
+		var carry uint64 // the highest bit of the previous word
+		if lo > 0 {
+			carry = op(wordAt(aWords, lo-1), wordAt(bWords, lo-1)) >> 63
+		}
+
+		var chunkN, chunkR int
+		for i := lo; i < hi; i++ {
+			w := op(wordAt(aWords, i), wordAt(bWords, i))
+			words[i] = w
+			chunkN += bits.OnesCount64(w)
+			chunkR += bits.OnesCount64(w &^ (w<<1 | carry))
+			carry = w >> 63
+		}
+		n.Add(int64(chunkN))
+		r.Add(int64(chunkR))
+	})
+
+	last := len(words)
+	for last > 0 && words[last-1] == 0 {
+		last--
+	}
+	if last <= 3 {
+		return fromWords(words[:last])
+	}
+	return fromCountedWords(words[:last], int(n.Load()), int(r.Load()))
+}
+
+// combineTries returns the result of applying op to the bits of a and b, combining the subtries under each child of
+// their roots on p's workers. The result shares the subtries it has in common with a or b rather than copying them.
+func (p Parallel) combineTries(a, b trieBitSet, op wordOp) Set {
+	height := max(a.height, b.height)
+	aRoot, bRoot := a.withHeight(height), b.withHeight(height)
+
+	var root trieNode
+	var stats [trieFanout]trieStats
+	capacity := trieCapacity(height - 1)
+	p.split(trieFanout, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			root[i], stats[i] = trieCombine(aRoot[i], bRoot[i], height-1, i*capacity, op)
+		}
+	})
+
+	var total trieStats
+	for _, s := range stats {
+		total = total.join(s)
+	}
+	return trieFromRoot(&root, height, total)
+}
+
+// parallelWordLen returns the number of words s spans, or false if s is not stored as words that can be read in parallel.
+func parallelWordLen(s Set) (int, bool) {
+	switch s := s.(type) {
+	case bitSet64, bitSet192, largeBitSet, mappedBitSet:
+		words, _ := denseWords(s)
+		return len(words), true
+	case trieBitSet:
+		return s.w, true
+	}
+	return 0, false
+}
+
+// words returns the words of s, which must be stored as words, and may share memory with s.
+func (p Parallel) words(s Set) []uint64 {
+	t, ok := s.(trieBitSet)
+	if !ok {
+		words, _ := denseWords(s)
+		return words
+	}
+
+	words := make([]uint64, t.w)
+	p.split(trieFanout, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if c := t.root[i]; c != nil {
+				walkLeaves(c, t.height-1, i*trieCapacity(t.height-1), func(wordIdx int, leaf *trieLeaf) {
+					copy(words[wordIdx:], leaf[:])
+				})
+			}
+		}
+	})
+	return words
+}
+
+// split calls fn on p's workers with consecutive ranges [lo, hi) that together cover [0, n), and waits for them to return.
+func (p Parallel) split(n int, fn func(lo, hi int)) {
+	workers := min(p.Workers, n)
+	if workers <= 0 {
+		workers = min(runtime.GOMAXPROCS(0), n)
+	}
+
+	var wg sync.WaitGroup
+	for k := range workers {
+		lo, hi := n*k/workers, n*(k+1)/workers
+		wg.Go(func() { fn(lo, hi) })
+	}
+	wg.Wait()
+}
+
+func (p Parallel) threshold() int {
+	if p.Threshold <= 0 {
+		return defaultParallelThreshold
+	}
+	return p.Threshold
+}
+
+func wordAt(words []uint64, i int) uint64 {
+	if i < len(words) {
+		return words[i]
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParallelOperations(t *testing.T) {
+	p := Parallel{Workers: 3, Threshold: 4}
+	sets := testSets()
+	for _, a := range sets {
+		if got, want := p.Count(a.s), a.s.Count(); got != want {
+			t.Errorf("%s: Count: want %d, got %d", a.name, want, got)
+		}
+
+		for _, b := range sets {
+			if got, want := p.Union(a.s, b.s), a.s.Union(b.s); got.Key() != want.Key() {
+				t.Errorf("Union of %s and %s: got %T, want %T", a.name, b.name, got, want)
+			}
+			if got, want := p.Intersect(a.s, b.s), a.s.Intersect(b.s); got.Key() != want.Key() {
+				t.Errorf("Intersect of %s and %s: got %T, want %T", a.name, b.name, got, want)
+			}
+		}
+	}
+}
+
+func TestParallelCanonical(t *testing.T) {
+	p := Parallel{Workers: 4, Threshold: 4}
+	a, b := everyThird(100_000), fromWords([]uint64{0, 0, 0, 0, 0, 1, 1})
+
+	// The result counts are merged from every worker, including the runs that span two chunks
+	checkCanonical(t, p.Union(a, everyThird(200_000)))
+	checkCanonical(t, p.Intersect(a, b))
+	checkCanonical(t, p.Union(largeBitSet{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}, b))
+}
+
+func TestParallelForEach(t *testing.T) {
+	p := Parallel{Workers: 3, Threshold: 4}
+	for _, ts := range testSets() {
+		var mu sync.Mutex
+		seen := map[uint32]bool{}
+		p.ForEach(ts.s, func(i uint32) {
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[i] {
+				t.Errorf("%s: bit %d visited twice", ts.name, i)
+			}
+			seen[i] = true
+		})
+
+		if len(seen) != ts.s.Count() {
+			t.Errorf("%s: expected %d bits, got %d", ts.name, ts.s.Count(), len(seen))
+		}
+		for i := range seen {
+			if !ts.s.Test(i) {
+				t.Fatalf("%s: visited bit %d which is not set", ts.name, i)
+			}
+		}
+	}
+}
+
+func TestParallelTries(t *testing.T) {
+	p := Parallel{Workers: 4, Threshold: 4}
+	a := everyThird(1 << 20)
+	b := a.Set(1).Clear(3000).Set(1<<22 + 5)
+	c := everyThird(1 << 18).Union(FromIndices(1<<21 + 1))
+	if _, ok := b.(trieBitSet); !ok {
+		t.Fatalf("Expected a trie, got %T", b)
+	}
+
+	for _, pair := range [][2]Set{{a, b}, {b, a}, {a, c}, {c, b}, {a, a}} {
+		x, y := pair[0], pair[1]
+		if got, want := p.Union(x, y), x.Union(y); !Equal(got, want) {
+			t.Error("Union of tries has incorrect bits")
+		} else {
+			checkCanonical(t, got)
+		}
+		if got, want := p.Intersect(x, y), x.Intersect(y); !Equal(got, want) {
+			t.Error("Intersect of tries has incorrect bits")
+		} else {
+			checkCanonical(t, got)
+		}
+	}
+
+	// The leaves that are the same in both sets are shared, not copied
+	leaves := map[int]*trieLeaf{}
+	a.(trieBitSet).forEachLeaf(func(wordIdx int, leaf *trieLeaf) { leaves[wordIdx] = leaf })
+	copied := 0
+	p.Union(a, b).(trieBitSet).forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+		if leaves[wordIdx] != leaf {
+			copied++
+		}
+	})
+	if copied > 3 {
+		t.Errorf("Expected only the leaves with changed bits to be copied, got %d", copied)
+	}
+}
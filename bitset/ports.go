@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// portWords is the number of words of a PortSet, one bit for each of the 65536 port numbers.
+const portWords = 1 << 16 / 64
+
+// noPorts is the words of an empty PortSet. It must not be modified.
+var noPorts [portWords]uint64
+
+// bitset.PortSet is an immutable set of port numbers, e.g. for firewall rules and scanners.
+//
+// It always stores all 65536 ports as a fixed 8 KiB array of words, so Test is a single lookup,
+// and operations between port sets are straight loops over the words without choosing representations.
+// The zero value is an empty set.
+type PortSet struct {
+	words *[portWords]uint64 // nil if empty - immutable, always copied on modification
+}
+
+// NewPortSet creates and returns a new bitset.PortSet with the given ports.
+func NewPortSet(ports ...uint16) PortSet {
+	if len(ports) == 0 {
+		return PortSet{}
+	}
+	words := new([portWords]uint64)
+	for _, port := range ports {
+		words[port/64] |= 1 << (port % 64)
+	}
+	return PortSet{words}
+}
+
+// PortSetOf returns a bitset.PortSet with the bits of s, or ErrOutOfRange if s has bits above 65535.
+func PortSetOf(s Set) (PortSet, error) {
+	if !isEmpty(s.Difference(Full(1 << 16))) {
+		return PortSet{}, fmt.Errorf("%w: set has bits outside [0, %d)", ErrOutOfRange, 1<<16)
+	}
+	if isEmpty(s) {
+		return PortSet{}, nil
+	}
+	words := new([portWords]uint64)
+	copy(words[:], wordsOf(s))
+	return PortSet{words}, nil
+}
+
+// ParsePorts returns a bitset.PortSet with the ports in a list like "80,443,8000-8100", in the format of
+// ParseRangeList. It returns an error wrapping ErrOutOfRange if a port is above 65535.
+func ParsePorts(list string) (PortSet, error) {
+	s, err := ParseRangeList(list)
+	if err != nil {
+		return PortSet{}, err
+	}
+	return PortSetOf(s)
+}
+
+// Bits returns the ports in p as a bitset.Set.
+func (p PortSet) Bits() Set {
+	if p.words == nil {
+		return New()
+	}
+	return fromWords(p.words[:])
+}
+
+// Test reports whether the given port is in p.
+func (p PortSet) Test(port uint16) bool {
+	return p.words != nil && p.words[port/64]&(1<<(port%64)) != 0
+}
+
+// Set returns a new bitset.PortSet with the given port added.
+// The original bitset.PortSet is not modified.
+func (p PortSet) Set(port uint16) PortSet {
+	if p.Test(port) {
+		return p
+	}
+	words := new([portWords]uint64)
+	*words = *p.all()
+	words[port/64] |= 1 << (port % 64)
+	return PortSet{words}
+}
+
+// Clear returns a new bitset.PortSet with the given port removed.
+// The original bitset.PortSet is not modified.
+func (p PortSet) Clear(port uint16) PortSet {
+	if !p.Test(port) {
+		return p
+	}
+	words := new([portWords]uint64)
+	*words = *p.words
+	words[port/64] &^= 1 << (port % 64)
+	return PortSet{words}
+}
+
+// Union returns a new bitset.PortSet with the ports that are in either p or other.
+// Neither set is modified.
+func (p PortSet) Union(other PortSet) PortSet {
+	return p.combine(other, orWords)
+}
+
+// Intersect returns a new bitset.PortSet with the ports that are in both p and other.
+// Neither set is modified.
+func (p PortSet) Intersect(other PortSet) PortSet {
+	return p.combine(other, andWords)
+}
+
+// Difference returns a new bitset.PortSet with the ports that are in p but not in other.
+// Neither set is modified.
+func (p PortSet) Difference(other PortSet) PortSet {
+	return p.combine(other, andNotWords)
+}
+
+// SymmetricDifference returns a new bitset.PortSet with the ports that are in exactly one of p and other.
+// Neither set is modified.
+func (p PortSet) SymmetricDifference(other PortSet) PortSet {
+	return p.combine(other, xorWords)
+}
+
+// Count returns the number of ports in p.
+func (p PortSet) Count() int {
+	if p.words == nil {
+		return 0
+	}
+	return popCountWords(p.words[:])
+}
+
+// Ports returns an iterator over the ports in p, in ascending order.
+func (p PortSet) Ports() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		if p.words == nil {
+			return
+		}
+		for i, w := range p.words {
+			for w != 0 {
+				if !yield(uint16(i*64 + bits.TrailingZeros64(w))) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// String returns the ports in p as a range list like "80,443,8000-8100", which ParsePorts parses back.
+func (p PortSet) String() string {
+	return FormatRangeList(p.Bits())
+}
+
+// all returns the words of p, which are all zero if p is empty. They must not be modified.
+func (p PortSet) all() *[portWords]uint64 {
+	if p.words == nil {
+		return &noPorts
+	}
+	return p.words
+}
+
+// combine returns a new bitset.PortSet with the words of p and other combined by kernel.
+func (p PortSet) combine(other PortSet, kernel wordKernel) PortSet {
+	if p.words == nil && other.words == nil {
+		return PortSet{}
+	}
+	words := new([portWords]uint64)
+	kernel(words[:], p.all()[:], other.all()[:])
+	return PortSet{words}
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPortSet(t *testing.T) {
+	var empty PortSet
+	if empty.Count() != 0 || empty.Test(80) || empty.String() != "" || empty.Bits().Count() != 0 {
+		t.Error("The zero PortSet should be empty")
+	}
+
+	p := NewPortSet(443, 80, 65535)
+	p2 := p.Set(22)
+	if !p2.Test(22) || p.Test(22) || p2.Count() != 4 {
+		t.Error("Set should return a new set with the port added, without modifying the original")
+	}
+	if p.Set(80) != p || p.Clear(21) != p {
+		t.Error("Adding a present port or removing an absent one should return the set itself")
+	}
+	if p3 := p2.Clear(65535); p3.Test(65535) || !p2.Test(65535) {
+		t.Error("Clear should return a new set with the port removed, without modifying the original")
+	}
+	if got := slices.Collect(p2.Ports()); !slices.Equal(got, []uint16{22, 80, 443, 65535}) {
+		t.Errorf("Ports = %v", got)
+	}
+	if got := p2.String(); got != "22,80,443,65535" {
+		t.Errorf("String = %q", got)
+	}
+	if got := slices.Collect(p2.Bits().Indices()); !slices.Equal(got, []uint32{22, 80, 443, 65535}) {
+		t.Errorf("Bits = %v", got)
+	}
+}
+
+func TestPortSetOperations(t *testing.T) {
+	a, b := NewPortSet(1, 2, 3, 1000), NewPortSet(3, 4, 1000, 60_000)
+	var empty PortSet
+	tests := []struct {
+		name string
+		got  PortSet
+		want []uint16
+	}{
+		{"Union", a.Union(b), []uint16{1, 2, 3, 4, 1000, 60_000}},
+		{"Intersect", a.Intersect(b), []uint16{3, 1000}},
+		{"Difference", a.Difference(b), []uint16{1, 2}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []uint16{1, 2, 4, 60_000}},
+		{"Union with empty", empty.Union(a), []uint16{1, 2, 3, 1000}},
+		{"Intersect with empty", a.Intersect(empty), nil},
+		{"empty Union empty", empty.Union(empty), nil},
+	}
+	for _, tt := range tests {
+		if got := slices.Collect(tt.got.Ports()); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, expected %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	p, err := ParsePorts("80,443,8000-8100")
+	if err != nil {
+		t.Fatalf("ParsePorts returned error: %v", err)
+	}
+	if p.Count() != 103 || !p.Test(8050) || p.Test(8101) {
+		t.Errorf("ParsePorts = %v", p)
+	}
+
+	if _, err := ParsePorts("80,65536"); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+	if _, err := ParsePorts("80-"); err == nil {
+		t.Error("Expected an error for an invalid list")
+	}
+
+	for _, ts := range testSets()[:4] {
+		p, err := PortSetOf(ts.s)
+		if err != nil || p.Bits().Key() != ts.s.Key() {
+			t.Errorf("%s: PortSetOf doesn't round trip: %v", ts.name, err)
+		}
+	}
+}
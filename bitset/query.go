@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseQuery parses a boolean query like "a AND (b OR NOT c)" into an expression over named sets,
+// e.g. a filter typed by a user.
+//
+// Names are resolved with lookup, and NOT x is the bits of universe that are not in x.
+// The operators are AND, OR, and NOT, matched case-insensitively, with NOT binding tightest and OR loosest,
+// and parentheses for grouping. Names consist of letters, digits, and any of "_-.:".
+func ParseQuery(query string, lookup func(name string) (Set, bool), universe Set) (Expr, error) {
+	p := queryParser{query: query, lookup: lookup, universe: universe}
+	p.next()
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, p.errorf("unexpected %q", p.tok)
+	}
+	return e, nil
+}
+
+type queryParser struct {
+	query    string
+	lookup   func(string) (Set, bool)
+	universe Set
+
+	pos    int    // position of the next token
+	tok    string // the current token, or "" at the end
+	tokPos int    // position of the current token
+}
+
+// next advances to the next token.
+func (p *queryParser) next() {
+	for p.pos < len(p.query) && unicode.IsSpace(rune(p.query[p.pos])) {
+		p.pos++
+	}
+
+	p.tokPos = p.pos
+	if p.pos == len(p.query) {
+		p.tok = ""
+		return
+	}
+	if c := p.query[p.pos]; c == '(' || c == ')' {
+		p.tok = p.query[p.pos : p.pos+1]
+		p.pos++
+		return
+	}
+
+	end := p.pos
+	for end < len(p.query) && isNameByte(p.query[end]) {
+		end++
+	}
+	if end == p.pos {
+		end++ // an invalid character, reported by the caller
+	}
+	p.tok = p.query[p.pos:end]
+	p.pos = end
+}
+
+func isNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("_-.:", c) >= 0
+}
+
+func (p *queryParser) is(keyword string) bool {
+	return strings.EqualFold(p.tok, keyword)
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("bitset: invalid query at position %d: %s", p.tokPos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parseOr() (Expr, error) {
+	xs, err := p.parseList("OR", p.parseAnd)
+	switch {
+	case err != nil:
+		return nil, err
+	case len(xs) == 1:
+		return xs[0], nil
+	}
+	return Or(xs...), nil
+}
+
+func (p *queryParser) parseAnd() (Expr, error) {
+	xs, err := p.parseList("AND", p.parseNot)
+	switch {
+	case err != nil:
+		return nil, err
+	case len(xs) == 1:
+		return xs[0], nil
+	}
+	return And(xs...), nil
+}
+
+// parseList parses one or more operands separated by the given keyword.
+func (p *queryParser) parseList(keyword string, parse func() (Expr, error)) ([]Expr, error) {
+	var xs []Expr
+	for {
+		x, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+		if !p.is(keyword) {
+			return xs, nil
+		}
+		p.next()
+	}
+}
+
+func (p *queryParser) parseNot() (Expr, error) {
+	if !p.is("NOT") {
+		return p.parsePrimary()
+	}
+
+	p.next()
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return AndNot(Of(p.universe), x), nil
+}
+
+func (p *queryParser) parsePrimary() (Expr, error) {
+	switch {
+	case p.tok == "":
+		return nil, p.errorf("unexpected end of query")
+	case p.tok == "(":
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, p.errorf("expected \")\"")
+		}
+		p.next()
+		return x, nil
+	case p.is("AND") || p.is("OR") || p.tok == ")" || !isNameByte(p.tok[0]):
+		return nil, p.errorf("unexpected %q", p.tok)
+	}
+
+	s, ok := p.lookup(p.tok)
+	if !ok {
+		return nil, p.errorf("unknown name %q", p.tok)
+	}
+	p.next()
+	return Of(s), nil
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	sets := map[string]Set{
+		"a":         FromIndices(1, 2, 3),
+		"b":         FromIndices(2, 3, 4),
+		"c":         FromIndices(3, 5),
+		"region:eu": FromIndices(1, 5),
+	}
+	lookup := func(name string) (Set, bool) {
+		s, ok := sets[name]
+		return s, ok
+	}
+	universe := FromIndices(0, 1, 2, 3, 4, 5)
+
+	tests := []struct {
+		query string
+		want  []uint32
+	}{
+		{"a", []uint32{1, 2, 3}},
+		{"a AND b", []uint32{2, 3}},
+		{"a or c", []uint32{1, 2, 3, 5}},
+		{"NOT a", []uint32{0, 4, 5}},
+		{"not not a", []uint32{1, 2, 3}},
+		{"a AND (b OR NOT c)", []uint32{1, 2, 3}},
+		{"a AND NOT c", []uint32{1, 2}},
+		{"a OR b AND c", []uint32{1, 2, 3}}, // AND binds tighter than OR
+		{"NOT a AND b", []uint32{4}},        // NOT binds tighter than AND
+		{" ( region:eu )\tOR\nc ", []uint32{1, 3, 5}},
+	}
+	for _, tt := range tests {
+		e, err := ParseQuery(tt.query, lookup, universe)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := slices.Collect(e.Eval().Indices()); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseQuery(%q) = %v, expected %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	lookup := func(name string) (Set, bool) {
+		return New(), name == "a"
+	}
+	tests := map[string]string{
+		"":           "unexpected end",
+		"a AND":      "unexpected end",
+		"(a":         `expected ")"`,
+		"a)":         `unexpected ")"`,
+		"a b":        `unexpected "b"`,
+		"x":          `unknown name "x"`,
+		"a AND OR a": `unexpected "OR"`,
+		"a & a":      `unexpected "&"`,
+	}
+	for query, want := range tests {
+		_, err := ParseQuery(query, lookup, New())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseQuery(%q) returned error %v, expected it to contain %q", query, err, want)
+		}
+	}
+}
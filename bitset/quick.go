@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	mathrand "math/rand"
+	"math/rand/v2"
+	"reflect"
+)
+
+// bitset.Arbitrary is a bitset.Set that implements quick.Generator, so property tests using testing/quick can take
+// random sets as arguments, e.g. quick.Check(func(a, b bitset.Arbitrary) bool { ... }, nil).
+type Arbitrary struct {
+	Set
+}
+
+// Generate returns a random bitset.Arbitrary generated by GenerateSet, as a reflect.Value for testing/quick.
+func (Arbitrary) Generate(r *mathrand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Arbitrary{GenerateSet(r, size)})
+}
+
+// GenerateSet returns a random bitset.Set for property tests, using r, e.g. to adapt other property based testing
+// frameworks. size limits the number of words in most dense sets, as in testing/quick, but sets may be sparse over
+// the whole bit index range.
+//
+// It picks the shape of the set at random, so that sets stored in every representation are covered, including edge
+// cases like the empty set, a single bit, all bits below 64 or 192, bits around the highest bit index, and densities
+// close to 0 and 1.
+func GenerateSet(r *mathrand.Rand, size int) Set {
+	src := rand.New(rand.NewPCG(r.Uint64(), r.Uint64()))
+	universe := uint32(max(1, min(size, 1<<20)) * 64)
+	switch r.Intn(11) {
+	case 0:
+		return New()
+	case 1:
+		return New().Set(src.Uint32())
+	case 2:
+		return Full(uint32(1 + r.Intn(192)))
+	case 3:
+		// Long runs, e.g. time ranges
+		var runs []run[uint32]
+		start := uint64(src.Uint32N(1 << 20))
+		for range 1 + r.Intn(8) {
+			last := start + uint64(src.Uint32N(1<<16))
+			if last > math.MaxUint32 {
+				break
+			}
+			runs = append(runs, run[uint32]{uint32(start), uint32(last)})
+			start = last + 2 + uint64(src.Uint32N(1<<16))
+		}
+		return fromRuns(runs)
+	case 4:
+		// A few bits spread over the whole range, including the highest bit index
+		s := Random(src, math.MaxUint32, float64(1+r.Intn(size+1))/math.MaxUint32)
+		if r.Intn(2) == 0 {
+			s = s.Set(math.MaxUint32)
+		}
+		return s
+	case 5:
+		// Many bits spread over a wide range, which are stored in chunks
+		return Random(src, 1<<24, float64(maxSparseLen+r.Intn(maxSparseLen))/(1<<24))
+	case 6:
+		// Enough dense words to be stored in a trie
+		return Random(src, trieMinWords*64+src.Uint32N(universe), 0.5)
+	}
+
+	// Dense sets with densities from almost empty to almost full
+	densities := []float64{0.001, 0.01, 0.5, 0.99, 0.999, src.Float64()}
+	return Random(src, 1+src.Uint32N(universe), densities[r.Intn(len(densities))])
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	mathrand "math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestGenerateSet(t *testing.T) {
+	r := mathrand.New(mathrand.NewSource(1))
+	kinds := map[Kind]bool{}
+	for range 1000 {
+		s := GenerateSet(r, 50)
+		if err := CheckInvariants(s); err != nil {
+			t.Fatal(err)
+		}
+		kinds[s.Kind()] = true
+	}
+	for _, k := range []Kind{KindSmall, KindMedium, KindDense, KindTrie, KindSparse, KindChunked, KindRuns} {
+		if !kinds[k] {
+			t.Errorf("GenerateSet never generated a %v set", k)
+		}
+	}
+}
+
+func TestArbitrary(t *testing.T) {
+	// De Morgan's law for the difference, as an example property
+	property := func(a, b, c Arbitrary) bool {
+		left := a.Difference(b.Union(c.Set))
+		right := a.Difference(b.Set).Intersect(a.Difference(c.Set))
+		return left.Key() == right.Key()
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
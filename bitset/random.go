@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// RandomBit returns a set bit of s chosen uniformly at random using r, e.g. to pick a random healthy backend,
+// or false if s is empty.
+// It finds the bit from the number of set bits in each word, run or chunk, without iterating over every bit.
+func RandomBit(s Set, r *rand.Rand) (uint32, bool) {
+	n := s.Count()
+	if n == 0 {
+		return 0, false
+	}
+	return nthBit(s, r.IntN(n)), true
+}
+
+// nthBit returns the set bit of s with the given rank, i.e. the k-th set bit counting from 0.
+// k must be less than s.Count().
+func nthBit(s Set, k int) uint32 {
+	switch s := materialized(s).(type) {
+	case sparseBitSet:
+		return s[k]
+	case runBitSet:
+		for _, r := range s {
+			length := uint64(r.last-r.start) + 1
+			if uint64(k) < length {
+				return r.start + uint32(k)
+			}
+			k -= int(length)
+		}
+	case chunkedBitSet:
+		for _, ch := range s.chunks {
+			if c := ch.c.card(); k >= c {
+				k -= c
+				continue
+			}
+			var bit uint32
+			ch.c.each(uint32(ch.key)<<16, func(i uint32) bool {
+				bit = i
+				k--
+				return k >= 0
+			})
+			return bit
+		}
+	case trieBitSet:
+		var bit uint32
+		s.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+			if k >= 0 {
+				bit, k = nthBitInWords(leaf[:], uint32(wordIdx*64), k)
+			}
+		})
+		return bit
+	}
+
+	words, _ := denseWords(s)
+	bit, _ := nthBitInWords(words, 0, k)
+	return bit
+}
+
+// nthBitInWords returns the set bit with rank k in words, where bit j of words[i] is the bit index base+i*64+j, and -1.
+// If words has no more than k set bits, it returns k minus that number instead, to continue with the following words.
+func nthBitInWords(words []uint64, base uint32, k int) (uint32, int) {
+	for i, w := range words {
+		if c := bits.OnesCount64(w); k >= c {
+			k -= c
+			continue
+		}
+		for range k {
+			w &= w - 1
+		}
+		return base + uint32(i*64+bits.TrailingZeros64(w)), -1
+	}
+	return 0, k
+}
+
+// Random returns a bitset.Set where each bit in [0, universe) is set independently with probability density,
+// using r, so the same seed always gives the same set, e.g. for tests and benchmarks.
+// It panics if density is not in [0, 1].
+//
+// Sparse sets are built from the geometrically distributed gaps between their bits, and dense ones a word at a time
+// from a few random words, with the density rounded to a multiple of 1/65536.
+func Random(r *rand.Rand, universe uint32, density float64) Set {
+	switch {
+	case !(density >= 0 && density <= 1):
+		panic(fmt.Sprintf("bitset: density %v is not in [0, 1]", density))
+	case density == 0 || universe == 0:
+		return bitSet64(0)
+	case density == 1:
+		return Full(universe)
+	}
+
+	if density < 1.0/16 {
+		var indices []uint32
+		logMiss := math.Log1p(-density)
+		for i := int64(-1); ; {
+			// The number of clear bits before the next set bit, using 1-r.Float64() to avoid log(0)
+			gap := math.Log(1-r.Float64()) / logMiss
+			if gap >= float64(int64(universe)-i-1) {
+				break
+			}
+			i += 1 + int64(gap)
+			indices = append(indices, uint32(i))
+		}
+		return fromIndices(indices)
+	}
+
+	// Combining random words with | and & for the binary digits of the density from the lowest one up
+	// gives each bit the probability (digit + previous)/2, which ends up as the density
+	m := uint32(math.Round(density * (1 << 16)))
+	if m == 1<<16 {
+		return Full(universe)
+	}
+	words := make([]uint64, (uint64(universe)+63)/64)
+	for i := range words {
+		var w uint64
+		for k := bits.TrailingZeros32(m); k < 16; k++ {
+			if m>>k&1 != 0 {
+				w |= r.Uint64()
+			} else {
+				w &= r.Uint64()
+			}
+		}
+		words[i] = w
+	}
+	if rem := universe % 64; rem != 0 {
+		words[len(words)-1] &= 1<<rem - 1
+	}
+	return fromWords(words)
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestNthBit(t *testing.T) {
+	for _, ts := range testSets() {
+		indices := slices.Collect(ts.s.Indices())
+		for _, k := range []int{0, 1, len(indices) / 3, len(indices) / 2, len(indices) - 1} {
+			if k < 0 || k >= len(indices) {
+				continue
+			}
+			if got := nthBit(ts.s, k); got != indices[k] {
+				t.Errorf("%s: nthBit(%d) = %d, want %d", ts.name, k, got, indices[k])
+			}
+		}
+	}
+}
+
+func TestRandomBit(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	if _, ok := RandomBit(New(), r); ok {
+		t.Error("RandomBit of an empty set should return false")
+	}
+
+	s := FromIndices(3, 70, 200, 5000)
+	counts := map[uint32]int{}
+	for range 4000 {
+		i, ok := RandomBit(s, r)
+		if !ok || !s.Test(i) {
+			t.Fatalf("RandomBit returned %d, %v, which is not in the set", i, ok)
+		}
+		counts[i]++
+	}
+	for i := range s.Indices() {
+		if counts[i] < 800 || counts[i] > 1200 {
+			t.Errorf("Bit %d was picked %d out of 4000 times, want about 1000", i, counts[i])
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	for _, universe := range []uint32{0, 1, 100, 10_000, 1 << 20} {
+		for _, density := range []float64{0, 0.001, 0.05, 0.3, 0.5, 0.9, 0.99999, 1} {
+			s := Random(rand.New(rand.NewPCG(1, 2)), universe, density)
+			if err := CheckInvariants(s); err != nil {
+				t.Fatalf("Random(%d, %v): %v", universe, density, err)
+			}
+			if universe > 0 && s.Test(universe) || s.Count() > int(universe) {
+				t.Fatalf("Random(%d, %v) has bits outside the universe", universe, density)
+			}
+
+			// The count is binomial, so allow 5 standard deviations
+			mean := float64(universe) * density
+			if sd := math.Sqrt(mean * (1 - density)); math.Abs(float64(s.Count())-mean) > 5*sd+1 {
+				t.Errorf("Random(%d, %v) has %d bits, expected about %v", universe, density, s.Count(), mean)
+			}
+
+			if again := Random(rand.New(rand.NewPCG(1, 2)), universe, density); again.Key() != s.Key() {
+				t.Errorf("Random(%d, %v) should be reproducible from the seed", universe, density)
+			}
+		}
+	}
+
+	for _, density := range []float64{-0.1, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Random with density %v should panic", density)
+				}
+			}()
+			Random(rand.New(rand.NewPCG(1, 2)), 100, density)
+		}()
+	}
+}
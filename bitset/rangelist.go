@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseRangeList returns a bitset.Set with the bits in a range list like "1,3,5-10", i.e. comma separated bit indices
+// and inclusive ranges, as used by cpusets and taskset, e.g. for command line flags and config files.
+// Ranges may overlap and be in any order. Surrounding whitespace is ignored, and an empty list is an empty set.
+// Ranges are stored as runs, so a list like "0-4000000000" is parsed without setting its bits one by one.
+func ParseRangeList(list string) (Set, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return New(), nil
+	}
+
+	var ranges []Range
+	for part := range strings.SplitSeq(list, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.ParseUint(first, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bitset: invalid range list %q: %w", list, err)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.ParseUint(last, 10, 32); err != nil {
+				return nil, fmt.Errorf("bitset: invalid range list %q: %w", list, err)
+			}
+			if hi < lo {
+				return nil, fmt.Errorf("bitset: invalid range list %q: range %s is reversed", list, part)
+			}
+		}
+		ranges = append(ranges, Range{lo, hi + 1})
+	}
+	return NewRangeSet(ranges...).Bits(), nil
+}
+
+// FormatRangeList returns the bits of s as a range list, using inclusive ranges for consecutive bits, e.g. "1,3,5-10".
+// It returns an empty string for an empty set. ParseRangeList parses it back.
+func FormatRangeList(s Set) string {
+	var sb strings.Builder
+	for _, r := range runsOf(s) {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(r.start), 10))
+		if r.last != r.start {
+			sb.WriteByte('-')
+			sb.WriteString(strconv.FormatUint(uint64(r.last), 10))
+		}
+	}
+	return sb.String()
+}
+
+// bitset.RangeList is a Set that is marshaled as a range list, as described by FormatRangeList,
+// for embedding sets in config files without a string field and manual parsing.
+//
+// It implements encoding.TextMarshaler and encoding.TextUnmarshaler, so it is a string in JSON and other text formats,
+// and the Marshaler and Unmarshaler interfaces of gopkg.in/yaml.v3 (the obsolete ones, which it still supports,
+// to avoid depending on it). In YAML, it can also be a sequence of bit indices and ranges, e.g. [1, 3, 5-10].
+// The zero value marshals as an empty set, but its Set is nil, so use Bits for a field that may not have been set.
+type RangeList struct {
+	Set
+}
+
+// Bits returns the bits of r as a bitset.Set, which is empty if r.Set is nil.
+func (r RangeList) Bits() Set {
+	return orEmpty(r.Set)
+}
+
+// String returns the bits of r as a range list.
+func (r RangeList) String() string {
+	return FormatRangeList(r.Bits())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r RangeList) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, using ParseRangeList.
+func (r *RangeList) UnmarshalText(text []byte) error {
+	s, err := ParseRangeList(string(text))
+	if err != nil {
+		return err
+	}
+	r.Set = s
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface of gopkg.in/yaml.v3 and v2.
+func (r RangeList) MarshalYAML() (any, error) {
+	return r.String(), nil
+}
+
+// UnmarshalYAML implements the obsolete yaml.Unmarshaler interface of gopkg.in/yaml.v3, and that of v2.
+func (r *RangeList) UnmarshalYAML(unmarshal func(any) error) error {
+	var list string
+	if err := unmarshal(&list); err != nil {
+		var parts []string
+		if unmarshal(&parts) != nil {
+			return err
+		}
+		list = strings.Join(parts, ",")
+	}
+	return r.UnmarshalText([]byte(list))
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseRangeList(t *testing.T) {
+	tests := []struct {
+		list string
+		want []uint32
+	}{
+		{"", nil},
+		{" \n", nil},
+		{"0", []uint32{0}},
+		{"1,3,5-10\n", []uint32{1, 3, 5, 6, 7, 8, 9, 10}},
+		{"8,0-1,1,2", []uint32{0, 1, 2, 8}},
+		{"4294967295", []uint32{math.MaxUint32}},
+	}
+	for _, tt := range tests {
+		s, err := ParseRangeList(tt.list)
+		if err != nil {
+			t.Errorf("ParseRangeList(%q) returned error: %v", tt.list, err)
+			continue
+		}
+		if got := slices.Collect(s.Indices()); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseRangeList(%q) = %v, expected %v", tt.list, got, tt.want)
+		}
+		checkCanonical(t, s)
+	}
+
+	s, err := ParseRangeList("0-4294967295")
+	if err != nil || s.Kind() != KindRuns || !s.Test(math.MaxUint32) {
+		t.Errorf("ParseRangeList of the full range = %v, %v", s.Kind(), err)
+	}
+
+	for _, list := range []string{"a", "1,", "3-1", "-2", "1-2-3", "0x1", "4294967296", "1 ,2"} {
+		if _, err := ParseRangeList(list); err == nil {
+			t.Errorf("Expected an error for %q", list)
+		}
+	}
+}
+
+func TestFormatRangeList(t *testing.T) {
+	tests := []struct {
+		bits []uint32
+		want string
+	}{
+		{nil, ""},
+		{[]uint32{5}, "5"},
+		{[]uint32{1, 3, 5, 6, 7, 8, 9, 10}, "1,3,5-10"},
+		{[]uint32{0, 2, 3, 100, 101, 102, math.MaxUint32}, "0,2-3,100-102,4294967295"},
+	}
+	for _, tt := range tests {
+		if got := FormatRangeList(FromIndices(tt.bits...)); got != tt.want {
+			t.Errorf("FormatRangeList(%v) = %q, expected %q", tt.bits, got, tt.want)
+		}
+	}
+
+	for _, ts := range testSets() {
+		s, err := ParseRangeList(FormatRangeList(ts.s))
+		if err != nil || s.Key() != ts.s.Key() {
+			t.Errorf("%s: range list doesn't round trip: %v", ts.name, err)
+		}
+	}
+}
+
+func TestRangeListJSON(t *testing.T) {
+	type config struct {
+		CPUs RangeList `json:"cpus"`
+		None RangeList `json:"none"`
+	}
+
+	data, err := json.Marshal(config{CPUs: RangeList{FromIndices(0, 1, 2, 3, 8)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"cpus":"0-3,8","none":""}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.CPUs.Key() != FromIndices(0, 1, 2, 3, 8).Key() || !isEmpty(c.None.Bits()) {
+		t.Errorf("Unexpected bits after a round trip: %v, %v", c.CPUs, c.None)
+	}
+	if err := json.Unmarshal([]byte(`{"cpus":"3-1"}`), &c); err == nil {
+		t.Error("Expected an error for a reversed range")
+	}
+}
+
+func TestRangeListYAML(t *testing.T) {
+	if v, err := (RangeList{FromIndices(1, 5, 6)}).MarshalYAML(); v != "1,5-6" || err != nil {
+		t.Errorf("Expected 1,5-6, got %v, %v", v, err)
+	}
+
+	// unmarshalYAML returns the unmarshal function yaml passes for a node with the given value
+	unmarshalYAML := func(node any) func(any) error {
+		return func(out any) error {
+			switch out := out.(type) {
+			case *string:
+				if s, ok := node.(string); ok {
+					*out = s
+					return nil
+				}
+			case *[]string:
+				if s, ok := node.([]string); ok {
+					*out = s
+					return nil
+				}
+			}
+			return fmt.Errorf("cannot unmarshal %T into %T", node, out)
+		}
+	}
+	tests := []struct {
+		node any
+		want string
+	}{
+		{"0-3,8", "0-3,8"},
+		{[]string{"22", "80", "8000-8080"}, "22,80,8000-8080"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		var r RangeList
+		if err := r.UnmarshalYAML(unmarshalYAML(tt.node)); err != nil || r.String() != tt.want {
+			t.Errorf("UnmarshalYAML(%v) = %v, %v, expected %s", tt.node, r, err, tt.want)
+		}
+	}
+
+	var r RangeList
+	if err := r.UnmarshalYAML(unmarshalYAML(map[string]int{})); err == nil {
+		t.Error("Expected an error for a mapping")
+	}
+}
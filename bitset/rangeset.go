@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// bitset.Range is the half-open range of bit indices [Start, End). End is at most 1<<32.
+type Range struct {
+	Start, End uint64
+}
+
+// bitset.RangeSet is an immutable set of bit indices stored as maximal ranges rather than individual bits,
+// for data that is mostly long runs, e.g. reserved ID blocks or time windows.
+// Its size depends only on the number of ranges, however many bits they cover.
+// The zero value is an empty set.
+type RangeSet struct {
+	runs []run[uint32] // sorted, non-overlapping and non-adjacent - immutable, always copied on modification
+}
+
+// NewRangeSet creates and returns a new bitset.RangeSet with the bits in the given ranges,
+// which may overlap and be in any order. Empty ranges are ignored.
+// It panics if a range has Start > End, or End > 1<<32.
+func NewRangeSet(ranges ...Range) RangeSet {
+	runs := make([]run[uint32], 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start > r.End || r.End > 1<<32 {
+			panic(fmt.Sprintf("bitset: invalid range [%d, %d)", r.Start, r.End))
+		}
+		if r.Start < r.End {
+			runs = append(runs, run[uint32]{uint32(r.Start), uint32(r.End - 1)})
+		}
+	}
+	slices.SortFunc(runs, func(a, b run[uint32]) int {
+		return cmp.Compare(a.start, b.start)
+	})
+
+	merged := runs[:0]
+	for _, r := range runs {
+		if n := len(merged); n > 0 && uint64(merged[n-1].last)+1 >= uint64(r.start) {
+			merged[n-1].last = max(merged[n-1].last, r.last)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return RangeSet{slices.Clip(merged)}
+}
+
+// RangeSetOf returns a bitset.RangeSet with the bits of s.
+func RangeSetOf(s Set) RangeSet {
+	return RangeSet{runsOf(s)}
+}
+
+// Bits returns the bits of r as a bitset.Set.
+func (r RangeSet) Bits() Set {
+	return fromRuns(r.runs)
+}
+
+// Test reports whether the bit for the given bit index is in r.
+func (r RangeSet) Test(bitIndex uint32) bool {
+	_, found := searchRuns(r.runs, bitIndex)
+	return found
+}
+
+// Len returns the number of maximal ranges in r.
+func (r RangeSet) Len() int {
+	return len(r.runs)
+}
+
+// Count returns the number of bits in r.
+func (r RangeSet) Count() int {
+	return runBitSet(r.runs).Count()
+}
+
+// Ranges returns an iterator over the maximal ranges in r, in ascending order.
+func (r RangeSet) Ranges() iter.Seq[Range] {
+	return func(yield func(Range) bool) {
+		for _, rn := range r.runs {
+			if !yield(Range{uint64(rn.start), uint64(rn.last) + 1}) {
+				return
+			}
+		}
+	}
+}
+
+// Add returns a new bitset.RangeSet with the bits in rng added.
+// It panics if rng has Start > End, or End > 1<<32.
+// The original bitset.RangeSet is not modified.
+func (r RangeSet) Add(rng Range) RangeSet {
+	return r.Union(NewRangeSet(rng))
+}
+
+// Remove returns a new bitset.RangeSet with the bits in rng removed.
+// It panics if rng has Start > End, or End > 1<<32.
+// The original bitset.RangeSet is not modified.
+func (r RangeSet) Remove(rng Range) RangeSet {
+	return r.Difference(NewRangeSet(rng))
+}
+
+// Union returns a new bitset.RangeSet with the bits that are in either r or other.
+// Neither set is modified.
+func (r RangeSet) Union(other RangeSet) RangeSet {
+	return RangeSet{combineRuns(r.runs, other.runs, or)}
+}
+
+// Intersect returns a new bitset.RangeSet with the bits that are in both r and other.
+// Neither set is modified.
+func (r RangeSet) Intersect(other RangeSet) RangeSet {
+	return RangeSet{combineRuns(r.runs, other.runs, and)}
+}
+
+// Difference returns a new bitset.RangeSet with the bits that are in r but not in other.
+// Neither set is modified.
+func (r RangeSet) Difference(other RangeSet) RangeSet {
+	return RangeSet{combineRuns(r.runs, other.runs, andNot)}
+}
+
+// SymmetricDifference returns a new bitset.RangeSet with the bits that are in exactly one of r and other.
+// Neither set is modified.
+func (r RangeSet) SymmetricDifference(other RangeSet) RangeSet {
+	return RangeSet{combineRuns(r.runs, other.runs, xor)}
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewRangeSet(t *testing.T) {
+	r := NewRangeSet(Range{10, 20}, Range{0, 5}, Range{15, 30}, Range{5, 6}, Range{40, 40})
+	want := []Range{{0, 6}, {10, 30}}
+	if got := slices.Collect(r.Ranges()); !slices.Equal(got, want) {
+		t.Errorf("Expected ranges %v, got %v", want, got)
+	}
+	if r.Len() != 2 || r.Count() != 26 {
+		t.Errorf("Expected 2 ranges and 26 bits, got %d and %d", r.Len(), r.Count())
+	}
+	if !r.Test(0) || !r.Test(29) || r.Test(30) || r.Test(7) {
+		t.Error("RangeSet has incorrect bits")
+	}
+
+	full := NewRangeSet(Range{0, 1 << 32})
+	if full.Len() != 1 || !full.Test(0) || !full.Test(0xffffffff) {
+		t.Error("Expected a range covering every bit index")
+	}
+
+	var zero RangeSet
+	if zero.Len() != 0 || zero.Test(0) || zero.Bits().Count() != 0 {
+		t.Error("Zero RangeSet should be empty")
+	}
+
+	for _, rng := range []Range{{5, 4}, {0, 1<<32 + 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewRangeSet(%v) should panic", rng)
+				}
+			}()
+			NewRangeSet(rng)
+		}()
+	}
+}
+
+func TestRangeSetOps(t *testing.T) {
+	a := NewRangeSet(Range{0, 100}, Range{200, 300})
+	b := NewRangeSet(Range{50, 250})
+
+	tests := []struct {
+		name string
+		got  RangeSet
+		want []Range
+	}{
+		{"Union", a.Union(b), []Range{{0, 300}}},
+		{"Intersect", a.Intersect(b), []Range{{50, 100}, {200, 250}}},
+		{"Difference", a.Difference(b), []Range{{0, 50}, {250, 300}}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []Range{{0, 50}, {100, 200}, {250, 300}}},
+		{"Add", a.Add(Range{100, 200}), []Range{{0, 300}}},
+		{"Remove", a.Remove(Range{10, 20}), []Range{{0, 10}, {20, 100}, {200, 300}}},
+	}
+	for _, tt := range tests {
+		if got := slices.Collect(tt.got.Ranges()); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+	if a.Len() != 2 {
+		t.Error("Operations should not modify the original set")
+	}
+}
+
+func TestRangeSetBits(t *testing.T) {
+	for _, ts := range testSets() {
+		r := RangeSetOf(ts.s)
+		got := r.Bits()
+		checkCanonical(t, got)
+		if got.Key() != ts.s.Key() || r.Count() != ts.s.Count() {
+			t.Errorf("%s: set does not round trip", ts.name)
+		}
+	}
+
+	r := NewRangeSet(Range{1 << 20, 1 << 30})
+	if s := r.Bits(); s.Kind() != KindRuns || s.Count() != 1<<30-1<<20 {
+		t.Errorf("Expected a run set, got %v", s.Kind())
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+const (
+	rankBlockWords  = 8                        // words per block, i.e. 512 bits
+	rankSuperBlocks = 128                      // blocks per superblock, i.e. 65536 bits
+	selectSample    = rankBlockWords * 64 * 16 // set bits between select samples
+)
+
+// bitset.RankIndex answers rank queries on the bits of a Set in constant time, and select queries in close to it,
+// e.g. to map between the positions of non-null values in a sparse column and their indices in a dense one.
+//
+// It is built once from a Set, and only stores its nonzero words, along with the index of each word if most words
+// are zero, and counts of the set bits before every block of 8 stored words, which take about 3% more memory than
+// the words themselves. Select starts from a sample taken every 8192 set bits, and binary searches the blocks
+// up to the next sample, so a few long stretches of clear bits don't slow it down.
+type RankIndex struct {
+	words    []uint64 // the nonzero words, or all the words if keys is nil - never modified
+	keys     []uint32 // the word index of each word in words, or nil if words has every word up to the last nonzero one
+	dir      []uint32 // the position in keys of the first word at or after every 1<<dirShift-th word index
+	dirShift int
+	supers   []uint64 // set bits before each superblock
+	blocks   []uint16 // set bits before each block, from the start of its superblock
+	samples  []uint32 // the block holding every selectSample-th set bit
+	n        int
+}
+
+// NewRankIndex creates and returns a new bitset.RankIndex for the bits of s.
+func NewRankIndex(s Set) *RankIndex {
+	idx := &RankIndex{}
+	if words, ok := denseWords(s); ok && !slices.Contains(words, 0) {
+		idx.words = words
+	} else {
+		idx.fromWordList(nonzeroWords(s))
+	}
+
+	words := idx.words
+	nBlocks := (len(words) + rankBlockWords - 1) / rankBlockWords
+	idx.supers = make([]uint64, 0, (nBlocks+rankSuperBlocks-1)/rankSuperBlocks)
+	idx.blocks = make([]uint16, nBlocks)
+	total := 0
+	for b := range nBlocks {
+		if b%rankSuperBlocks == 0 {
+			idx.supers = append(idx.supers, uint64(total))
+		}
+		idx.blocks[b] = uint16(total - int(idx.supers[len(idx.supers)-1]))
+
+		total += popCount(words[b*rankBlockWords : min((b+1)*rankBlockWords, len(words))])
+		for len(idx.samples)*selectSample < total {
+			idx.samples = append(idx.samples, uint32(b))
+		}
+	}
+	idx.n = total
+	return idx
+}
+
+// fromWordList sets the words of idx to the given nonzero words, storing their indices only if most words are zero,
+// and builds a directory of the word indices with about one word per entry.
+func (idx *RankIndex) fromWordList(list []indexedWord) {
+	if len(list) == 0 {
+		return
+	}
+	span := list[len(list)-1].i + 1
+	if 3*len(list) >= 2*span {
+		// Storing the zero words takes less memory than storing the index of every word
+		idx.words = make([]uint64, span)
+		for _, iw := range list {
+			idx.words[iw.i] = iw.w
+		}
+		return
+	}
+
+	idx.words = make([]uint64, len(list))
+	idx.keys = make([]uint32, len(list))
+	for p, iw := range list {
+		idx.words[p], idx.keys[p] = iw.w, uint32(iw.i)
+	}
+	idx.dirShift = bits.Len(uint(span / len(list)))
+	idx.dir = make([]uint32, (span-1)>>idx.dirShift+2)
+	p := 0
+	for d := range idx.dir {
+		for p < len(idx.keys) && int(idx.keys[p]) < d<<idx.dirShift {
+			p++
+		}
+		idx.dir[d] = uint32(p)
+	}
+}
+
+// Count returns the number of set bits.
+func (idx *RankIndex) Count() int {
+	return idx.n
+}
+
+// Test reports whether the bit for the given bit index is set.
+func (idx *RankIndex) Test(bitIndex uint32) bool {
+	p, found := idx.find(int(bitIndex / 64))
+	return found && idx.words[p]&(1<<(bitIndex%64)) != 0
+}
+
+// Rank returns the number of set bits with an index less than bitIndex.
+func (idx *RankIndex) Rank(bitIndex uint32) int {
+	p, found := idx.find(int(bitIndex / 64))
+	if p >= len(idx.words) {
+		return idx.n
+	}
+
+	b := p / rankBlockWords
+	r := idx.blockRank(b)
+	for _, x := range idx.words[b*rankBlockWords : p] {
+		r += bits.OnesCount64(x)
+	}
+	if found {
+		r += bits.OnesCount64(idx.words[p] & (1<<(bitIndex%64) - 1))
+	}
+	return r
+}
+
+// Select returns the index of the set bit with the given rank, i.e. the k-th set bit counting from 0,
+// or false if there are no more than k set bits.
+func (idx *RankIndex) Select(k int) (uint32, bool) {
+	if k < 0 || k >= idx.n {
+		return 0, false
+	}
+
+	// The block holding bit k is between the samples before and after it
+	lo, hi := int(idx.samples[k/selectSample]), len(idx.blocks)
+	if next := k/selectSample + 1; next < len(idx.samples) {
+		hi = int(idx.samples[next]) + 1
+	}
+	b := lo + sort.Search(hi-lo, func(i int) bool { return idx.blockRank(lo+i) > k }) - 1
+
+	r := idx.blockRank(b)
+	for p := b * rankBlockWords; ; p++ {
+		x := idx.words[p]
+		if c := bits.OnesCount64(x); r+c <= k {
+			r += c
+			continue
+		}
+		w := p
+		if idx.keys != nil {
+			w = int(idx.keys[p])
+		}
+		return uint32(w*64 + selectInWord(x, k-r)), true
+	}
+}
+
+// find returns the position in idx.words of the first word with an index of at least w,
+// and whether it is word w itself.
+func (idx *RankIndex) find(w int) (int, bool) {
+	if idx.keys == nil {
+		return min(w, len(idx.words)), w < len(idx.words)
+	}
+
+	d := w >> idx.dirShift
+	if d >= len(idx.dir)-1 {
+		return len(idx.keys), false
+	}
+	lo, hi := int(idx.dir[d]), int(idx.dir[d+1])
+	p, found := slices.BinarySearch(idx.keys[lo:hi], uint32(w))
+	return lo + p, found
+}
+
+// blockRank returns the number of set bits before block b.
+func (idx *RankIndex) blockRank(b int) int {
+	return int(idx.supers[b/rankSuperBlocks]) + int(idx.blocks[b])
+}
+
+// selectInWord returns the position of the set bit with rank r in x, which has more than r set bits.
+// It narrows down the byte holding the bit by counting the bits in halves of x, and only clears the bits below it
+// in that byte one by one.
+func selectInWord(x uint64, r int) int {
+	pos := 0
+	for width := 32; width >= 8; width /= 2 {
+		if c := bits.OnesCount64(x & (1<<width - 1)); c <= r {
+			r -= c
+			x >>= width
+			pos += width
+		}
+	}
+	for range r {
+		x &= x - 1
+	}
+	return pos + bits.TrailingZeros64(x)
+}
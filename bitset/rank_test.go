@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"testing"
+)
+
+func TestRankIndex(t *testing.T) {
+	idx := NewRankIndex(FromIndices(3, 64, 65, 1000))
+	tests := []struct {
+		i    uint32
+		want int
+	}{
+		{0, 0}, {3, 0}, {4, 1}, {64, 1}, {65, 2}, {66, 3}, {1000, 3}, {1001, 4}, {1 << 31, 4},
+	}
+	for _, tt := range tests {
+		if got := idx.Rank(tt.i); got != tt.want {
+			t.Errorf("Rank(%d) = %d, expected %d", tt.i, got, tt.want)
+		}
+	}
+
+	for k, want := range []uint32{3, 64, 65, 1000} {
+		if got, ok := idx.Select(k); !ok || got != want {
+			t.Errorf("Select(%d) = %d, expected %d", k, got, want)
+		}
+	}
+	if _, ok := idx.Select(4); ok {
+		t.Error("Select should fail past the last set bit")
+	}
+	if _, ok := idx.Select(-1); ok {
+		t.Error("Select should fail for a negative rank")
+	}
+	if idx.Count() != 4 || !idx.Test(65) || idx.Test(66) {
+		t.Error("RankIndex has incorrect bits")
+	}
+
+	empty := NewRankIndex(New())
+	if empty.Rank(100) != 0 || empty.Count() != 0 {
+		t.Error("Empty index should have no bits")
+	}
+	if _, ok := empty.Select(0); ok {
+		t.Error("Select on an empty index should fail")
+	}
+}
+
+func TestRankIndexLarge(t *testing.T) {
+	// Dense and sparse regions spanning several superblocks
+	r := rand.New(rand.NewPCG(1, 2))
+	b := NewBuilder(0)
+	for i := range uint32(300_000) {
+		if i < 100_000 && r.IntN(2) == 0 || r.IntN(500) == 0 {
+			b = b.With(i)
+		}
+	}
+	s := b.Build()
+	idx := NewRankIndex(s)
+
+	rank := 0
+	for i := range uint32(300_001) {
+		if got := idx.Rank(i); got != rank {
+			t.Fatalf("Rank(%d) = %d, expected %d", i, got, rank)
+		}
+		if s.Test(i) {
+			if got, ok := idx.Select(rank); !ok || got != i {
+				t.Fatalf("Select(%d) = %d, expected %d", rank, got, i)
+			}
+			rank++
+		}
+	}
+	if idx.Count() != s.Count() {
+		t.Errorf("Expected %d bits, got %d", s.Count(), idx.Count())
+	}
+}
+
+func TestRankIndexAllSets(t *testing.T) {
+	for _, ts := range testSets() {
+		idx := NewRankIndex(ts.s)
+		k := 0
+		for i := range ts.s.Indices() {
+			if got := idx.Rank(i); got != k {
+				t.Fatalf("%s: Rank(%d) = %d, expected %d", ts.name, i, got, k)
+			}
+			if got, _ := idx.Select(k); got != i {
+				t.Fatalf("%s: Select(%d) = %d, expected %d", ts.name, k, got, i)
+			}
+			k++
+		}
+	}
+}
+
+func TestRankIndexWideSparse(t *testing.T) {
+	// A few bits spread over the whole index space only stores their words
+	s := FromIndices(5, 1<<20, 1<<20+70, 3_000_000_000, math.MaxUint32)
+	var idx *RankIndex
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	idx = NewRankIndex(s)
+	runtime.ReadMemStats(&after)
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 1<<12 {
+		t.Errorf("Expected the index to only store the nonzero words, allocated %d bytes", alloc)
+	}
+
+	k := 0
+	for i := range s.Indices() {
+		if got := idx.Rank(i); got != k {
+			t.Errorf("Rank(%d) = %d, expected %d", i, got, k)
+		}
+		if got, ok := idx.Select(k); !ok || got != i {
+			t.Errorf("Select(%d) = %d, expected %d", k, got, i)
+		}
+		if !idx.Test(i) || idx.Test(i-1) {
+			t.Errorf("Test(%d) is incorrect", i)
+		}
+		k++
+	}
+	if idx.Rank(2_000_000_000) != 3 || idx.Rank(math.MaxUint32) != 4 {
+		t.Error("Rank between the bits is incorrect")
+	}
+}
+
+func TestSelectInWord(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 1000 {
+		x := r.Uint64() & r.Uint64()
+		k := 0
+		for i := range 64 {
+			if x&(1<<i) == 0 {
+				continue
+			}
+			if got := selectInWord(x, k); got != i {
+				t.Fatalf("selectInWord(%#x, %d) = %d, expected %d", x, k, got, i)
+			}
+			k++
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// EncodeRedis returns the bits of s in the layout of a Redis bitmap string, as used by SETBIT, GETBIT and BITCOUNT:
+// bit i is stored in byte i/8, with bit 0 of each byte in its most significant bit.
+// The result ends at the byte with the highest set bit, so it is empty for an empty set.
+func EncodeRedis(s Set) []byte {
+	words := AppendWords(nil, s)
+	if len(words) == 0 {
+		return []byte{}
+	}
+
+	n := (len(words)-1)*8 + (bits.Len64(words[len(words)-1])+7)/8
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = bits.Reverse8(byte(words[i/8] >> (8 * (i % 8))))
+	}
+	return data
+}
+
+// DecodeRedis returns a bitset.Set with the bits of a Redis bitmap string in the layout described by EncodeRedis,
+// e.g. the result of GET on a key written with SETBIT.
+func DecodeRedis(data []byte) Set {
+	words := make([]uint64, (len(data)+7)/8)
+	for i, b := range data {
+		words[i/8] |= uint64(bits.Reverse8(b)) << (8 * (i % 8))
+	}
+	return fromWords(words)
+}
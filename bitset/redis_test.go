@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeRedis(t *testing.T) {
+	// SETBIT key 0 1, SETBIT key 9 1, SETBIT key 71 1
+	got := EncodeRedis(FromIndices(0, 9, 71))
+	want := []byte{0x80, 0x40, 0, 0, 0, 0, 0, 0, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected % x, got % x", want, got)
+	}
+
+	if got := EncodeRedis(New()); len(got) != 0 {
+		t.Errorf("Expected no bytes for an empty set, got % x", got)
+	}
+}
+
+func TestDecodeRedis(t *testing.T) {
+	s := DecodeRedis([]byte{0x80, 0x40, 0, 0, 0, 0, 0, 0, 0x01, 0, 0})
+	if s.Count() != 3 || !s.Test(0) || !s.Test(9) || !s.Test(71) {
+		t.Errorf("Decoded set has incorrect bits: %v", s)
+	}
+
+	for _, ts := range testSets() {
+		if got := DecodeRedis(EncodeRedis(ts.s)); got.Key() != ts.s.Key() {
+			t.Errorf("%s: set does not round trip, got %T", ts.name, got)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"iter"
+	"unsafe"
+)
+
+// ShrinkPolicy controls how a set gives back memory when its highest bits are cleared.
+//
+// By default (ShrinkAlways) Clear trims trailing zero words and switches to a smaller representation as soon as
+// possible, which causes a lot of reallocation when the same high bit is repeatedly set and cleared.
+// Any other policy keeps the set as plain words that are only trimmed according to the policy.
+//
+// A positive policy is the number of trailing zero words a set may keep before they are trimmed, so a set only
+// shrinks once its highest set bit drops that many words below the largest size it has had since it last shrank.
+type ShrinkPolicy int
+
+const (
+	// ShrinkAlways trims trailing zero words and downgrades the representation on every Clear. This is the default.
+	ShrinkAlways ShrinkPolicy = 0
+
+	// ShrinkNever never trims trailing zero words, so a set keeps the largest size it ever had.
+	ShrinkNever ShrinkPolicy = -1
+)
+
+// NewWithShrinkPolicy creates and returns a new empty bitset.Set that shrinks according to the given policy.
+// Sets derived from it using Set, Clear, or the boolean operations keep the same policy.
+func NewWithShrinkPolicy(policy ShrinkPolicy) Set {
+	return WithShrinkPolicy(New(), policy)
+}
+
+// WithShrinkPolicy returns a bitset.Set with the same bits as s that shrinks according to the given policy.
+// Passing ShrinkAlways returns s to the default behavior.
+// The original bitset.Set is not modified.
+func WithShrinkPolicy(s Set, policy ShrinkPolicy) Set {
+	if b, ok := s.(retainedBitSet); ok {
+		s = fromWords(b.words)
+	}
+	if policy == ShrinkAlways {
+		return s
+	}
+
+	return retainedBitSet{words: wordsOf(s), policy: policy}
+}
+
+// trim returns the number of words to keep for a set that has capacity words, of which only the first used are nonzero.
+func (p ShrinkPolicy) trim(capacity, used int) int {
+	if p < 0 || capacity-used <= int(p) {
+		return capacity
+	}
+	return used
+}
+
+// Retained (plain words, trimmed according to a ShrinkPolicy other than ShrinkAlways)
+type retainedBitSet struct {
+	words  []uint64 // immutable - always copied on modification. May have trailing zero words
+	policy ShrinkPolicy
+}
+
+func (b retainedBitSet) Test(bitIndex uint32) bool {
+	idx := int(bitIndex / 64)
+	if idx >= len(b.words) {
+		return false
+	}
+
+	return b.words[idx]&(1<<(bitIndex%64)) != 0
+}
+
+func (b retainedBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
+	return retainedBitSet{words: withBit(b.words, bitIndex), policy: b.policy}
+}
+
+func (b retainedBitSet) Clear(bitIndex uint32) Set {
+	if !b.Test(bitIndex) {
+		return b
+	}
+
+	idx := int(bitIndex / 64)
+
+	newBits := make([]uint64, len(b.words))
+	copy(newBits, b.words)
+	newBits[idx] &^= 1 << (bitIndex % 64)
+	recordCopy(len(newBits))
+	return b.retain(newBits)
+}
+
+func (b retainedBitSet) Union(other Set) Set {
+	return b.retain(wordsOf(combine(b, other, or)))
+}
+
+func (b retainedBitSet) Intersect(other Set) Set {
+	return b.retain(wordsOf(combine(b, other, and)))
+}
+
+func (b retainedBitSet) Difference(other Set) Set {
+	return b.retain(wordsOf(combine(b, other, andNot)))
+}
+
+func (b retainedBitSet) SymmetricDifference(other Set) Set {
+	return b.retain(wordsOf(combine(b, other, xor)))
+}
+
+func (b retainedBitSet) Count() int {
+	return popCount(b.words)
+}
+
+func (b retainedBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldWords(b.words, 0, yield)
+	}
+}
+
+func (b retainedBitSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(b)) + 8*cap(b.words)
+}
+
+func (b retainedBitSet) Kind() Kind {
+	return KindRetained
+}
+
+// Key returns the key of the equivalent set with the default ShrinkPolicy, so it doesn't depend on trailing zero words.
+func (b retainedBitSet) Key() Key {
+	return fromWords(b.words).Key()
+}
+
+// wordLen returns the number of words stored in b, including trailing zero words.
+func (b retainedBitSet) wordLen() int {
+	return len(b.words)
+}
+
+// retain returns words as a set with the policy of b, grown or trimmed to the size the policy allows given the size of b.
+// words is not copied, and must not be modified afterwards.
+func (b retainedBitSet) retain(words []uint64) Set {
+	used := len(words)
+	for used > 0 && words[used-1] == 0 {
+		used--
+	}
+
+	capacity := max(len(b.words), used)
+	n := b.policy.trim(capacity, used)
+	if n > len(words) {
+		newBits := make([]uint64, n)
+		copy(newBits, words)
+		words = newBits
+	}
+	return retainedBitSet{words: words[:n], policy: b.policy}
+}
+
+// wordsOf returns the bits of s as a word slice, which may share memory with s and must not be modified.
+func wordsOf(s Set) []uint64 {
+	s = materialized(s)
+	if words, ok := denseWords(s); ok {
+		return words
+	}
+
+	list := nonzeroWords(s)
+	if len(list) == 0 {
+		return nil
+	}
+	words := make([]uint64, list[len(list)-1].i+1)
+	for _, iw := range list {
+		words[iw.i] = iw.w
+	}
+	return words
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"testing"
+)
+
+func TestShrinkNever(t *testing.T) {
+	bs := NewWithShrinkPolicy(ShrinkNever).Set(5).Set(1000)
+
+	// Clearing the high bit keeps the words and the representation
+	bs2 := bs.Clear(1000)
+	rbs, ok := bs2.(retainedBitSet)
+	if !ok {
+		t.Fatalf("Expected retainedBitSet after Clear, but got %T", bs2)
+	}
+	if len(rbs.words) != 1000/64+1 {
+		t.Errorf("Words should not have been trimmed, got len %d", len(rbs.words))
+	}
+	if !bs2.Test(5) || bs2.Test(1000) {
+		t.Error("Retained set has incorrect bits after Clear")
+	}
+
+	// Test immutability on Clear
+	if !bs.Test(1000) {
+		t.Error("Original retainedBitSet should not be modified after Clear")
+	}
+
+	// Clearing every bit still keeps the words
+	empty := bs2.Clear(5)
+	if rbs, ok := empty.(retainedBitSet); !ok || len(rbs.words) != 1000/64+1 {
+		t.Errorf("Expected retainedBitSet with all its words, but got %T %v", empty, empty)
+	}
+
+	// Boolean operations keep the policy
+	u := bs2.Union(New().Set(3))
+	if rbs, ok := u.(retainedBitSet); !ok || rbs.policy != ShrinkNever || len(rbs.words) != 1000/64+1 {
+		t.Errorf("Expected retainedBitSet with the receiver's policy and size, but got %T %v", u, u)
+	}
+	if !u.Test(3) || !u.Test(5) || u.Test(1000) {
+		t.Error("Union of retained set has incorrect bits")
+	}
+}
+
+func TestShrinkHysteresis(t *testing.T) {
+	var bs Set = NewWithShrinkPolicy(4).Set(0).Set(10 * 64)
+
+	// Highest word drops by 4 words, which is within the allowed slack
+	bs = bs.Set(6 * 64).Clear(10 * 64)
+	if rbs := bs.(retainedBitSet); len(rbs.words) != 11 {
+		t.Errorf("Expected 11 words within the slack, got %d", len(rbs.words))
+	}
+
+	// Highest word drops by 10 words, past the slack
+	bs = bs.Clear(6 * 64)
+	if rbs := bs.(retainedBitSet); len(rbs.words) != 1 {
+		t.Errorf("Expected 1 word past the slack, got %d", len(rbs.words))
+	}
+	if !bs.Test(0) || bs.Test(6*64) || bs.Test(10*64) {
+		t.Error("Retained set has incorrect bits after shrinking")
+	}
+}
+
+func TestWithShrinkPolicy(t *testing.T) {
+	sparse := fromIndices([]uint32{3, 2_000_000})
+	rbs := WithShrinkPolicy(sparse, ShrinkNever)
+	if _, ok := rbs.(retainedBitSet); !ok {
+		t.Fatalf("Expected retainedBitSet, but got %T", rbs)
+	}
+	if !rbs.Test(3) || !rbs.Test(2_000_000) || rbs.Test(4) {
+		t.Error("Retained set has incorrect bits")
+	}
+
+	// Going back to the default policy returns the canonical representation
+	back := WithShrinkPolicy(rbs.Clear(2_000_000), ShrinkAlways)
+	if back != bitSet64(1<<3) {
+		t.Errorf("Expected bitSet64 after restoring ShrinkAlways, but got %T %v", back, back)
+	}
+}
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+	"unsafe"
+)
+
+// Run-length encoded (long runs of consecutive bits)
+type runBitSet []run[uint32] // sorted, non-overlapping and non-adjacent - immutable, always copied on modification
+
+// run is an inclusive range of set bits.
+type run[T uint16 | uint32] struct {
+	start, last T
+}
+
+// preferRuns reports whether a set with n bits set in r runs, spanning w words, should be a runBitSet.
+// A runBitSet is used when it takes at most half the memory of both the equivalent largeBitSet and sparseBitSet.
+func preferRuns(n, r, w int) bool {
+	return 4*r < min(2*w, n)
+}
+
+func (b runBitSet) Test(bitIndex uint32) bool {
+	_, found := searchRuns(b, bitIndex)
+	return found
+}
+
+func (b runBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
+	return fromRuns(withRun(b, bitIndex))
+}
+
+func (b runBitSet) Clear(bitIndex uint32) Set {
+	if !b.Test(bitIndex) {
+		return b
+	}
+
+	return fromRuns(withoutRun(b, bitIndex))
+}
+
+func (b runBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b runBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b runBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b runBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b runBitSet) Count() int {
+	return clampCount(countRuns(b))
+}
+
+func (b runBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for _, r := range b {
+			for i := r.start; ; i++ {
+				if !yield(i) {
+					return
+				}
+				if i == r.last {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (b runBitSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(b)) + int(unsafe.Sizeof(run[uint32]{}))*cap(b)
+}
+
+func (b runBitSet) Kind() Kind {
+	return KindRuns
+}
+
+func (b runBitSet) Key() Key {
+	key := newKey(KindRuns, 8*len(b))
+	for _, r := range b {
+		key = binary.LittleEndian.AppendUint32(key, r.start)
+		key = binary.LittleEndian.AppendUint32(key, r.last)
+	}
+	return Key(key)
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b runBitSet) wordLen() int {
+	if len(b) == 0 {
+		return 0
+	}
+	return int(b[len(b)-1].last/64) + 1
+}
+
+// fromRuns returns the most suitable Set representation for the given runs.
+// runs is not copied, and must not be modified afterwards.
+func fromRuns(runs []run[uint32]) Set {
+	if len(runs) == 0 {
+		return bitSet64(0)
+	}
+
+	// preferRuns and preferSparse only compare n with w, which is below 1<<26, so capping n doesn't change
+	// the choice, and keeps 2*n from overflowing on 32-bit platforms
+	n := int(min(countRuns(runs), math.MaxInt/4))
+	w := int(runs[len(runs)-1].last/64) + 1
+	switch {
+	case w <= 3:
+		return fromWords(runBitSet(runs).words())
+	case preferRuns(n, len(runs), w):
+		return runBitSet(runs)
+	case preferSparse(n, w):
+		return fromIndices(runBitSet(runs).indices(n))
+	}
+	return denseFromWords(runBitSet(runs).words(), n, len(runs))
+}
+
+// countRuns returns the number of bits in runs.
+func countRuns(runs []run[uint32]) uint64 {
+	n := uint64(0)
+	for _, r := range runs {
+		n += uint64(r.last-r.start) + 1
+	}
+	return n
+}
+
+// words returns the bits of b as a newly allocated word slice.
+func (b runBitSet) words() []uint64 {
+	words := make([]uint64, b[len(b)-1].last/64+1)
+	for _, r := range b {
+		fillRange(words, r.start, r.last)
+	}
+	return words
+}
+
+// indices returns the bit indices of all the n set bits in b.
+func (b runBitSet) indices(n int) []uint32 {
+	s := make([]uint32, 0, n)
+	for _, r := range b {
+		for i := uint64(r.start); i <= uint64(r.last); i++ {
+			s = append(s, uint32(i))
+		}
+	}
+	return s
+}
+
+// searchRuns returns the index of the run containing i, or the index where such a run would be inserted.
+func searchRuns[T uint16 | uint32](runs []run[T], i T) (int, bool) {
+	idx, _ := slices.BinarySearchFunc(runs, i, func(r run[T], i T) int {
+		if r.last < i {
+			return -1
+		}
+		return 1
+	})
+	return idx, idx < len(runs) && runs[idx].start <= i
+}
+
+// withRun returns a copy of runs with i added. i must not already be in runs.
+func withRun[T uint16 | uint32](runs []run[T], i T) []run[T] {
+	idx, _ := searchRuns(runs, i)
+	joinsPrev := idx > 0 && uint64(runs[idx-1].last)+1 == uint64(i)
+	joinsNext := idx < len(runs) && uint64(runs[idx].start) == uint64(i)+1
+	switch {
+	case joinsPrev && joinsNext:
+		newRuns := slices.Delete(slices.Clone(runs), idx, idx+1)
+		newRuns[idx-1].last = runs[idx].last
+		return newRuns
+	case joinsPrev:
+		newRuns := slices.Clone(runs)
+		newRuns[idx-1].last = i
+		return newRuns
+	case joinsNext:
+		newRuns := slices.Clone(runs)
+		newRuns[idx].start = i
+		return newRuns
+	}
+	return slices.Insert(slices.Clip(runs), idx, run[T]{i, i})
+}
+
+// withoutRun returns a copy of runs with i removed. i must be in runs.
+func withoutRun[T uint16 | uint32](runs []run[T], i T) []run[T] {
+	idx, _ := searchRuns(runs, i)
+	r := runs[idx]
+	switch {
+	case r.start == i && r.last == i:
+		return slices.Delete(slices.Clone(runs), idx, idx+1)
+	case r.start == i:
+		newRuns := slices.Clone(runs)
+		newRuns[idx].start++
+		return newRuns
+	case r.last == i:
+		newRuns := slices.Clone(runs)
+		newRuns[idx].last--
+		return newRuns
+	}
+
+	// Split the run in two
+	newRuns := slices.Insert(slices.Clip(runs), idx+1, run[T]{i + 1, r.last})
+	newRuns[idx].last = i - 1
+	return newRuns
+}
+
+// appendRun appends the run [start, last] to dst, merging it with the last run in dst if they are adjacent.
+// start must be greater than the end of the last run in dst.
+func appendRun(dst []run[uint32], start, last uint32) []run[uint32] {
+	if n := len(dst); n > 0 && uint64(dst[n-1].last)+1 == uint64(start) {
+		dst[n-1].last = last
+		return dst
+	}
+	return append(dst, run[uint32]{start, last})
+}
+
+// appendWordRuns appends the runs of set bits in words, offset by base, to dst.
+func appendWordRuns(dst []run[uint32], words []uint64, base uint32) []run[uint32] {
+	for i, w := range words {
+		offset := base + uint32(i*64)
+		for w != 0 {
+			start := bits.TrailingZeros64(w)
+			length := bits.TrailingZeros64(^(w >> start))
+			dst = appendRun(dst, offset+uint32(start), offset+uint32(start+length-1))
+			if start+length == 64 {
+				break
+			}
+			w &^= (1<<length - 1) << start
+		}
+	}
+	return dst
+}
+
+// runsFromIndices returns the runs of the given sorted bit indices.
+func runsFromIndices(indices []uint32) []run[uint32] {
+	runs := make([]run[uint32], 0, indexRunCount(indices))
+	for _, i := range indices {
+		runs = appendRun(runs, i, i)
+	}
+	return runs
+}
+
+// indexRunCount returns the number of runs in the given sorted bit indices.
+func indexRunCount(indices []uint32) int {
+	r := 0
+	for i, idx := range indices {
+		if i == 0 || indices[i-1] != idx-1 {
+			r++
+		}
+	}
+	return r
+}
+
+// runCount returns the number of runs of set bits in words.
+func runCount(words []uint64) int {
+	r := 0
+	var carry uint64 // the highest bit of the previous word
+	for _, w := range words {
+		// count the bits that start a run, i.e. set bits whose preceding bit is clear
+		r += bits.OnesCount64(w &^ (w<<1 | carry))
+		carry = w >> 63
+	}
+	return r
+}
+
+// fillRange sets the bits in [start, last] in words.
+func fillRange(words []uint64, start, last uint32) {
+	for i := start / 64; i <= last/64; i++ {
+		mask := ^uint64(0)
+		if i == start/64 {
+			mask &= ^uint64(0) << (start % 64)
+		}
+		if i == last/64 {
+			mask &= ^uint64(0) >> (63 - last%64)
+		}
+		words[i] |= mask
+	}
+}
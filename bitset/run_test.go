@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"testing"
+)
+
+func TestRunBitSet(t *testing.T) {
+	b := NewBuilder(0)
+	for i := uint32(100); i < 10_000; i++ {
+		b = b.With(i)
+	}
+	bs := b.Build()
+
+	rbs, ok := bs.(runBitSet)
+	if !ok {
+		t.Fatalf("A single long run of bits should be a runBitSet, but got %T", bs)
+	}
+	if len(rbs) != 1 || rbs[0] != (run[uint32]{100, 9_999}) {
+		t.Errorf("runBitSet has incorrect runs: %v", rbs)
+	}
+	if bs.Test(99) || !bs.Test(100) || !bs.Test(5000) || !bs.Test(9_999) || bs.Test(10_000) {
+		t.Error("runBitSet has incorrect bits")
+	}
+
+	// Extending and splitting runs
+	bs2 := bs.Set(10_000).Set(99).Clear(5000)
+	if bs.Test(10_000) || bs.Test(99) || !bs.Test(5000) {
+		t.Error("Original runBitSet should not be modified")
+	}
+	if rbs2, ok := bs2.(runBitSet); !ok || len(rbs2) != 2 || rbs2[0] != (run[uint32]{99, 4_999}) || rbs2[1] != (run[uint32]{5_001, 10_000}) {
+		t.Errorf("runBitSet has incorrect runs after modification: %v", bs2)
+	}
+
+	// Joining runs
+	bs3 := bs2.Set(5000)
+	if rbs3, ok := bs3.(runBitSet); !ok || len(rbs3) != 1 || rbs3[0] != (run[uint32]{99, 10_000}) {
+		t.Errorf("Setting the bit between two runs should join them, got %v", bs3)
+	}
+
+	// Runs at the very end of the index range
+	top := fromRuns([]run[uint32]{{1<<32 - 1000, 1<<32 - 1}})
+	if !top.Test(1<<32-1) || !top.Test(1<<32-1000) || top.Test(1<<32-1001) {
+		t.Error("Run at the end of the index range has incorrect bits")
+	}
+	if !top.Clear(1<<32-1).Test(1<<32-2) || top.Clear(1<<32-1).Test(1<<32-1) {
+		t.Error("Clearing the last bit of the index range is incorrect")
+	}
+}
+
+func TestRunBitSetDowngrade(t *testing.T) {
+	var bs Set = fromRuns([]run[uint32]{{0, 10}, {5000, 5010}})
+	if _, ok := bs.(runBitSet); !ok {
+		t.Fatalf("Expected runBitSet, but got %T", bs)
+	}
+
+	// Fragmenting the runs makes the set sparse
+	for i := uint32(5000); i <= 5010; i += 2 {
+		bs = bs.Clear(i)
+	}
+	for i := uint32(1); i <= 10; i += 2 {
+		bs = bs.Clear(i)
+	}
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet after fragmenting the runs, but got %T", bs)
+	}
+
+	// Clearing the high run downgrades to bitSet64
+	small := fromRuns([]run[uint32]{{0, 10}, {5000, 5010}})
+	for i := uint32(5000); i <= 5010; i++ {
+		small = small.Clear(i)
+	}
+	if small != bitSet64(1<<11-1) {
+		t.Errorf("Expected bitSet64 with the low run, but got %T %v", small, small)
+	}
+}
+
+func TestRunCount(t *testing.T) {
+	words := []uint64{0b1011, 1 << 63, 1, 0, ^uint64(0), ^uint64(0)}
+	if r := runCount(words); r != 4 {
+		t.Errorf("Expected 4 runs, got %d", r)
+	}
+
+	runs := appendWordRuns(nil, words, 0)
+	want := []run[uint32]{{0, 1}, {3, 3}, {127, 128}, {256, 383}}
+	if len(runs) != len(want) {
+		t.Fatalf("Expected runs %v, got %v", want, runs)
+	}
+	for i := range want {
+		if runs[i] != want[i] {
+			t.Fatalf("Expected runs %v, got %v", want, runs)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "math/bits"
+
+// LongestRun returns the first bit index and the length of the longest run of consecutive set bits in s,
+// e.g. the largest free block in an allocation bitmap, or 0, 0 if s is empty. If several runs are the longest,
+// it returns the lowest one. The length is a uint64, since a run can cover all 2^32 bit indices.
+// It works a word or a run at a time, rather than a bit at a time.
+func LongestRun(s Set) (start uint32, length uint64) {
+	if words, ok := denseWords(s); ok {
+		return longestRunInWords(words)
+	}
+	for _, r := range runsOf(s) {
+		if n := uint64(r.last-r.start) + 1; n > length {
+			start, length = r.start, n
+		}
+	}
+	return start, length
+}
+
+// LeadingRun returns the length of the run of consecutive set bits starting at bit 0, which is 0 if bit 0 is not set.
+func LeadingRun(s Set) uint64 {
+	if !s.Test(0) {
+		return 0
+	}
+	if words, ok := denseWords(s); ok {
+		n := uint64(0)
+		for _, w := range words {
+			ones := bits.TrailingZeros64(^w)
+			n += uint64(ones)
+			if ones < 64 {
+				break
+			}
+		}
+		return n
+	}
+	r := runsOf(s)[0]
+	return uint64(r.last) + 1
+}
+
+// TrailingRun returns the length of the run of consecutive set bits ending at the highest set bit, or 0 if s is empty.
+func TrailingRun(s Set) uint64 {
+	words, ok := denseWords(s)
+	if !ok {
+		runs := runsOf(s)
+		if len(runs) == 0 {
+			return 0
+		}
+		r := runs[len(runs)-1]
+		return uint64(r.last-r.start) + 1
+	}
+
+	i := len(words) - 1
+	for i >= 0 && words[i] == 0 {
+		i-- // retained sets may have trailing zero words
+	}
+	if i < 0 {
+		return 0
+	}
+	w := words[i] << bits.LeadingZeros64(words[i])
+	n := uint64(bits.LeadingZeros64(^w))
+	if n < 64-uint64(bits.LeadingZeros64(words[i])) {
+		return n
+	}
+	for i--; i >= 0; i-- {
+		ones := bits.LeadingZeros64(^words[i])
+		n += uint64(ones)
+		if ones < 64 {
+			break
+		}
+	}
+	return n
+}
+
+// longestRunInWords is LongestRun for a set stored as words.
+func longestRunInWords(words []uint64) (start uint32, length uint64) {
+	var curStart, cur uint64 // the run that continues into the current word
+	for i, w := range words {
+		base := uint64(i) * 64
+		if w == ^uint64(0) {
+			if cur == 0 {
+				curStart = base
+			}
+			cur += 64
+			continue
+		}
+
+		// The run from the previous words ends in the low bits of w
+		if cur == 0 {
+			curStart = base
+		}
+		low := uint64(bits.TrailingZeros64(^w))
+		if cur+low > length {
+			start, length = uint32(curStart), cur+low
+		}
+
+		// Runs inside w, the last of which may continue into the next word
+		cur = 0
+		for off := low; ; {
+			w &^= 1<<off - 1 // clear the bits below off
+			if w == 0 {
+				break
+			}
+			off = uint64(bits.TrailingZeros64(w))
+			ones := uint64(bits.TrailingZeros64(^(w >> off)))
+			if off+ones == 64 {
+				curStart, cur = base+off, ones
+				break
+			}
+			if ones > length {
+				start, length = uint32(base+off), ones
+			}
+			off += ones
+		}
+	}
+	if cur > length {
+		start, length = uint32(curStart), cur
+	}
+	return start, length
+}
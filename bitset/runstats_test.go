@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"testing"
+)
+
+// runStats returns what LongestRun, LeadingRun and TrailingRun should return for s, from its runs.
+func runStats(s Set) (start uint32, longest, leading, trailing uint64) {
+	runs := runsOf(s)
+	for k, r := range runs {
+		n := uint64(r.last-r.start) + 1
+		if n > longest {
+			start, longest = r.start, n
+		}
+		if k == 0 && r.start == 0 {
+			leading = n
+		}
+		if k == len(runs)-1 {
+			trailing = n
+		}
+	}
+	return start, longest, leading, trailing
+}
+
+func TestRunStats(t *testing.T) {
+	sets := testSets()
+	for _, s := range []Set{
+		Full(64 * 5),
+		Full(100).Union(FromIndices(130)),
+		NewRangeSet(Range{10, 20}, Range{64, 200}, Range{250, 260}).Bits(),
+		NewRangeSet(Range{0, 3}, Range{60, 130}, Range{191, 320}).Bits(),
+		UnsafeFromWords([]uint64{0b0110_1110, 0xf000_0000_0000_0000, 0b111, 0}),
+		FromStride(1, 2, 1000),
+	} {
+		sets = append(sets, testSet{name: FormatRangeList(s), s: s})
+		sets = append(sets, testSet{name: "retained " + FormatRangeList(s), s: WithShrinkPolicy(s, ShrinkNever).Set(1 << 12).Clear(1 << 12)})
+	}
+
+	for _, ts := range sets {
+		wantStart, wantLongest, wantLeading, wantTrailing := runStats(ts.s)
+		if start, length := LongestRun(ts.s); start != wantStart || length != wantLongest {
+			t.Errorf("%s: LongestRun = %d, %d, expected %d, %d", ts.name, start, length, wantStart, wantLongest)
+		}
+		if got := LeadingRun(ts.s); got != wantLeading {
+			t.Errorf("%s: LeadingRun = %d, expected %d", ts.name, got, wantLeading)
+		}
+		if got := TrailingRun(ts.s); got != wantTrailing {
+			t.Errorf("%s: TrailingRun = %d, expected %d", ts.name, got, wantTrailing)
+		}
+	}
+
+	full := Full(math.MaxUint32).Set(math.MaxUint32)
+	if start, length := LongestRun(full); start != 0 || length != 1<<32 {
+		t.Errorf("Expected a run of every bit, got %d, %d", start, length)
+	}
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+const (
+	setMapBits   = 5 // bits of the hash used per level
+	setMapFanout = 1 << setMapBits
+)
+
+var setMapSeed = maphash.MakeSeed()
+
+// bitset.SetMap is an immutable map from keys to Sets, e.g. a configuration made of many named sets.
+//
+// With and Without return a new map that shares all but O(log n) of its memory with the original,
+// instead of copying every entry like a Go map would, so every version can be kept as a cheap snapshot.
+// The zero value is an empty map.
+type SetMap[K comparable] struct {
+	root *setMapNode[K]
+	n    int
+}
+
+// setMapNode is a node of a hash array mapped trie. Below the last level of the hash,
+// a node holds the entries whose hashes collide in unordered slots, and bitmap is unused.
+type setMapNode[K comparable] struct {
+	bitmap uint32          // which of the 32 possible slots are present
+	slots  []setMapSlot[K] // immutable - always copied on modification
+}
+
+// setMapSlot is either an entry, or a child node if child is not nil.
+type setMapSlot[K comparable] struct {
+	key   K
+	hash  uint64
+	value Set
+	child *setMapNode[K]
+}
+
+// Len returns the number of entries in m.
+func (m SetMap[K]) Len() int {
+	return m.n
+}
+
+// Get returns the set for the given key, or false if m has no entry for it.
+func (m SetMap[K]) Get(key K) (Set, bool) {
+	return m.root.get(key, maphash.Comparable(setMapSeed, key))
+}
+
+// With returns a new bitset.SetMap with the entry for the given key set to s.
+// The original bitset.SetMap is not modified.
+func (m SetMap[K]) With(key K, s Set) SetMap[K] {
+	root, added := m.root.with(setMapSlot[K]{key: key, hash: maphash.Comparable(setMapSeed, key), value: s}, 0)
+	m.root = root
+	if added {
+		m.n++
+	}
+	return m
+}
+
+// Without returns a new bitset.SetMap without the entry for the given key.
+// The original bitset.SetMap is not modified.
+func (m SetMap[K]) Without(key K) SetMap[K] {
+	root, removed := m.root.without(key, maphash.Comparable(setMapSeed, key), 0)
+	if removed {
+		m.root = root
+		m.n--
+	}
+	return m
+}
+
+// All returns an iterator over the entries of m, in no particular order.
+func (m SetMap[K]) All() iter.Seq2[K, Set] {
+	return func(yield func(K, Set) bool) {
+		m.root.all(yield)
+	}
+}
+
+// get returns the value of the entry for key, which has hash h, under n. n may be nil.
+func (n *setMapNode[K]) get(key K, h uint64) (Set, bool) {
+	for shift := 0; n != nil; shift += setMapBits {
+		slot, ok := n.find(key, h, shift)
+		if !ok {
+			return nil, false
+		}
+		if slot.child == nil {
+			return slot.value, true
+		}
+		n = slot.child
+	}
+	return nil, false
+}
+
+// find returns the slot of n that the key with hash h belongs to at the given shift, if there is one.
+// At the last level, it only returns an entry with the same key.
+func (n *setMapNode[K]) find(key K, h uint64, shift int) (setMapSlot[K], bool) {
+	if shift >= 64 {
+		for _, slot := range n.slots {
+			if slot.key == key {
+				return slot, true
+			}
+		}
+		return setMapSlot[K]{}, false
+	}
+
+	bit := uint32(1) << (h >> shift % setMapFanout)
+	if n.bitmap&bit == 0 {
+		return setMapSlot[K]{}, false
+	}
+	slot := n.slots[bits.OnesCount32(n.bitmap&(bit-1))]
+	if slot.child == nil && slot.key != key {
+		return setMapSlot[K]{}, false
+	}
+	return slot, true
+}
+
+// with returns a copy of n with the entry e added or replaced, and whether it was added.
+// n may be nil.
+func (n *setMapNode[K]) with(e setMapSlot[K], shift int) (*setMapNode[K], bool) {
+	if n == nil {
+		n = &setMapNode[K]{}
+	}
+
+	if shift >= 64 {
+		i := slices.IndexFunc(n.slots, func(slot setMapSlot[K]) bool { return slot.key == e.key })
+		if i < 0 {
+			return &setMapNode[K]{slots: append(slices.Clip(n.slots), e)}, true
+		}
+		newSlots := slices.Clone(n.slots)
+		newSlots[i] = e
+		return &setMapNode[K]{slots: newSlots}, false
+	}
+
+	bit := uint32(1) << (e.hash >> shift % setMapFanout)
+	i := bits.OnesCount32(n.bitmap & (bit - 1))
+	if n.bitmap&bit == 0 {
+		return &setMapNode[K]{bitmap: n.bitmap | bit, slots: slices.Insert(slices.Clip(n.slots), i, e)}, true
+	}
+
+	slot := n.slots[i]
+	var added bool
+	switch {
+	case slot.child != nil:
+		slot.child, added = slot.child.with(e, shift+setMapBits)
+		slot = setMapSlot[K]{child: slot.child}
+	case slot.key == e.key:
+		slot = e
+	default:
+		// Push the existing entry and the new one down into a new child node
+		child, _ := (*setMapNode[K])(nil).with(slot, shift+setMapBits)
+		child, added = child.with(e, shift+setMapBits)
+		slot = setMapSlot[K]{child: child}
+	}
+
+	newSlots := slices.Clone(n.slots)
+	newSlots[i] = slot
+	return &setMapNode[K]{bitmap: n.bitmap, slots: newSlots}, added
+}
+
+// without returns a copy of n without the entry for key, or nil if it would be empty, and whether it was removed.
+// n may be nil.
+func (n *setMapNode[K]) without(key K, h uint64, shift int) (*setMapNode[K], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	if shift >= 64 {
+		i := slices.IndexFunc(n.slots, func(slot setMapSlot[K]) bool { return slot.key == key })
+		if i < 0 {
+			return n, false
+		}
+		if len(n.slots) == 1 {
+			return nil, true
+		}
+		return &setMapNode[K]{slots: slices.Delete(slices.Clone(n.slots), i, i+1)}, true
+	}
+
+	bit := uint32(1) << (h >> shift % setMapFanout)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	i := bits.OnesCount32(n.bitmap & (bit - 1))
+	slot := n.slots[i]
+
+	if slot.child != nil {
+		child, removed := slot.child.without(key, h, shift+setMapBits)
+		if !removed {
+			return n, false
+		}
+		if child != nil {
+			newSlots := slices.Clone(n.slots)
+			newSlots[i] = setMapSlot[K]{child: child}
+			return &setMapNode[K]{bitmap: n.bitmap, slots: newSlots}, true
+		}
+	} else if slot.key != key {
+		return n, false
+	}
+
+	// Remove the slot
+	if len(n.slots) == 1 {
+		return nil, true
+	}
+	return &setMapNode[K]{bitmap: n.bitmap &^ bit, slots: slices.Delete(slices.Clone(n.slots), i, i+1)}, true
+}
+
+// all calls yield for every entry under n, and reports whether to continue. n may be nil.
+func (n *setMapNode[K]) all(yield func(K, Set) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for _, slot := range n.slots {
+		if slot.child != nil {
+			if !slot.child.all(yield) {
+				return false
+			}
+		} else if !yield(slot.key, slot.value) {
+			return false
+		}
+	}
+	return true
+}
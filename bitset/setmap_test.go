@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetMap(t *testing.T) {
+	var m SetMap[string]
+	if m.Len() != 0 {
+		t.Error("Zero SetMap should be empty")
+	}
+	if _, ok := m.Get("a"); ok {
+		t.Error("Zero SetMap should have no entries")
+	}
+
+	m1 := m.With("a", FromIndices(1)).With("b", FromIndices(2))
+	m2 := m1.With("a", FromIndices(3)).Without("b").Without("missing")
+
+	if s, ok := m1.Get("a"); !ok || !s.Test(1) || m1.Len() != 2 {
+		t.Error("m1 has incorrect entries")
+	}
+	if s, ok := m2.Get("a"); !ok || !s.Test(3) || m2.Len() != 1 {
+		t.Error("m2 has incorrect entries")
+	}
+	if _, ok := m2.Get("b"); ok {
+		t.Error("Without should remove the entry")
+	}
+	if _, ok := m1.Get("b"); !ok {
+		t.Error("Without should not modify the original map")
+	}
+	if m.Len() != 0 {
+		t.Error("With should not modify the original map")
+	}
+}
+
+func TestSetMapMany(t *testing.T) {
+	const n = 10_000
+	var m SetMap[int]
+	for i := range n {
+		m = m.With(i, FromIndices(uint32(i)))
+	}
+	if m.Len() != n {
+		t.Fatalf("Expected %d entries, got %d", n, m.Len())
+	}
+
+	seen := make(map[int]bool)
+	for k, s := range m.All() {
+		if seen[k] || !s.Test(uint32(k)) {
+			t.Fatalf("Unexpected entry %d", k)
+		}
+		seen[k] = true
+	}
+	if len(seen) != n {
+		t.Errorf("All returned %d entries, expected %d", len(seen), n)
+	}
+
+	for i := 0; i < n; i += 2 {
+		m = m.Without(i)
+	}
+	if m.Len() != n/2 {
+		t.Fatalf("Expected %d entries, got %d", n/2, m.Len())
+	}
+	for i := range n {
+		if _, ok := m.Get(i); ok != (i%2 == 1) {
+			t.Fatalf("Get(%d) returned %v", i, ok)
+		}
+	}
+
+	for i := 1; i < n; i += 2 {
+		m = m.Without(i)
+	}
+	if m.Len() != 0 || m.root != nil {
+		t.Error("Removing every entry should leave an empty map")
+	}
+}
+
+func TestSetMapCollisions(t *testing.T) {
+	// Give every key the same hash, so they all end up in one node at the last level
+	var root *setMapNode[string]
+	for i := range 5 {
+		root, _ = root.with(setMapSlot[string]{key: fmt.Sprint(i), hash: 42, value: FromIndices(uint32(i))}, 0)
+	}
+	root, added := root.with(setMapSlot[string]{key: "3", hash: 42, value: FromIndices(30)}, 0)
+	if added {
+		t.Error("Replacing an entry should not add one")
+	}
+
+	for i := range 5 {
+		s, ok := root.get(fmt.Sprint(i), 42)
+		want := uint32(i)
+		if i == 3 {
+			want = 30
+		}
+		if !ok || !s.Test(want) {
+			t.Errorf("Missing entry %d", i)
+		}
+	}
+	if _, ok := root.get("5", 42); ok {
+		t.Error("Unexpected entry for a missing key")
+	}
+
+	for i := range 5 {
+		var removed bool
+		if root, removed = root.without(fmt.Sprint(i), 42, 0); !removed {
+			t.Errorf("Entry %d was not removed", i)
+		}
+	}
+	if root != nil {
+		t.Error("Removing every entry should leave an empty trie")
+	}
+}
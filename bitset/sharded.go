@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "fmt"
+
+// bitset.ShardedBuilder builds a set from multiple goroutines without locks, for ingesting bits faster than one
+// goroutine can set them. Each goroutine fills its own Shard, and Build merges the shards in parallel.
+//
+// Every shard stores words up to its highest bit, so giving each goroutine its own part of the bit range keeps
+// the total memory close to that of the final set.
+//
+// WARNING: Using the builder or its shards after calling Build() is not supported and will cause undefined behavior.
+type ShardedBuilder struct {
+	shards []Shard
+}
+
+// bitset.Shard is one part of a ShardedBuilder, which must only be used by one goroutine at a time.
+type Shard struct {
+	words []uint64
+}
+
+// NewShardedBuilder creates and returns a new bitset.ShardedBuilder with n empty shards.
+// It panics if n is not positive.
+func NewShardedBuilder(n int) *ShardedBuilder {
+	if n <= 0 {
+		panic(fmt.Sprintf("bitset: invalid number of shards %d", n))
+	}
+	return &ShardedBuilder{make([]Shard, n)}
+}
+
+// Len returns the number of shards in b.
+func (b *ShardedBuilder) Len() int {
+	return len(b.shards)
+}
+
+// Shard returns the i-th shard of b. Different shards can be filled concurrently.
+func (b *ShardedBuilder) Shard(i int) *Shard {
+	return &b.shards[i]
+}
+
+// Add sets the bit for the given bit index.
+func (s *Shard) Add(bitIndex uint32) {
+	idx := int(bitIndex / 64)
+	if idx >= len(s.words) {
+		s.words = append(s.words, make([]uint64, idx+1-len(s.words))...)
+	}
+	s.words[idx] |= 1 << (bitIndex % 64)
+}
+
+// AddMany sets the bits for the given bit indices.
+func (s *Shard) AddMany(bitIndices ...uint32) {
+	for _, i := range bitIndices {
+		s.Add(i)
+	}
+}
+
+// Build returns the final immutable Set containing all the bits set on the shards of b, merging them in parallel.
+// Using the builder or its shards after calling Build() is not supported and will cause undefined behavior.
+func (b *ShardedBuilder) Build() Set {
+	// Merge into the longest shard, so the result needs no new allocation
+	longest := 0
+	for i := range b.shards {
+		if len(b.shards[i].words) > len(b.shards[longest].words) {
+			longest = i
+		}
+	}
+	out := b.shards[longest].words
+
+	merge := func(lo, hi int) {
+		for i := range b.shards {
+			words := b.shards[i].words
+			if i == longest || lo >= len(words) {
+				continue
+			}
+			end := min(hi, len(words))
+			orWords(out[lo:end], out[lo:end], words[lo:end])
+		}
+	}
+	if p := (Parallel{}); len(out) >= p.threshold() {
+		p.split(len(out), merge)
+	} else {
+		merge(0, len(out))
+	}
+	return fromWords(out)
+}
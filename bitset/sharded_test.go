@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedBuilder(t *testing.T) {
+	for _, size := range []uint32{100, 1 << 24} {
+		b := NewShardedBuilder(4)
+		var wg sync.WaitGroup
+		for i := range b.Len() {
+			shard := b.Shard(i)
+			wg.Go(func() {
+				// Interleave the shards' bits, so every shard spans the whole range
+				for bit := 7 * uint32(i); bit < size; bit += 7 * uint32(b.Len()) {
+					shard.Add(bit)
+				}
+			})
+		}
+		wg.Wait()
+
+		got := b.Build()
+		want := FromStride(0, 7, (size+6)/7)
+		if got.Key() != want.Key() {
+			t.Errorf("size %d: expected every 7th bit, got %d bits", size, got.Count())
+		}
+		if err := CheckInvariants(got); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestShardedBuilderEdgeCases(t *testing.T) {
+	if got := NewShardedBuilder(3).Build(); !isEmpty(got) {
+		t.Errorf("Expected an empty set, got %d bits", got.Count())
+	}
+
+	b := NewShardedBuilder(2)
+	b.Shard(1).AddMany(5, 1000)
+	b.Shard(0).Add(3)
+	checkCanonical(t, b.Build())
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected NewShardedBuilder(0) to panic")
+		}
+	}()
+	NewShardedBuilder(0)
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Jaccard returns the Jaccard similarity of a and b, i.e. the number of bits set in both divided by the number of
+// bits set in either, which is 1 for equal sets and 0 for disjoint ones. Two empty sets have a similarity of 1.
+// It doesn't allocate the intersection or union of the sets.
+func Jaccard(a, b Set) float64 {
+	inter := andCount(a, b)
+	union := a.Count() + b.Count() - inter
+	if union == 0 {
+		return 1
+	}
+	return float64(inter) / float64(union)
+}
+
+// HammingDistance returns the number of bits set in exactly one of a and b, e.g. the number of differing bits
+// between two binary fingerprints. It doesn't allocate the symmetric difference of the sets.
+func HammingDistance(a, b Set) int {
+	return a.Count() + b.Count() - 2*andCount(a, b)
+}
+
+// MinHash returns a MinHash signature of s with k hash functions derived from seed, a sketch of s that can be
+// compared with MinHashSimilarity to estimate the Jaccard similarity of two sets without having both of them.
+// Signatures are only comparable if they were computed with the same k and seed.
+// Every entry of the signature of an empty set is math.MaxUint64.
+func MinHash(s Set, k int, seed uint64) []uint64 {
+	sig := make([]uint64, k)
+	for j := range sig {
+		sig[j] = math.MaxUint64
+	}
+
+	for i := range s.Indices() {
+		for j := range sig {
+			sig[j] = min(sig[j], splitMix64(uint64(i)^splitMix64(seed+uint64(j))))
+		}
+	}
+	return sig
+}
+
+// MinHashSimilarity returns the estimated Jaccard similarity of the sets with the given MinHash signatures,
+// i.e. the fraction of their entries that are equal. It panics if the signatures have different lengths.
+func MinHashSimilarity(a, b []uint64) float64 {
+	if len(a) != len(b) {
+		panic("bitset: MinHash signatures have different lengths")
+	}
+	if len(a) == 0 {
+		return 0
+	}
+
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// splitMix64 returns the SplitMix64 hash of x.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// andCount returns the number of bits set in both a and b, without allocating their intersection.
+func andCount(a, b Set) int {
+	aWords, aDense := denseWords(a)
+	bWords, bDense := denseWords(b)
+	if aDense && bDense {
+		n := 0
+		for i := range min(len(aWords), len(bWords)) {
+			n += bits.OnesCount64(aWords[i] & bWords[i])
+		}
+		return n
+	}
+
+	// Test every bit of the smaller set against the other one
+	if a.Count() > b.Count() {
+		a, b = b, a
+	}
+	n := 0
+	for i := range a.Indices() {
+		if b.Test(i) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b Set
+		want float64
+	}{
+		{FromIndices(1, 2, 3), FromIndices(1, 2, 3), 1},
+		{FromIndices(1, 2), FromIndices(3, 4), 0},
+		{FromIndices(1, 2, 3), FromIndices(2, 3, 4), 0.5},
+		{New(), New(), 1},
+		{New(), FromIndices(1), 0},
+	}
+	for _, tt := range tests {
+		if got := Jaccard(tt.a, tt.b); got != tt.want {
+			t.Errorf("Jaccard(%v, %v) = %v, expected %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	x, y := New(), New()
+	for i := range uint32(10_000) {
+		if i%3 == 0 {
+			x = x.Set(i)
+		}
+		if i%5 == 0 {
+			y = y.Set(i)
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { Jaccard(x, y) }); allocs != 0 {
+		t.Errorf("Jaccard of dense sets made %v allocations", allocs)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	if got := HammingDistance(FromIndices(1, 2, 3, 100), FromIndices(2, 3, 4)); got != 3 {
+		t.Errorf("Expected distance 3, got %d", got)
+	}
+	if got := HammingDistance(New(), New()); got != 0 {
+		t.Errorf("Expected distance 0, got %d", got)
+	}
+
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			if got, want := HammingDistance(a.s, b.s), a.s.SymmetricDifference(b.s).Count(); got != want {
+				t.Errorf("HammingDistance(%s, %s) = %d, expected %d", a.name, b.name, got, want)
+			}
+		}
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	a, b := New(), New()
+	for i := range uint32(20_000) {
+		if i < 15_000 {
+			a = a.Set(i * 7)
+		}
+		if i >= 5_000 {
+			b = b.Set(i * 7)
+		}
+	}
+	// |a ∩ b| = 10000, |a ∪ b| = 20000
+
+	sigA, sigB := MinHash(a, 256, 42), MinHash(b, 256, 42)
+	if got := MinHashSimilarity(sigA, sigB); math.Abs(got-0.5) > 0.1 {
+		t.Errorf("Estimated similarity %v is too far from 0.5", got)
+	}
+	if got := MinHashSimilarity(sigA, MinHash(a, 256, 42)); got != 1 {
+		t.Errorf("Equal sets should have similarity 1, got %v", got)
+	}
+	if got := MinHashSimilarity(sigA, MinHash(a, 256, 43)); got > 0.1 {
+		t.Errorf("Signatures with different seeds should not match, got %v", got)
+	}
+
+	for _, v := range MinHash(New(), 4, 0) {
+		if v != math.MaxUint64 {
+			t.Error("Empty set should have a signature of math.MaxUint64")
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MinHashSimilarity should panic for signatures of different lengths")
+		}
+	}()
+	MinHashSimilarity(sigA, sigB[:10])
+}
+
+func TestAndCount(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			if got, want := andCount(a.s, b.s), a.s.Intersect(b.s).Count(); got != want {
+				t.Errorf("andCount(%s, %s) = %d, expected %d", a.name, b.name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "slices"
+
+// Slice returns a new bitset.Set with the bits of s in [lo, hi), shifted down so bit lo is bit 0,
+// e.g. to carve the bits of one shard out of a global set.
+// It only visits the part of s in the range, whatever its representation.
+// The original bitset.Set is not modified.
+func Slice(s Set, lo, hi uint32) Set {
+	return slice(s, uint64(lo), uint64(hi))
+}
+
+// SplitAt returns two new bitset.Sets with the bits of s below i, and the bits at or above i shifted down so bit i
+// is bit 0, e.g. to divide a keyspace between workers. Concat(low, i, high) has the same bits as s.
+// The original bitset.Set is not modified.
+func SplitAt(s Set, i uint32) (low, high Set) {
+	return slice(s, 0, uint64(i)), slice(s, uint64(i), 1<<32)
+}
+
+// InsertAt returns a new bitset.Set with the bits of s at or above i shifted up by one, and bit i set to value,
+// e.g. to keep a set in step with a list that an element was inserted into.
+// A bit at the highest bit index is shifted out and dropped.
+// The original bitset.Set is not modified.
+func InsertAt(s Set, i uint32, value bool) Set {
+	out := slice(s, 0, uint64(i)).Union(shiftUp(slice(s, uint64(i), 1<<32), uint64(i)+1))
+	if value {
+		out = out.Set(i)
+	}
+	return out
+}
+
+// DeleteAt returns a new bitset.Set without bit i, and with the bits of s above i shifted down by one,
+// e.g. to keep a set in step with a list that an element was removed from. It is the inverse of InsertAt.
+// The original bitset.Set is not modified.
+func DeleteAt(s Set, i uint32) Set {
+	return slice(s, 0, uint64(i)).Union(shiftUp(slice(s, uint64(i)+1, 1<<32), uint64(i)))
+}
+
+// slice is Slice for a range that may extend past the highest bit index.
+func slice(s Set, lo, hi uint64) Set {
+	hi = min(hi, 1<<32)
+	if lo >= hi {
+		return bitSet64(0)
+	}
+
+	switch s := materialized(s).(type) {
+	case sparseBitSet:
+		i, _ := slices.BinarySearch(s, uint32(lo))
+		j := len(s)
+		if hi < 1<<32 {
+			j, _ = slices.BinarySearch(s, uint32(hi))
+		}
+		out := make([]uint32, j-i)
+		for k := range out {
+			out[k] = s[i+k] - uint32(lo)
+		}
+		return fromIndices(out)
+	case runBitSet:
+		i, _ := searchRuns(s, uint32(lo))
+		var out []run[uint32]
+		for _, r := range s[i:] {
+			if uint64(r.start) >= hi {
+				break
+			}
+			start, last := max(uint64(r.start), lo), min(uint64(r.last), hi-1)
+			out = append(out, run[uint32]{uint32(start - lo), uint32(last - lo)})
+		}
+		return fromRuns(out)
+	case chunkedBitSet:
+		var out []uint32
+		first, _ := s.find(uint16(lo >> 16))
+		for _, ch := range s.chunks[first:] {
+			base := uint64(ch.key) << 16
+			if base >= hi {
+				break
+			}
+			ch.c.each(uint32(base), func(i uint32) bool {
+				if uint64(i) >= lo && uint64(i) < hi {
+					out = append(out, i-uint32(lo))
+				}
+				return uint64(i) < hi
+			})
+		}
+		return fromIndices(out)
+	case trieBitSet:
+		hi = min(hi, uint64(s.w)*64)
+		if lo >= hi {
+			return bitSet64(0)
+		}
+		out := make([]uint64, (hi-lo+63)/64)
+		s.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+			sliceWords(out, leaf[:], uint64(wordIdx), lo, hi)
+		})
+		return fromWords(out)
+	}
+
+	words, _ := denseWords(s)
+	hi = min(hi, uint64(len(words))*64)
+	if lo >= hi {
+		return bitSet64(0)
+	}
+	out := make([]uint64, (hi-lo+63)/64)
+	sliceWords(out, words, 0, lo, hi)
+	return fromWords(out)
+}
+
+// sliceWords ORs the bits in [lo, hi) of words into out, shifted down by lo, where words[i] holds the bits of word
+// first+i. out must be long enough to hold hi-lo bits.
+func sliceWords(out, words []uint64, first, lo, hi uint64) {
+	from, to := max(lo/64, first), min((hi+63)/64, first+uint64(len(words)))
+	for wi := from; wi < to; wi++ {
+		w, base := words[wi-first], wi*64
+		if base < lo {
+			w &= ^uint64(0) << (lo - base)
+		}
+		if base+64 > hi {
+			w &= 1<<(hi-base) - 1
+		}
+		if w == 0 {
+			continue
+		}
+
+		if base < lo {
+			out[0] |= w >> (lo - base)
+			continue
+		}
+		off := base - lo
+		out[off/64] |= w << (off % 64)
+		if off%64 != 0 && off/64+1 < uint64(len(out)) {
+			out[off/64+1] |= w >> (64 - off%64)
+		}
+	}
+}
+
+// Concat returns a new bitset.Set with the bits of a below widthA, followed by the bits of b shifted up by widthA,
+// e.g. to assemble a composite mask from fixed-width fields. It is the inverse of Slice, so
+// Concat(Slice(s, 0, w), w, Slice(s, w, math.MaxUint32)) has the same bits as s, apart from the highest bit index.
+// Bits of a at or above widthA, and bits of b that would be shifted past the highest bit index, are dropped.
+// Neither set is modified.
+func Concat(a Set, widthA uint32, b Set) Set {
+	return slice(a, 0, uint64(widthA)).Union(shiftUp(b, uint64(widthA)))
+}
+
+// shiftUp returns the bits of s shifted up by k, dropping the bits that would be shifted past the highest bit index.
+func shiftUp(s Set, k uint64) Set {
+	if k == 0 {
+		return s
+	}
+	s = slice(s, 0, 1<<32-min(k, 1<<32))
+
+	switch s := s.(type) {
+	case sparseBitSet:
+		out := make([]uint32, len(s))
+		for i, idx := range s {
+			out[i] = idx + uint32(k)
+		}
+		return fromIndices(out)
+	case runBitSet:
+		out := make([]run[uint32], len(s))
+		for i, r := range s {
+			out[i] = run[uint32]{r.start + uint32(k), r.last + uint32(k)}
+		}
+		return fromRuns(out)
+	}
+
+	wordShift, bitShift := int(k/64), k%64
+	var out []indexedWord
+	for _, iw := range nonzeroWords(s) {
+		i := iw.i + wordShift
+		if lo := iw.w << bitShift; lo != 0 {
+			if n := len(out); n > 0 && out[n-1].i == i {
+				out[n-1].w |= lo
+			} else {
+				out = append(out, indexedWord{i, lo})
+			}
+		}
+		if bitShift != 0 {
+			if hi := iw.w >> (64 - bitShift); hi != 0 {
+				out = append(out, indexedWord{i + 1, hi})
+			}
+		}
+	}
+	return fromWordList(out)
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	ranges := [][2]uint32{
+		{0, 0}, {5, 5}, {9, 3}, {0, 64}, {1, 63}, {63, 130}, {64, 192}, {100, 70_000},
+		{65_530, 65_600}, {10, 1_000_001}, {99_999, 2_000_001}, {2_999_990, 3_000_050}, {0, math.MaxUint32},
+	}
+	for _, ts := range testSets() {
+		for _, r := range ranges {
+			lo, hi := r[0], r[1]
+			var want []uint32
+			for i := range ts.s.Indices() {
+				if i >= lo && i < hi {
+					want = append(want, i-lo)
+				}
+			}
+			got := Slice(ts.s, lo, hi)
+			if idx := slices.Collect(got.Indices()); !slices.Equal(idx, want) {
+				t.Errorf("Slice(%s, %d, %d) returned %d bits, want %d", ts.name, lo, hi, len(idx), len(want))
+			}
+			checkCanonical(t, got)
+		}
+	}
+}
+
+func TestSliceHighestBit(t *testing.T) {
+	s := FromIndices(math.MaxUint32-1, math.MaxUint32)
+	if got := Slice(s, math.MaxUint32-1, math.MaxUint32); !slices.Equal(slices.Collect(got.Indices()), []uint32{0}) {
+		t.Errorf("Slice excluding the highest bit returned %v", slices.Collect(got.Indices()))
+	}
+	if got := slice(s, math.MaxUint32, 1<<32); !slices.Equal(slices.Collect(got.Indices()), []uint32{0}) {
+		t.Errorf("slice up to 1<<32 returned %v", slices.Collect(got.Indices()))
+	}
+}
+
+func TestConcat(t *testing.T) {
+	sets := testSets()
+	for _, a := range sets {
+		for _, b := range sets {
+			for _, width := range []uint32{0, 1, 64, 100, 65_537} {
+				var want []uint32
+				for i := range a.s.Indices() {
+					if i < width {
+						want = append(want, i)
+					}
+				}
+				for i := range b.s.Indices() {
+					want = append(want, i+width)
+				}
+
+				got := Concat(a.s, width, b.s)
+				if idx := slices.Collect(got.Indices()); !slices.Equal(idx, want) {
+					t.Fatalf("Concat(%s, %d, %s) returned %d bits, want %d", a.name, width, b.name, len(idx), len(want))
+				}
+				checkCanonical(t, got)
+			}
+		}
+	}
+}
+
+func TestConcatInvertsSlice(t *testing.T) {
+	for _, ts := range testSets() {
+		for _, w := range []uint32{0, 63, 150, 70_000, 2_000_000} {
+			got := Concat(Slice(ts.s, 0, w), w, Slice(ts.s, w, math.MaxUint32))
+			if got.Key() != ts.s.Key() {
+				t.Errorf("Concat of %s sliced at %d differs from the original", ts.name, w)
+			}
+		}
+	}
+}
+
+func TestConcatOverflow(t *testing.T) {
+	got := Concat(New(), math.MaxUint32-1, FromIndices(0, 1, 2))
+	if idx := slices.Collect(got.Indices()); !slices.Equal(idx, []uint32{math.MaxUint32 - 1, math.MaxUint32}) {
+		t.Errorf("Concat past the highest bit index returned %v", idx)
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	for _, ts := range testSets() {
+		for _, i := range []uint32{0, 1, 64, 150, 65_536, 1_000_000, math.MaxUint32} {
+			low, high := SplitAt(ts.s, i)
+			checkCanonical(t, low)
+			checkCanonical(t, high)
+			if low.Key() != Slice(ts.s, 0, i).Key() {
+				t.Errorf("SplitAt(%s, %d) low half differs from Slice", ts.name, i)
+			}
+			if got := Concat(low, i, high); got.Key() != ts.s.Key() {
+				t.Errorf("Concat of SplitAt(%s, %d) differs from the original", ts.name, i)
+			}
+		}
+	}
+
+	_, high := SplitAt(FromIndices(3, math.MaxUint32), math.MaxUint32)
+	if idx := slices.Collect(high.Indices()); !slices.Equal(idx, []uint32{0}) {
+		t.Errorf("SplitAt at the highest bit index returned high %v", idx)
+	}
+}
+
+func TestInsertDeleteAt(t *testing.T) {
+	for _, ts := range testSets() {
+		for _, i := range []uint32{0, 5, 63, 64, 191, 65_535, 1_000_000} {
+			for _, value := range []bool{false, true} {
+				var want []uint32
+				for j := range ts.s.Indices() {
+					if j < i {
+						want = append(want, j)
+					}
+				}
+				if value {
+					want = append(want, i)
+				}
+				for j := range ts.s.Indices() {
+					if j >= i {
+						want = append(want, j+1)
+					}
+				}
+
+				got := InsertAt(ts.s, i, value)
+				if idx := slices.Collect(got.Indices()); !slices.Equal(idx, want) {
+					t.Fatalf("InsertAt(%s, %d, %v) returned %d bits, want %d", ts.name, i, value, len(idx), len(want))
+				}
+				checkCanonical(t, got)
+
+				deleted := DeleteAt(got, i)
+				if deleted.Key() != ts.s.Key() {
+					t.Errorf("DeleteAt(InsertAt(%s, %d, %v)) differs from the original", ts.name, i, value)
+				}
+				checkCanonical(t, deleted)
+			}
+		}
+	}
+}
+
+func TestInsertAtHighestBit(t *testing.T) {
+	s := FromIndices(0, math.MaxUint32)
+	if idx := slices.Collect(InsertAt(s, 0, false).Indices()); !slices.Equal(idx, []uint32{1}) {
+		t.Errorf("InsertAt should drop the highest bit, got %v", idx)
+	}
+	if idx := slices.Collect(InsertAt(s, math.MaxUint32, false).Indices()); !slices.Equal(idx, []uint32{0}) {
+		t.Errorf("InsertAt at the highest bit index returned %v", idx)
+	}
+	if idx := slices.Collect(DeleteAt(s, math.MaxUint32).Indices()); !slices.Equal(idx, []uint32{0}) {
+		t.Errorf("DeleteAt at the highest bit index returned %v", idx)
+	}
+}
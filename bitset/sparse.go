@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"iter"
+	"math/bits"
+	"slices"
+	"unsafe"
+)
+
+// Sparse (few bits spread over a wide range)
+type sparseBitSet []uint32 // sorted bit indices without duplicates - immutable, always copied on modification
+
+// preferSparse reports whether a set with n bits set, spanning w words, should be a sparseBitSet rather than a largeBitSet.
+// A sparseBitSet is used when it takes at most a quarter of the memory the equivalent largeBitSet would.
+func preferSparse(n, w int) bool {
+	return 2*n < w
+}
+
+func (b sparseBitSet) Test(bitIndex uint32) bool {
+	_, found := slices.BinarySearch(b, bitIndex)
+	return found
+}
+
+func (b sparseBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
+	return fromIndices(b.insert(bitIndex))
+}
+
+func (b sparseBitSet) Clear(bitIndex uint32) Set {
+	i, found := slices.BinarySearch(b, bitIndex)
+	if !found {
+		return b
+	}
+
+	newIdx := make([]uint32, len(b)-1)
+	copy(newIdx, b[:i])
+	copy(newIdx[i:], b[(i+1):])
+	return fromIndices(newIdx)
+}
+
+func (b sparseBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b sparseBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b sparseBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b sparseBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+func (b sparseBitSet) Count() int {
+	return len(b)
+}
+
+func (b sparseBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for _, idx := range b {
+			if !yield(idx) {
+				return
+			}
+		}
+	}
+}
+
+func (b sparseBitSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(b)) + 4*cap(b)
+}
+
+func (b sparseBitSet) Kind() Kind {
+	return KindSparse
+}
+
+func (b sparseBitSet) Key() Key {
+	key := newKey(KindSparse, 4*len(b))
+	for _, idx := range b {
+		key = binary.LittleEndian.AppendUint32(key, idx)
+	}
+	return Key(key)
+}
+
+// fromIndices returns the most suitable Set representation for the given sorted bit indices.
+// indices is not copied, and must not be modified afterwards.
+func fromIndices(indices []uint32) Set {
+	s := sparseBitSet(indices)
+	r := indexRunCount(s)
+	switch w := s.wordLen(); {
+	case w <= 3:
+		return fromWords(s.words())
+	case preferRuns(len(s), r, w):
+		return runBitSet(runsFromIndices(s))
+	case !preferSparse(len(s), w):
+		return denseFromWords(s.words(), len(s), r)
+	case len(s) > maxSparseLen:
+		return chunkedFromIndices(s)
+	}
+	return s
+}
+
+// insert returns a copy of b with the given bit index added. The bit index must not already be in b.
+func (b sparseBitSet) insert(bitIndex uint32) sparseBitSet {
+	i, _ := slices.BinarySearch(b, bitIndex)
+	newIdx := make([]uint32, len(b)+1)
+	copy(newIdx, b[:i])
+	newIdx[i] = bitIndex
+	copy(newIdx[(i+1):], b[i:])
+	return newIdx
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b sparseBitSet) wordLen() int {
+	if len(b) == 0 {
+		return 0
+	}
+	return int(b[len(b)-1]/64) + 1
+}
+
+// words returns the bits of b as a newly allocated word slice.
+func (b sparseBitSet) words() []uint64 {
+	words := make([]uint64, b.wordLen())
+	for _, i := range b {
+		words[i/64] |= 1 << (i % 64)
+	}
+	return words
+}
+
+// sparseFromWords returns the bit indices of all the set bits in words as a sparseBitSet.
+func sparseFromWords(words []uint64) sparseBitSet {
+	s := make([]uint32, 0, popCount(words))
+	for i, w := range words {
+		s = appendBits(s, w, uint32(i*64))
+	}
+	return s
+}
+
+// appendBits appends the bit index of every set bit in w, offset by base, to dst in ascending order.
+func appendBits(dst []uint32, w uint64, base uint32) []uint32 {
+	for w != 0 {
+		dst = append(dst, base+uint32(bits.TrailingZeros64(w)))
+		w &= w - 1
+	}
+	return dst
+}
+
+// gallopRatio is how many times more indices the larger of two sparse sets must have for intersectSparse to look up
+// the indices of the smaller one in it, rather than merging both.
+const gallopRatio = 8
+
+// intersectSparse returns the intersection of a and b, if either is sparse, by looking up each index of the smaller
+// set in the other one, instead of going through all the words of both. It returns false if neither is sparse,
+// or both are sparse and of similar size, where merging them is faster.
+func intersectSparse(a, b Set) (Set, bool) {
+	as, aSparse := a.(sparseBitSet)
+	bs, bSparse := b.(sparseBitSet)
+	switch {
+	case aSparse && bSparse:
+		if len(as) > len(bs) {
+			as, bs = bs, as
+		}
+		if len(as)*gallopRatio > len(bs) {
+			return nil, false
+		}
+		return fromIndices(gallopIntersect(as, bs)), true
+	case bSparse:
+		as, b = bs, a
+	case !aSparse:
+		return nil, false
+	}
+
+	var out []uint32
+	for _, i := range as {
+		if b.Test(i) {
+			out = append(out, i)
+		}
+	}
+	return fromIndices(out), true
+}
+
+// gallopIntersect returns the indices that are in both small and large, which must be sorted.
+// It finds each index of small in large with an exponential search from the previous one, so it takes
+// O(len(small) * log(len(large)/len(small))) time rather than O(len(small) + len(large)).
+func gallopIntersect(small, large []uint32) []uint32 {
+	var out []uint32
+	j := 0
+	for _, x := range small {
+		// Double the step until it passes x, then search the last step
+		lo, step := j, 1
+		for lo+step < len(large) && large[lo+step] < x {
+			lo += step
+			step *= 2
+		}
+		k, found := slices.BinarySearch(large[lo:min(lo+step+1, len(large))], x)
+		if j = lo + k; found {
+			out = append(out, x)
+			j++
+		}
+		if j >= len(large) {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSparseBitSet(t *testing.T) {
+	bs := New().Set(3).Set(2_000_000)
+
+	sbs, ok := bs.(sparseBitSet)
+	if !ok {
+		t.Fatalf("Set with a far away bit should be a sparseBitSet, but got %T", bs)
+	}
+	if len(sbs) != 2 {
+		t.Errorf("sparseBitSet should hold exactly 2 indices, got %d", len(sbs))
+	}
+	if !bs.Test(3) || !bs.Test(2_000_000) {
+		t.Error("sparseBitSet is missing its bits")
+	}
+	if bs.Test(4) || bs.Test(1_999_999) || bs.Test(3_000_000) {
+		t.Error("sparseBitSet has bits that weren't set")
+	}
+
+	// Test immutability on Set
+	bs2 := bs.Set(1_000_000)
+	if bs.Test(1_000_000) {
+		t.Error("Original sparseBitSet should not be modified after Set")
+	}
+	if !bs2.Test(1_000_000) || !bs2.Test(3) || !bs2.Test(2_000_000) {
+		t.Error("New sparseBitSet should have old and new bits")
+	}
+
+	// Test immutability on Clear
+	bs3 := bs2.Clear(1_000_000)
+	if !bs2.Test(1_000_000) {
+		t.Error("Original sparseBitSet should not be modified after Clear")
+	}
+	if bs3.Test(1_000_000) || !bs3.Test(2_000_000) {
+		t.Error("New sparseBitSet should have correct bits after removal")
+	}
+
+	// Setting an existing bit or clearing a missing one is a no-op
+	if _, ok := bs.Set(3).(sparseBitSet); !ok {
+		t.Error("Setting an existing bit should keep the sparseBitSet")
+	}
+	if _, ok := bs.Clear(5).(sparseBitSet); !ok {
+		t.Error("Clearing a missing bit should keep the sparseBitSet")
+	}
+}
+
+func TestSparseBitSetDowngrade(t *testing.T) {
+	bs := New().Set(5).Set(1000)
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet, but got %T", bs)
+	}
+
+	small := bs.Clear(1000)
+	if _, ok := small.(bitSet64); !ok {
+		t.Fatalf("Set should have downgraded to bitSet64, but got %T", small)
+	}
+	if !small.Test(5) || small.Test(1000) {
+		t.Error("Downgraded set has incorrect bits")
+	}
+
+	empty := New().Set(1000).Set(2000).Clear(1000).Clear(2000)
+	if empty != New() {
+		t.Errorf("Set should be empty after removing all its bits, but got %v", empty)
+	}
+}
+
+func TestSparseBitSetDensify(t *testing.T) {
+	// 640 bits span 10 words
+	var bs Set = New().Set(639)
+	for i := uint32(0); i < 3; i++ {
+		bs = bs.Set(i * 64)
+		if _, ok := bs.(sparseBitSet); !ok {
+			t.Fatalf("Set with %d bits over 10 words should be a sparseBitSet, but got %T", i+2, bs)
+		}
+	}
+
+	bs = bs.Set(300)
+	if _, ok := bs.(largeBitSet); !ok {
+		t.Fatalf("Set with 5 bits over 10 words should be a largeBitSet, but got %T", bs)
+	}
+	for _, i := range []uint32{0, 64, 128, 300, 639} {
+		if !bs.Test(i) {
+			t.Errorf("Densified set is missing bit %d", i)
+		}
+	}
+
+	// Clearing bits from the large set makes it sparse again
+	bs = bs.Clear(300)
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Set with 4 bits over 10 words should be a sparseBitSet, but got %T", bs)
+	}
+
+	// Setting a far bit on a large set makes it sparse
+	bs = New().Set(1).Set(100).Set(100_000)
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet after setting a far bit on a largeBitSet, but got %T", bs)
+	}
+	if !bs.Test(1) || !bs.Test(100) || !bs.Test(100_000) {
+		t.Error("Sparsified set has incorrect bits")
+	}
+}
+
+func TestBuilderBuildsSparse(t *testing.T) {
+	bs := NewBuilder(0).WithMany(1, 100_000, 2_000_000).Build()
+	if _, ok := bs.(sparseBitSet); !ok {
+		t.Fatalf("Expected sparseBitSet from builder, but got %T", bs)
+	}
+	if !bs.Test(1) || !bs.Test(100_000) || !bs.Test(2_000_000) || bs.Test(2) {
+		t.Error("Built sparse set has incorrect bits")
+	}
+}
+
+func TestGallopIntersect(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, sizes := range [][2]int{{0, 100}, {1, 1}, {3, 4000}, {10, 100}, {50, 4000}, {100, 100}} {
+		small := randomIndices(r, sizes[0], 1<<20)
+		large := randomIndices(r, sizes[1], 1<<20)
+		large = append(large, small[:len(small)/2]...)
+		slices.Sort(large)
+		large = slices.Compact(large)
+
+		var want []uint32
+		for _, i := range small {
+			if _, ok := slices.BinarySearch(large, i); ok {
+				want = append(want, i)
+			}
+		}
+		if got := gallopIntersect(small, large); !slices.Equal(got, want) {
+			t.Errorf("gallopIntersect of %d and %d indices = %v, want %v", len(small), len(large), got, want)
+		}
+	}
+}
+
+func TestIntersectSparse(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	small := fromIndices(randomIndices(r, 20, 1<<22))
+	others := []Set{fromIndices(randomIndices(r, 3000, 1<<22))}
+	for _, ts := range testSets() {
+		others = append(others, ts.s.Union(FromIndices(slices.Collect(small.Indices())[:10]...)))
+	}
+
+	for _, other := range others {
+		for _, pair := range [][2]Set{{small, other}, {other, small}} {
+			got := pair[0].Intersect(pair[1])
+			want := FromIndices(slices.DeleteFunc(slices.Collect(small.Indices()), func(i uint32) bool { return !other.Test(i) })...)
+			if got.Key() != want.Key() {
+				t.Errorf("Intersect of sparse and %v = %d bits, want %d", other.Kind(), got.Count(), want.Count())
+			}
+			checkCanonical(t, got)
+		}
+	}
+}
+
+// randomIndices returns n distinct sorted random indices below max.
+func randomIndices(r *rand.Rand, n int, max uint32) []uint32 {
+	seen := map[uint32]bool{}
+	for len(seen) < n {
+		seen[r.Uint32N(max)] = true
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
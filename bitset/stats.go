@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// bitset.Stats counts how sets move between representations and copy their words, e.g. to check in production
+// whether a workload keeps growing and shrinking sets across the 64 and 192 bit boundaries.
+//
+// Nothing is recorded until it is passed to SetStats. *Stats implements expvar.Var, so it can be published with
+// expvar.Publish.
+type Stats struct {
+	Upgrades       atomic.Int64 // Set calls that moved a set of up to 192 bits to a larger representation
+	Downgrades     atomic.Int64 // Clear calls that moved a word-based set to a smaller representation
+	Copies         atomic.Int64 // Set and Clear calls, and Builders growing, that copied the words of a word-based set
+	WordsAllocated atomic.Int64 // words allocated by those copies, and by operations between word-based sets
+}
+
+// currentStats is where the counters are recorded, or nil if they are not.
+var currentStats atomic.Pointer[Stats]
+
+// SetStats starts recording counters into s, from all goroutines, or stops recording them if s is nil.
+// Recording costs an atomic add for every event, and an atomic load when it is off.
+func SetStats(s *Stats) {
+	currentStats.Store(s)
+}
+
+// String returns the counters of s as a JSON object, as expvar.Var requires.
+func (s *Stats) String() string {
+	return fmt.Sprintf(`{"Upgrades": %d, "Downgrades": %d, "Copies": %d, "WordsAllocated": %d}`,
+		s.Upgrades.Load(), s.Downgrades.Load(), s.Copies.Load(), s.WordsAllocated.Load())
+}
+
+func recordUpgrade() {
+	if s := currentStats.Load(); s != nil {
+		s.Upgrades.Add(1)
+	}
+}
+
+func recordDowngrade() {
+	if s := currentStats.Load(); s != nil {
+		s.Downgrades.Add(1)
+	}
+}
+
+// recordCopy records a copy of the words of a set into n newly allocated words.
+func recordCopy(n int) {
+	if s := currentStats.Load(); s != nil {
+		s.Copies.Add(1)
+		s.WordsAllocated.Add(int64(n))
+	}
+}
+
+func recordWords(n int) {
+	if s := currentStats.Load(); s != nil {
+		s.WordsAllocated.Add(int64(n))
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	a, b := UnsafeFromWords([]uint64{1, 2, 3, 4}), UnsafeFromWords([]uint64{1, 1, 1, 1, 1, 1})
+
+	var stats Stats
+	SetStats(&stats)
+	defer SetStats(nil)
+
+	s := New().Set(5).Set(100) // upgrade to bitSet192
+	s = s.Set(300)             // upgrade to largeBitSet, copying into 5 words
+	s = s.Set(200)             // copy into 5 words
+	s = s.Clear(300)           // copy into 4 words, without the trailing zero word
+	s = s.Clear(200)           // copy into 2 words, back to bitSet192
+	_ = s.Clear(100)           // back to bitSet64
+	_ = a.Union(b)             // 6 words
+
+	want := map[string]int64{"Upgrades": 2, "Downgrades": 2, "Copies": 4, "WordsAllocated": 5 + 5 + 4 + 2 + 6}
+	got := map[string]int64{
+		"Upgrades":       stats.Upgrades.Load(),
+		"Downgrades":     stats.Downgrades.Load(),
+		"Copies":         stats.Copies.Load(),
+		"WordsAllocated": stats.WordsAllocated.Load(),
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s = %d, want %d", name, got[name], n)
+		}
+	}
+
+	var v expvar.Var = &stats
+	var decoded map[string]int64
+	if err := json.Unmarshal([]byte(v.String()), &decoded); err != nil || decoded["Copies"] != 4 {
+		t.Errorf("String should be a JSON object with the counters, got %s: %v", v.String(), err)
+	}
+
+	SetStats(nil)
+	New().Set(1000)
+	if stats.Upgrades.Load() != 2 {
+		t.Error("Nothing should be recorded after SetStats(nil)")
+	}
+}
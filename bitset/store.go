@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "sync"
+
+// bitset.Store holds a versioned Set for coordinating updates to a shared set across goroutines
+// with optimistic concurrency: read the set and its version with Get, compute the new set,
+// and only publish it with CompareAndSet if nobody else published a newer version in the meantime.
+//
+// A Store is safe for concurrent use.
+type Store struct {
+	mu      sync.RWMutex
+	version uint64
+	s       Set
+	history []Set // previous sets, oldest first. The last one has version-1
+	keep    int
+}
+
+// NewStore creates and returns a new bitset.Store holding s at version 0,
+// which retains the given number of previous versions, which can be retrieved with At.
+func NewStore(s Set, history int) *Store {
+	return &Store{s: s, keep: max(history, 0)}
+}
+
+// Get returns the current set and its version.
+func (st *Store) Get() (Set, uint64) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.s, st.version
+}
+
+// CompareAndSet replaces the current set with s, and increments the version, if the current version is old.
+// It reports whether the set was replaced.
+func (st *Store) CompareAndSet(old uint64, s Set) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.version != old {
+		return false
+	}
+
+	if st.keep > 0 {
+		if len(st.history) == st.keep {
+			st.history = append(st.history[:0], st.history[1:]...)
+		}
+		st.history = append(st.history, st.s)
+	}
+	st.s = s
+	st.version++
+	return true
+}
+
+// Update replaces the current set with the result of calling fn with it, and returns the new set and its version.
+// If another goroutine replaces the set while fn is running, fn is called again with the newer set,
+// so fn may be called more than once and must not have side effects.
+func (st *Store) Update(fn func(Set) Set) (Set, uint64) {
+	for {
+		s, v := st.Get()
+		if s = fn(s); st.CompareAndSet(v, s) {
+			return s, v + 1
+		}
+	}
+}
+
+// At returns the set at the given version, or false if that version is not the current one or a retained previous one.
+func (st *Store) At(version uint64) (Set, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	if version == st.version {
+		return st.s, true
+	}
+	if version > st.version || st.version-version > uint64(len(st.history)) {
+		return nil, false
+	}
+	return st.history[uint64(len(st.history))-(st.version-version)], true
+}
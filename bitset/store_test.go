@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	st := NewStore(New(), 2)
+
+	s, v := st.Get()
+	if v != 0 || s != New() {
+		t.Fatalf("Expected empty set at version 0, got %v at %d", s, v)
+	}
+
+	if !st.CompareAndSet(0, s.Set(1)) {
+		t.Fatal("CompareAndSet with the current version should succeed")
+	}
+	if st.CompareAndSet(0, s.Set(2)) {
+		t.Fatal("CompareAndSet with an old version should fail")
+	}
+	if s, v := st.Get(); v != 1 || !s.Test(1) || s.Test(2) {
+		t.Errorf("Unexpected set %v at version %d", s, v)
+	}
+
+	st.CompareAndSet(1, New().Set(2))
+	st.CompareAndSet(2, New().Set(3))
+
+	// Versions 1 and 2 are retained, version 0 is not
+	for v, bit := range map[uint64]uint32{1: 1, 2: 2, 3: 3} {
+		if s, ok := st.At(v); !ok || !s.Test(bit) {
+			t.Errorf("Version %d should have bit %d, got %v", v, bit, s)
+		}
+	}
+	if _, ok := st.At(0); ok {
+		t.Error("Version 0 should no longer be retained")
+	}
+	if _, ok := st.At(4); ok {
+		t.Error("Future versions should not exist")
+	}
+
+	if _, ok := NewStore(New(), 0).At(0); !ok {
+		t.Error("The current version should always be available")
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	st := NewStore(New(), 0)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 100 {
+				st.Update(func(s Set) Set {
+					return s.Set(uint32(g*100 + i))
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	s, v := st.Get()
+	if v != 800 {
+		t.Errorf("Expected version 800, got %d", v)
+	}
+	for i := range uint32(800) {
+		if !s.Test(i) {
+			t.Fatalf("Update lost bit %d", i)
+		}
+	}
+}
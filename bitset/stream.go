@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"context"
+	"iter"
+	"slices"
+)
+
+const accumulatorBatch = 4096 // indices
+
+// FromSeq returns a bitset.Set with the bits for the bit indices yielded by seq, which may be in any order
+// and contain duplicates, e.g. to collect the output of a pipeline stage without buffering it into a slice first.
+func FromSeq(seq iter.Seq[uint32]) Set {
+	var a accumulator
+	for i := range seq {
+		a.add(i)
+	}
+	return a.build()
+}
+
+// FromChan returns a bitset.Set with the bits for the bit indices received from ch, which may be in any order
+// and contain duplicates. It returns when ch is closed or ctx is done, with the bits received until then,
+// so check ctx.Err() to tell the two apart.
+func FromChan(ctx context.Context, ch <-chan uint32) Set {
+	var a accumulator
+	for {
+		select {
+		case i, ok := <-ch:
+			if !ok {
+				return a.build()
+			}
+			a.add(i)
+		case <-ctx.Done():
+			return a.build()
+		}
+	}
+}
+
+// accumulator collects bit indices that arrive one at a time, in any order.
+// It buffers them as indices, sorting and deduplicating them in batches, and switches to words once
+// the set they make up would be dense.
+type accumulator struct {
+	indices []uint32
+	sorted  int      // the length of indices after it was last sorted and deduplicated
+	words   []uint64 // the bits, once non-nil, in which case indices is unused
+	n       int      // an upper bound on the number of bits in words
+}
+
+func (a *accumulator) add(bitIndex uint32) {
+	if a.words == nil {
+		a.indices = append(a.indices, bitIndex)
+		if len(a.indices) >= max(2*a.sorted, accumulatorBatch) {
+			a.compact()
+		}
+		return
+	}
+
+	idx := int(bitIndex / 64)
+	if idx >= len(a.words) {
+		if preferSparse(a.n+1, idx+1) {
+			// A far away bit would make the words mostly zeros, so go back to indices
+			for i, w := range a.words {
+				a.indices = appendBits(a.indices, w, uint32(i*64))
+			}
+			a.sorted, a.words = len(a.indices), nil
+			a.add(bitIndex)
+			return
+		}
+		a.words = append(a.words, make([]uint64, idx+1-len(a.words))...)
+	}
+	a.words[idx] |= 1 << (bitIndex % 64)
+	a.n++
+}
+
+// compact sorts and deduplicates the buffered indices, and switches to words if they would make up a dense set.
+func (a *accumulator) compact() {
+	slices.Sort(a.indices)
+	a.indices = slices.Compact(a.indices)
+	a.sorted = len(a.indices)
+
+	w := int(a.indices[len(a.indices)-1]/64) + 1
+	if preferSparse(len(a.indices), w) {
+		return
+	}
+	a.words = make([]uint64, w)
+	for _, i := range a.indices {
+		a.words[i/64] |= 1 << (i % 64)
+	}
+	a.n, a.indices, a.sorted = len(a.indices), nil, 0
+}
+
+func (a *accumulator) build() Set {
+	if a.words != nil {
+		return fromWords(a.words)
+	}
+	if len(a.indices) == 0 {
+		return bitSet64(0)
+	}
+	a.compact()
+	if a.words != nil {
+		return fromWords(a.words)
+	}
+	return fromIndices(slices.Clip(a.indices))
+}
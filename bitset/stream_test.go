@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestFromSeq(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tests := map[string][]uint32{
+		"empty":  nil,
+		"small":  {5, 3, 5, 63},
+		"sparse": randomIndices(r, 10000, 1<<30),
+		"dense":  randomIndices(r, 50000, 1<<16),
+		// Dense for long enough to switch to words, then a far away bit that switches back to indices
+		"dense then far": append(randomIndices(r, 50000, 1<<16), 1<<31, 7),
+	}
+	for name, indices := range tests {
+		// Duplicates shouldn't make a difference
+		input := append(slices.Clone(indices), indices...)
+		r.Shuffle(len(input), func(i, j int) { input[i], input[j] = input[j], input[i] })
+
+		got := FromSeq(slices.Values(input))
+		if want := FromIndices(indices...); got.Key() != want.Key() {
+			t.Errorf("%s: expected %d bits, got %d", name, want.Count(), got.Count())
+		}
+		if err := CheckInvariants(got); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestFromChan(t *testing.T) {
+	ch := make(chan uint32)
+	go func() {
+		for i := range uint32(10000) {
+			ch <- i * 3
+		}
+		close(ch)
+	}()
+	if got, want := FromChan(context.Background(), ch), FromStride(0, 3, 10000); got.Key() != want.Key() {
+		t.Errorf("Expected every 3rd bit, got %d bits", got.Count())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = make(chan uint32, 2)
+	ch <- 1
+	ch <- 2
+	done := make(chan Set)
+	go func() { done <- FromChan(ctx, ch) }()
+	for len(ch) > 0 {
+		runtime.Gosched() // wait for FromChan to receive the buffered bits
+	}
+	cancel()
+	if got := <-done; got.Key() != FromIndices(1, 2).Key() {
+		t.Errorf("Expected the bits received before the context was canceled, got %v", slices.Collect(got.Indices()))
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// tagFields caches the tagged fields of each struct type, as a []tagField or an error.
+var tagFields sync.Map // map[reflect.Type]any
+
+// tagField is a bool field of a struct with a bitset tag.
+type tagField struct {
+	index    []int // as used by reflect.Value.FieldByIndex
+	bitIndex uint32
+}
+
+// Marshal returns a bitset.Set with a bit set for every true bool field of v tagged with its bit index,
+// e.g. `bitset:"3"`, so structs of feature flags can be packed without a hand-written table.
+// v must be a struct or a pointer to one. Untagged fields, and fields tagged `bitset:"-"`, are ignored.
+// Fields of embedded structs are included, as with encoding/json.
+//
+// It returns an error if a tag is not a valid bit index, is on a field that is not a bool,
+// or has the same bit index as another.
+func Marshal(v any) (Set, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bitset: Marshal of non-struct type %T", v)
+	}
+
+	fields, err := fieldsOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	var indices []uint32
+	for _, f := range fields {
+		if rv.FieldByIndex(f.index).Bool() {
+			indices = append(indices, f.bitIndex)
+		}
+	}
+	return FromIndices(indices...), nil
+}
+
+// Unmarshal sets every bool field of the struct v points to that is tagged with a bit index, as described by Marshal,
+// to whether that bit is set in s. Bits of s without a field are ignored.
+//
+// It returns an error if v is not a non-nil pointer to a struct, or if its tags are invalid.
+func Unmarshal(s Set, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bitset: Unmarshal into %T, which is not a non-nil pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	fields, err := fieldsOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	s = orEmpty(s)
+	for _, f := range fields {
+		rv.FieldByIndex(f.index).SetBool(s.Test(f.bitIndex))
+	}
+	return nil
+}
+
+// fieldsOf returns the tagged fields of the struct type t, from the cache if possible.
+func fieldsOf(t reflect.Type) ([]tagField, error) {
+	if cached, ok := tagFields.Load(t); ok {
+		if err, ok := cached.(error); ok {
+			return nil, err
+		}
+		return cached.([]tagField), nil
+	}
+
+	fields, err := appendTagFields(nil, t, nil, map[uint32]string{})
+	if err != nil {
+		tagFields.Store(t, err)
+		return nil, err
+	}
+	tagFields.Store(t, fields)
+	return fields, nil
+}
+
+// appendTagFields appends the tagged fields of the struct type t to fields, where index is the index of t
+// in the outermost struct, and seen maps the bit indices found so far to their fields.
+func appendTagFields(fields []tagField, t reflect.Type, index []int, seen map[uint32]string) ([]tagField, error) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		tag, ok := sf.Tag.Lookup("bitset")
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				var err error
+				if fields, err = appendTagFields(fields, sf.Type, fieldIndex, seen); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name := t.Name() + "." + sf.Name
+		bitIndex, err := strconv.ParseUint(tag, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bitset: invalid bit index %q on field %s", tag, name)
+		}
+		if sf.Type.Kind() != reflect.Bool || !sf.IsExported() {
+			return nil, fmt.Errorf("bitset: tagged field %s is not an exported bool", name)
+		}
+		if other, ok := seen[uint32(bitIndex)]; ok {
+			return nil, fmt.Errorf("bitset: fields %s and %s have the same bit index %d", other, name, bitIndex)
+		}
+		seen[uint32(bitIndex)] = name
+		fields = append(fields, tagField{fieldIndex, uint32(bitIndex)})
+	}
+	return fields, nil
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+type auditFlags struct {
+	Logged bool `bitset:"64"`
+}
+
+type features struct {
+	Read     bool `bitset:"0"`
+	Write    bool `bitset:"1"`
+	Admin    bool `bitset:"5"`
+	Internal bool `bitset:"-"`
+	Name     string
+	auditFlags
+}
+
+func TestMarshal(t *testing.T) {
+	f := features{Read: true, Admin: true, Internal: true, Name: "x", auditFlags: auditFlags{Logged: true}}
+	for _, v := range []any{f, &f} {
+		s, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := slices.Collect(s.Indices()); !slices.Equal(got, []uint32{0, 5, 64}) {
+			t.Errorf("Expected bits [0 5 64], got %v", got)
+		}
+	}
+
+	var got features
+	if err := Unmarshal(FromIndices(1, 3, 64), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (features{Write: true, auditFlags: auditFlags{Logged: true}}); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if err := Unmarshal(nil, &got); err != nil || got != (features{}) {
+		t.Errorf("Expected a nil set to clear every field, got %+v, %v", got, err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, v := range []any{
+		nil,
+		42,
+		(*features)(nil),
+		struct {
+			A bool `bitset:"x"`
+		}{},
+		struct {
+			A bool `bitset:"4294967296"`
+		}{},
+		struct {
+			A int `bitset:"1"`
+		}{},
+		struct {
+			a bool `bitset:"1"`
+		}{},
+		struct {
+			A bool `bitset:"1"`
+			B bool `bitset:"1"`
+		}{},
+	} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%#v) should fail", v)
+		}
+	}
+
+	for _, v := range []any{features{}, (*features)(nil), new(int)} {
+		if err := Unmarshal(New(), v); err == nil {
+			t.Errorf("Unmarshal into %T should fail", v)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"iter"
+	"slices"
+)
+
+// Subsets returns an iterator over every subset of mask, from mask itself down to the empty set, e.g. for dynamic
+// programming over subsets. It is the classic sub = (sub-1) & mask loop, borrowing across the words of mask.
+// A mask with n bits has 2^n subsets, so this is only practical for small n.
+func Subsets(mask Set) iter.Seq[Set] {
+	return func(yield func(Set) bool) {
+		maskWords := nonzeroWords(mask)
+		sub := slices.Clone(maskWords)
+		for {
+			if !yield(fromWordList(slices.DeleteFunc(slices.Clone(sub), func(iw indexedWord) bool { return iw.w == 0 }))) {
+				return
+			}
+
+			// Subtract one, where a zero word borrows from the next by becoming all the bits of its mask word
+			k := 0
+			for k < len(sub) && sub[k].w == 0 {
+				sub[k].w = maskWords[k].w
+				k++
+			}
+			if k == len(sub) {
+				return
+			}
+			sub[k].w = (sub[k].w - 1) & maskWords[k].w
+		}
+	}
+}
+
+// Combinations returns an iterator over every set of exactly k bits in [0, n), in ascending order of their words
+// like Gosper's hack, e.g. for exhaustive search. It yields nothing if k is not in [0, n].
+// There are n choose k such sets, so this is only practical for small n or k.
+func Combinations(n uint32, k int) iter.Seq[Set] {
+	return func(yield func(Set) bool) {
+		if k < 0 || uint64(k) > uint64(n) {
+			return
+		}
+
+		// Start with the lowest k bits, and move to the next higher combination by moving up the highest bit of the
+		// lowest run, and moving the rest of the run down to the bottom
+		c := make([]uint32, k)
+		for i := range c {
+			c[i] = uint32(i)
+		}
+		for {
+			if !yield(fromIndices(slices.Clone(c))) {
+				return
+			}
+
+			i := 0
+			for i < k-1 && c[i]+1 == c[i+1] {
+				i++
+			}
+			if i == k || c[i]+1 == n {
+				return
+			}
+			c[i]++
+			for j := range i {
+				c[j] = uint32(j)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "testing"
+
+func TestSubsets(t *testing.T) {
+	masks := []Set{
+		New(),
+		FromIndices(3),
+		FromIndices(0, 1, 2, 63),
+		FromIndices(1, 64, 130, 191, 192, 5000),
+		FromIndices(10, 20, 100_000, 2_000_000, 3_000_000_000),
+	}
+	for _, mask := range masks {
+		seen := map[Key]bool{}
+		var prev Set
+		for sub := range Subsets(mask) {
+			if prev == nil && sub.Key() != mask.Key() {
+				t.Errorf("Subsets of %v should start with the mask", mask)
+			}
+			if !isEmpty(sub.Difference(mask)) {
+				t.Errorf("%v is not a subset of %v", sub, mask)
+			}
+			if seen[sub.Key()] {
+				t.Errorf("Subsets of %v yielded %v twice", mask, sub)
+			}
+			seen[sub.Key()] = true
+			if err := CheckInvariants(sub); err != nil {
+				t.Error(err)
+			}
+			prev = sub
+		}
+		if want := 1 << mask.Count(); len(seen) != want {
+			t.Errorf("Subsets of %d bits yielded %d sets, want %d", mask.Count(), len(seen), want)
+		}
+		if prev.Count() != 0 {
+			t.Errorf("Subsets of %v should end with the empty set", mask)
+		}
+	}
+
+	n := 0
+	for range Subsets(FromIndices(1, 2, 3)) {
+		if n++; n == 3 {
+			break
+		}
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	binomial := func(n, k int) int {
+		c := 1
+		for i := range k {
+			c = c * (n - i) / (i + 1)
+		}
+		return c
+	}
+
+	for _, c := range [][2]int{{0, 0}, {5, 0}, {5, 5}, {5, 2}, {10, 3}, {70, 2}, {130, 3}, {200, 1}} {
+		n, k := c[0], c[1]
+		seen := map[Key]bool{}
+		var prev []uint64
+		for s := range Combinations(uint32(n), k) {
+			if s.Count() != k || k > 0 && s.Test(uint32(n)) {
+				t.Fatalf("Combinations(%d, %d) yielded %v", n, k, s)
+			}
+			if seen[s.Key()] {
+				t.Fatalf("Combinations(%d, %d) yielded %v twice", n, k, s)
+			}
+			seen[s.Key()] = true
+			checkCanonical(t, s)
+
+			// Like Gosper's hack, the sets are in ascending order of their words as a number
+			words := AppendWords(nil, s)
+			if prev != nil && !wordsLess(prev, words) {
+				t.Fatalf("Combinations(%d, %d) is not in ascending order at %v", n, k, s)
+			}
+			prev = words
+		}
+		if want := binomial(n, k); len(seen) != want {
+			t.Errorf("Combinations(%d, %d) yielded %d sets, want %d", n, k, len(seen), want)
+		}
+	}
+
+	for range Combinations(3, 4) {
+		t.Error("Combinations with k > n should yield nothing")
+	}
+	for range Combinations(3, -1) {
+		t.Error("Combinations with k < 0 should yield nothing")
+	}
+}
+
+// wordsLess reports whether a is less than b as a number with the least significant word first.
+func wordsLess(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"slices"
+)
+
+var errInvalidDigest = errors.New("bitset: invalid sync digest")
+
+// SyncDigest is a Merkle tree of the hashes of fixed-size segments of a set, so two nodes holding large replicas of
+// a set can find which segments differ by exchanging digests, or walking them from the root down with Node,
+// and then exchange only those segments instead of whole sets.
+//
+// Leaf i is the hash of the bits in segment i, and every node above is the hash of its two children.
+// Empty segments, and nodes with only empty segments below them, hash to 0, and are not stored,
+// so the size of a digest is proportional to the number of nonempty segments, wherever they are.
+// The hashes are 64-bit FNV-1a, and are the same on every platform,
+// but they are not cryptographic, so they only detect accidental differences.
+//
+// Create a digest with NewSyncDigest, or with UnmarshalBinary on a zero SyncDigest.
+type SyncDigest struct {
+	shift  int            // log2 of the number of bits in a segment
+	levels [][]digestNode // the nonempty nodes of each level in ascending order, from the leaves up to the root
+}
+
+// digestNode is a nonempty node of a SyncDigest.
+type digestNode struct {
+	i uint32
+	h uint64
+}
+
+// NewSyncDigest creates and returns a new bitset.SyncDigest of s, with segments of segmentBits bits.
+// It panics if segmentBits is not a power of two of at least 64.
+func NewSyncDigest(s Set, segmentBits uint32) *SyncDigest {
+	if segmentBits < 64 || segmentBits&(segmentBits-1) != 0 {
+		panic(fmt.Sprintf("bitset: invalid segment size %d", segmentBits))
+	}
+	shift := bits.TrailingZeros32(segmentBits)
+	segWords := int(segmentBits / 64)
+
+	var leaves []digestNode
+	list := nonzeroWords(s)
+	for start := 0; start < len(list); {
+		seg := list[start].i / segWords
+		end := start + 1
+		for end < len(list) && list[end].i/segWords == seg {
+			end++
+		}
+		leaves = append(leaves, digestNode{uint32(seg), hashSegment(list[start:end], segWords)})
+		start = end
+	}
+	return &SyncDigest{shift, buildLevels(leaves, 32-shift)}
+}
+
+// SegmentBits returns the number of bits in each segment of d.
+func (d *SyncDigest) SegmentBits() uint32 {
+	return 1 << d.shift
+}
+
+// Height returns the level of the root of d, which is the number of levels above the leaves.
+// Every digest with the same segment size has the same height, whatever its set.
+func (d *SyncDigest) Height() int {
+	return 32 - d.shift
+}
+
+// Root returns the hash of the root of d, which is equal for digests of sets with the same bits.
+func (d *SyncDigest) Root() uint64 {
+	return d.Node(d.Height(), 0)
+}
+
+// Node returns the hash of node i at the given level of d, where the leaves are at level 0,
+// and the children of node i are nodes 2i and 2i+1 of the level below.
+// It panics if the level is above the root.
+func (d *SyncDigest) Node(level int, i uint32) uint64 {
+	if level < 0 || level > d.Height() {
+		panic(fmt.Sprintf("bitset: sync digest level %d out of range", level))
+	}
+	nodes := d.levels[level]
+	if k, ok := slices.BinarySearchFunc(nodes, i, func(n digestNode, i uint32) int {
+		return cmp.Compare(n.i, i)
+	}); ok {
+		return nodes[k].h
+	}
+	return 0
+}
+
+// Diff returns the indices of the segments that differ between the sets of d and other, in ascending order.
+// It only visits the subtrees whose hashes differ. It panics if the digests have different segment sizes.
+func (d *SyncDigest) Diff(other *SyncDigest) []uint32 {
+	if d.shift != other.shift {
+		panic(fmt.Sprintf("bitset: sync digests have different segment sizes %d and %d", d.SegmentBits(), other.SegmentBits()))
+	}
+
+	var diff []uint32
+	var walk func(level int, i uint32)
+	walk = func(level int, i uint32) {
+		if d.Node(level, i) == other.Node(level, i) {
+			return
+		}
+		if level == 0 {
+			diff = append(diff, i)
+			return
+		}
+		walk(level-1, 2*i)
+		walk(level-1, 2*i+1)
+	}
+	walk(d.Height(), 0)
+	return diff
+}
+
+// Segment returns a new bitset.Set with the bits of s in segment i of d, shifted down so the first bit of the segment
+// is bit 0, to send to a replica whose digest differs in that segment.
+// The original bitset.Set is not modified.
+func (d *SyncDigest) Segment(s Set, i uint32) Set {
+	lo := uint64(i) << d.shift
+	return slice(s, lo, lo+1<<d.shift)
+}
+
+// Patch returns a new bitset.Set with the bits of s in segment i of d replaced by the bits of seg,
+// as returned by Segment on another replica. Bits of seg beyond the segment size are ignored.
+// Neither set is modified.
+func (d *SyncDigest) Patch(s Set, i uint32, seg Set) Set {
+	lo := uint64(i) << d.shift
+	hi := lo + 1<<d.shift
+	return s.Difference(fromRuns([]run[uint32]{{uint32(lo), uint32(hi - 1)}})).Union(shiftUp(slice(seg, 0, 1<<d.shift), lo))
+}
+
+// MarshalBinary returns the base-2 logarithm of the segment size of d as a byte, followed by the index and hash
+// of every nonempty segment, as the uvarint difference from the previous index and a little-endian uint64.
+func (d *SyncDigest) MarshalBinary() ([]byte, error) {
+	data := []byte{byte(d.shift)}
+	prev := int64(-1)
+	for _, n := range d.levels[0] {
+		data = binary.AppendUvarint(data, uint64(int64(n.i)-prev))
+		data = binary.LittleEndian.AppendUint64(data, n.h)
+		prev = int64(n.i)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets d to the digest in data, in the format written by MarshalBinary.
+func (d *SyncDigest) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] < 6 || data[0] > 31 {
+		return errInvalidDigest
+	}
+	shift := int(data[0])
+	data = data[1:]
+
+	// Every leaf takes at least 9 bytes, so the digest is proportional to the size of data
+	leaves := make([]digestNode, 0, len(data)/9)
+	i := int64(-1)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		// The index must be above the previous one, and below the number of segments
+		if n <= 0 || delta == 0 || len(data) < n+8 || delta > uint64(int64(1)<<(32-shift)-1-i) {
+			return errInvalidDigest
+		}
+		i += int64(delta)
+		h := binary.LittleEndian.Uint64(data[n:])
+		if h == 0 {
+			return errInvalidDigest
+		}
+		leaves = append(leaves, digestNode{uint32(i), h})
+		data = data[n+8:]
+	}
+	*d = SyncDigest{shift, buildLevels(leaves, 32-shift)}
+	return nil
+}
+
+// buildLevels returns the nonempty nodes of every level of a tree of the given height, from the nonempty leaves up.
+func buildLevels(leaves []digestNode, height int) [][]digestNode {
+	levels := [][]digestNode{leaves}
+	for cur := leaves; len(levels) <= height; {
+		var next []digestNode
+		for k := 0; k < len(cur); k++ {
+			// The children of a node are next to each other, if both are nonempty
+			left, right := uint64(0), uint64(0)
+			if cur[k].i%2 == 0 {
+				left = cur[k].h
+				if k+1 < len(cur) && cur[k+1].i == cur[k].i+1 {
+					k++
+					right = cur[k].h
+				}
+			} else {
+				right = cur[k].h
+			}
+			next = append(next, digestNode{cur[k].i / 2, hashPair(left, right)})
+		}
+		levels = append(levels, next)
+		cur = next
+	}
+	return levels
+}
+
+// hashSegment returns the hash of a segment of segWords words, given its nonzero words, which is never 0.
+// It hashes the offset of every nonzero word in the segment along with the word,
+// so it doesn't depend on the representation of the set.
+func hashSegment(words []indexedWord, segWords int) uint64 {
+	h := fnv.New64a()
+	var buf [12]byte
+	for _, iw := range words {
+		binary.LittleEndian.PutUint32(buf[:], uint32(iw.i%segWords))
+		binary.LittleEndian.PutUint64(buf[4:], iw.w)
+		h.Write(buf[:])
+	}
+	return nonzeroHash(h.Sum64())
+}
+
+// hashPair returns the hash of a node with the given children, which is 0 if both are 0, and never 0 otherwise.
+func hashPair(left, right uint64) uint64 {
+	if left == 0 && right == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:], left)
+	binary.LittleEndian.PutUint64(buf[8:], right)
+	h.Write(buf[:])
+	return nonzeroHash(h.Sum64())
+}
+
+// nonzeroHash maps a hash of 0, which is reserved for empty segments, to 1.
+func nonzeroHash(h uint64) uint64 {
+	if h == 0 {
+		return 1
+	}
+	return h
+}
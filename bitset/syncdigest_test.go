@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSyncDigest(t *testing.T) {
+	r := rand.New(rand.NewPCG(7, 8))
+	const segmentBits = 256
+	local := FromIndices(randomIndices(r, 2000, 1<<16)...)
+
+	// Change a few segments of the replica, including some beyond the highest bit of local
+	lowest := nthBit(local, 0)
+	remote := local.Clear(lowest)
+	changed := []uint32{lowest / segmentBits}
+	for _, seg := range []uint32{3, 40, 41, 255, 1000, 1 << 16} {
+		remote = remote.Set(seg*segmentBits + uint32(r.IntN(segmentBits)))
+		changed = append(changed, seg)
+	}
+	slices.Sort(changed)
+	changed = slices.Compact(changed)
+
+	dl, dr := NewSyncDigest(local, segmentBits), NewSyncDigest(remote, segmentBits)
+	if dl.Root() == dr.Root() {
+		t.Fatal("Expected different roots")
+	}
+	if got := dl.Diff(dr); !slices.Equal(got, changed) {
+		t.Fatalf("Diff = %v, expected %v", got, changed)
+	}
+	if got := dr.Diff(dl); !slices.Equal(got, changed) {
+		t.Fatalf("Reverse Diff = %v, expected %v", got, changed)
+	}
+
+	synced := local
+	for _, seg := range dl.Diff(dr) {
+		synced = dl.Patch(synced, seg, dr.Segment(remote, seg))
+	}
+	if !isEmpty(synced.SymmetricDifference(remote)) {
+		t.Errorf("Expected the patched set to equal the remote set")
+	}
+	if d := NewSyncDigest(synced, segmentBits); d.Root() != dr.Root() || len(d.Diff(dr)) != 0 {
+		t.Errorf("Expected the patched set to have the remote digest")
+	}
+}
+
+func TestSyncDigestRepresentations(t *testing.T) {
+	for _, ts := range testSets() {
+		d := NewSyncDigest(ts.s, 1024)
+		for _, other := range []Set{
+			FromIndices(slices.Collect(ts.s.Indices())...),
+			WithShrinkPolicy(ts.s, ShrinkNever).Set(1 << 20).Clear(1 << 20),
+		} {
+			if od := NewSyncDigest(other, 1024); od.Root() != d.Root() {
+				t.Errorf("%s: Expected the same root for a %v set", ts.name, other.Kind())
+			}
+		}
+		if isEmpty(ts.s) != (d.Root() == 0) {
+			t.Errorf("%s: Root = %#x, expected 0 only for the empty set", ts.name, d.Root())
+		}
+	}
+}
+
+func TestSyncDigestMarshalBinary(t *testing.T) {
+	r := rand.New(rand.NewPCG(9, 10))
+	for _, tc := range []struct {
+		s           Set
+		segmentBits uint32
+	}{
+		{FromIndices(), 64},
+		{FromIndices(randomIndices(r, 500, 1<<20)...), 64},
+		{FromIndices(0, 1<<32-1), 1 << 16},
+	} {
+		d := NewSyncDigest(tc.s, tc.segmentBits)
+		data, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got SyncDigest
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary: %v", err)
+		}
+		if got.SegmentBits() != tc.segmentBits || got.Root() != d.Root() || len(got.Diff(d)) != 0 {
+			t.Errorf("Expected the unmarshaled digest to equal the original")
+		}
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		{5},
+		{32},
+		{6, 1},
+		{6, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+		{6, 0, 1, 0, 0, 0, 0, 0, 0, 0},
+		{31, 3, 1, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		var d SyncDigest
+		if err := d.UnmarshalBinary(data); err == nil {
+			t.Errorf("Expected an error for %v", data)
+		}
+	}
+}
+
+func TestSyncDigestUnmarshalBinaryHugeIndex(t *testing.T) {
+	// A single segment near the end of a digest of 2^26 segments must not allocate a leaf for every segment before it
+	data := binary.AppendUvarint([]byte{6}, 1<<26)
+	data = binary.LittleEndian.AppendUint64(data, 1)
+
+	var d SyncDigest
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<16 {
+		t.Errorf("UnmarshalBinary of %d bytes allocated %d bytes", len(data), n)
+	}
+	if got := d.Diff(NewSyncDigest(FromIndices(), 64)); !slices.Equal(got, []uint32{1<<26 - 1}) {
+		t.Errorf("Diff = %v, expected [%d]", got, 1<<26-1)
+	}
+
+	// The same goes for a set with a single high bit
+	runtime.ReadMemStats(&before)
+	d2 := NewSyncDigest(FromIndices(1<<32-1), 64)
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<16 {
+		t.Errorf("NewSyncDigest of a single high bit allocated %d bytes", n)
+	}
+	if d2.Root() == 0 {
+		t.Error("Expected a nonzero root")
+	}
+}
+
+func TestSyncDigestPanics(t *testing.T) {
+	for _, n := range []uint32{0, 32, 100} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for segment size %d", n)
+				}
+			}()
+			NewSyncDigest(FromIndices(1), n)
+		}()
+	}
+}
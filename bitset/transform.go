@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// Filter returns a new bitset.Set with the bits of s for which pred returns true.
+// pred is called once for every set bit, in ascending order.
+// The original bitset.Set is not modified.
+func Filter(s Set, pred func(bitIndex uint32) bool) Set {
+	if words, ok := denseWords(s); ok {
+		newBits := make([]uint64, len(words))
+		for i, w := range words {
+			newBits[i] = filterWord(w, uint32(i*64), pred)
+		}
+		if r, ok := s.(retainedBitSet); ok {
+			return r.retain(newBits)
+		}
+		return fromWords(newBits)
+	}
+
+	var indices []uint32
+	for i := range s.Indices() {
+		if pred(i) {
+			indices = append(indices, i)
+		}
+	}
+	return fromIndices(slices.Clip(indices))
+}
+
+// Partition returns two new bitset.Sets with the bits of s for which pred returns true and false respectively,
+// in a single pass. pred is called once for every set bit, in ascending order.
+// The original bitset.Set is not modified.
+func Partition(s Set, pred func(bitIndex uint32) bool) (pass, fail Set) {
+	if words, ok := denseWords(s); ok {
+		passBits, failBits := make([]uint64, len(words)), make([]uint64, len(words))
+		for i, w := range words {
+			passBits[i] = filterWord(w, uint32(i*64), pred)
+			failBits[i] = w &^ passBits[i]
+		}
+		if r, ok := s.(retainedBitSet); ok {
+			return r.retain(passBits), r.retain(failBits)
+		}
+		return fromWords(passBits), fromWords(failBits)
+	}
+
+	var passIdx, failIdx []uint32
+	for i := range s.Indices() {
+		if pred(i) {
+			passIdx = append(passIdx, i)
+		} else {
+			failIdx = append(failIdx, i)
+		}
+	}
+	return fromIndices(slices.Clip(passIdx)), fromIndices(slices.Clip(failIdx))
+}
+
+// filterWord returns the bits of w for which pred returns true, where bit j of w is the bit index base+j.
+func filterWord(w uint64, base uint32, pred func(uint32) bool) uint64 {
+	out := w
+	for w != 0 {
+		j := bits.TrailingZeros64(w)
+		if !pred(base + uint32(j)) {
+			out &^= 1 << j
+		}
+		w &= w - 1
+	}
+	return out
+}
+
+// Map returns a new bitset.Set with the bit fn(i) set for every set bit i of s, e.g. to translate local ids to global ones.
+// fn is called once for every set bit, in ascending order, and may map several bits to the same one.
+// The original bitset.Set is not modified.
+func Map(s Set, fn func(bitIndex uint32) uint32) Set {
+	indices := make([]uint32, 0, s.Count())
+	for i := range s.Indices() {
+		indices = append(indices, fn(i))
+	}
+	if !slices.IsSorted(indices) {
+		slices.Sort(indices)
+	}
+	return fromIndices(slices.Clip(slices.Compact(indices)))
+}
+
+// Any reports whether pred returns true for any set bit of s. It stops at the first bit that passes.
+func Any(s Set, pred func(bitIndex uint32) bool) bool {
+	for i := range s.Indices() {
+		if pred(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// All reports whether pred returns true for every set bit of s, which is true if s is empty.
+// It stops at the first bit that fails.
+func All(s Set, pred func(bitIndex uint32) bool) bool {
+	return !Any(s, func(i uint32) bool { return !pred(i) })
+}
+
+// None reports whether pred returns false for every set bit of s, which is true if s is empty.
+// It stops at the first bit that passes.
+func None(s Set, pred func(bitIndex uint32) bool) bool {
+	return !Any(s, pred)
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	even := func(i uint32) bool { return i%2 == 0 }
+	for _, ts := range testSets() {
+		got := Filter(ts.s, even)
+		var want []uint32
+		for i := range ts.s.Indices() {
+			if even(i) {
+				want = append(want, i)
+			}
+		}
+		if !slices.Equal(slices.Collect(got.Indices()), want) {
+			t.Errorf("%s: Filter returned the wrong bits", ts.name)
+		}
+		checkCanonical(t, got)
+	}
+
+	var calls []uint32
+	Filter(FromIndices(200, 3, 70), func(i uint32) bool {
+		calls = append(calls, i)
+		return true
+	})
+	if !slices.Equal(calls, []uint32{3, 70, 200}) {
+		t.Errorf("pred should be called for every set bit in ascending order, got %v", calls)
+	}
+}
+
+func TestMap(t *testing.T) {
+	for _, ts := range testSets() {
+		got := Map(ts.s, func(i uint32) uint32 { return i/2 + 10 })
+		want := map[uint32]bool{}
+		for i := range ts.s.Indices() {
+			want[i/2+10] = true
+		}
+		if got.Count() != len(want) {
+			t.Errorf("%s: Map returned %d bits, want %d", ts.name, got.Count(), len(want))
+		}
+		for i := range got.Indices() {
+			if !want[i] {
+				t.Errorf("%s: Map returned unexpected bit %d", ts.name, i)
+				break
+			}
+		}
+		checkCanonical(t, got)
+	}
+
+	// Bits can be mapped out of order
+	got := Map(FromIndices(0, 1, 2), func(i uint32) uint32 { return 1000 - i*100 })
+	if !slices.Equal(slices.Collect(got.Indices()), []uint32{800, 900, 1000}) {
+		t.Errorf("Map with a decreasing function = %v", slices.Collect(got.Indices()))
+	}
+}
+
+func TestAnyAllNone(t *testing.T) {
+	s := FromIndices(2, 4, 100)
+	even := func(i uint32) bool { return i%2 == 0 }
+	big := func(i uint32) bool { return i >= 100 }
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"Any(even)", Any(s, even), true},
+		{"All(even)", All(s, even), true},
+		{"None(even)", None(s, even), false},
+		{"Any(big)", Any(s, big), true},
+		{"All(big)", All(s, big), false},
+		{"None(big)", None(s, big), false},
+		{"Any(empty)", Any(New(), even), false},
+		{"All(empty)", All(New(), even), true},
+		{"None(empty)", None(New(), even), true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	calls := 0
+	Any(s, func(i uint32) bool {
+		calls++
+		return true
+	})
+	if calls != 1 {
+		t.Errorf("Any should stop at the first bit that passes, pred was called %d times", calls)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	even := func(i uint32) bool { return i%2 == 0 }
+	for _, ts := range testSets() {
+		pass, fail := Partition(ts.s, even)
+		if pass.Key() != Filter(ts.s, even).Key() {
+			t.Errorf("%s: the passing bits of Partition differ from Filter", ts.name)
+		}
+		if !isEmpty(pass.Intersect(fail)) || pass.Union(fail).Key() != ts.s.Key() {
+			t.Errorf("%s: Partition should split the bits into two disjoint sets", ts.name)
+		}
+		checkCanonical(t, pass)
+		checkCanonical(t, fail)
+	}
+}
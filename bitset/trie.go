@@ -0,0 +1,515 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+	"unsafe"
+)
+
+const (
+	trieLeafWords = 16 // words in a trieLeaf
+	trieFanout    = 32 // children of a trieNode
+	trieLevelBits = 5  // log2(trieFanout)
+	trieMinWords  = 1024
+)
+
+// Persistent trie (big dense sets)
+type trieBitSet struct {
+	n      int // number of set bits
+	r      int // number of runs of set bits
+	w      int // number of words the equivalent largeBitSet would have, always >= trieMinWords
+	height int // number of levels of trieNodes above the leaves
+	root   *trieNode
+}
+
+// trieNode is an immutable inner node of a trieBitSet, shared between all sets that contain it.
+// Its children are *trieLeaf at height 1, and *trieNode above that. Children without any set bits are nil.
+type trieNode [trieFanout]any
+
+// trieLeaf is an immutable leaf of a trieBitSet, shared between all sets that contain it.
+type trieLeaf [trieLeafWords]uint64
+
+// trieCapacity returns the number of words a trie of the given height can hold.
+func trieCapacity(height int) int {
+	return trieLeafWords << (trieLevelBits * height)
+}
+
+// childIndex returns the index of the child of a node at the given height that holds the word at wordIdx.
+func childIndex(wordIdx, height int) int {
+	return (wordIdx / trieLeafWords >> (trieLevelBits * (height - 1))) % trieFanout
+}
+
+func (b trieBitSet) Test(bitIndex uint32) bool {
+	wordIdx := int(bitIndex / 64)
+	if wordIdx >= b.w {
+		return false
+	}
+
+	var child any = b.root
+	for h := b.height; h > 0; h-- {
+		child = child.(*trieNode)[childIndex(wordIdx, h)]
+		if child == nil {
+			return false
+		}
+	}
+	return child.(*trieLeaf)[wordIdx%trieLeafWords]&(1<<(bitIndex%64)) != 0
+}
+
+func (b trieBitSet) Set(bitIndex uint32) Set {
+	if b.Test(bitIndex) {
+		return b
+	}
+
+	wordIdx := int(bitIndex / 64)
+	root, height := b.root, b.height
+	for trieCapacity(height) <= wordIdx {
+		root = &trieNode{root}
+		height++
+	}
+
+	newB := trieBitSet{
+		n:      b.n + 1,
+		r:      b.r + 1 - b.neighbors(bitIndex),
+		w:      max(b.w, wordIdx+1),
+		height: height,
+		root:   trieUpdate(root, height, wordIdx, func(w uint64) uint64 { return w | 1<<(bitIndex%64) }).(*trieNode),
+	}
+	return newB.normalize()
+}
+
+func (b trieBitSet) Clear(bitIndex uint32) Set {
+	if !b.Test(bitIndex) {
+		return b
+	}
+
+	wordIdx := int(bitIndex / 64)
+	newB := trieBitSet{
+		n:      b.n - 1,
+		r:      b.r - 1 + b.neighbors(bitIndex),
+		w:      b.w,
+		height: b.height,
+	}
+	root := trieUpdate(b.root, b.height, wordIdx, func(w uint64) uint64 { return w &^ (1 << (bitIndex % 64)) })
+	if root == nil {
+		return bitSet64(0)
+	}
+	newB.root = root.(*trieNode)
+
+	if wordIdx == b.w-1 {
+		newB.w = newB.highestWord() + 1
+	}
+	// Drop levels that are no longer needed
+	for newB.height > 1 && trieCapacity(newB.height-1) >= newB.w {
+		newB.root = newB.root[0].(*trieNode)
+		newB.height--
+	}
+	return newB.normalize()
+}
+
+func (b trieBitSet) Union(other Set) Set {
+	return combine(b, other, or)
+}
+
+func (b trieBitSet) Intersect(other Set) Set {
+	return combine(b, other, and)
+}
+
+func (b trieBitSet) Difference(other Set) Set {
+	return combine(b, other, andNot)
+}
+
+func (b trieBitSet) SymmetricDifference(other Set) Set {
+	return combine(b, other, xor)
+}
+
+// Count converts n through uint, as it wraps around on 32-bit platforms when there are more than 2^31-1 bits.
+func (b trieBitSet) Count() int {
+	return clampCount(uint64(uint(b.n)))
+}
+
+func (b trieBitSet) Indices() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		yieldLeaves(b.root, b.height, 0, yield)
+	}
+}
+
+func (b trieBitSet) SizeInBytes() int {
+	size := int(unsafe.Sizeof(b))
+	var walk func(child any, height int)
+	walk = func(child any, height int) {
+		if height == 0 {
+			size += int(unsafe.Sizeof(trieLeaf{}))
+			return
+		}
+		size += int(unsafe.Sizeof(trieNode{}))
+		for _, c := range child.(*trieNode) {
+			if c != nil {
+				walk(c, height-1)
+			}
+		}
+	}
+	walk(b.root, b.height)
+	return size
+}
+
+func (b trieBitSet) Kind() Kind {
+	return KindTrie
+}
+
+func (b trieBitSet) Key() Key {
+	return Key(appendWords(newKey(KindTrie, 8*b.w), b.words()))
+}
+
+// wordLen returns the number of words the equivalent largeBitSet would have.
+func (b trieBitSet) wordLen() int {
+	return b.w
+}
+
+// trieUpdate returns a copy of the subtrie rooted at child, at the given height, with fn applied to the word at wordIdx.
+// Only the nodes on the path to the word are copied. Returns nil if the resulting subtrie has no set bits.
+func trieUpdate(child any, height, wordIdx int, fn func(uint64) uint64) any {
+	if height == 0 {
+		var leaf trieLeaf
+		if child != nil {
+			leaf = *child.(*trieLeaf)
+		}
+		leaf[wordIdx%trieLeafWords] = fn(leaf[wordIdx%trieLeafWords])
+		if leaf == (trieLeaf{}) {
+			return nil
+		}
+		return &leaf
+	}
+
+	var node trieNode
+	if child != nil {
+		node = *child.(*trieNode)
+	}
+	i := childIndex(wordIdx, height)
+	node[i] = trieUpdate(node[i], height-1, wordIdx, fn)
+	if node == (trieNode{}) {
+		return nil
+	}
+	return &node
+}
+
+// word returns the word at wordIdx in b.
+func (b trieBitSet) word(wordIdx int) uint64 {
+	var child any = b.root
+	for h := b.height; h > 0; h-- {
+		child = child.(*trieNode)[childIndex(wordIdx, h)]
+		if child == nil {
+			return 0
+		}
+	}
+	return child.(*trieLeaf)[wordIdx%trieLeafWords]
+}
+
+// neighbors returns how many of the bits adjacent to the given bit index are set.
+func (b trieBitSet) neighbors(bitIndex uint32) int {
+	n := 0
+	if bitIndex > 0 && b.Test(bitIndex-1) {
+		n++
+	}
+	if bitIndex < 1<<32-1 && b.Test(bitIndex+1) {
+		n++
+	}
+	return n
+}
+
+// highestWord returns the index of the highest nonzero word in b.
+func (b trieBitSet) highestWord() int {
+	var child any = b.root
+	wordIdx := 0
+	for h := b.height; h > 0; h-- {
+		node := child.(*trieNode)
+		i := trieFanout - 1
+		for node[i] == nil {
+			i--
+		}
+		child = node[i]
+		wordIdx += i * trieCapacity(h-1)
+	}
+
+	leaf := child.(*trieLeaf)
+	i := trieLeafWords - 1
+	for leaf[i] == 0 {
+		i--
+	}
+	return wordIdx + i
+}
+
+// normalize returns b, or the equivalent Set of another representation if that is more suitable.
+func (b trieBitSet) normalize() Set {
+	switch {
+	case preferRuns(b.n, b.r, b.w):
+		return runBitSet(b.toRuns())
+	case preferSparse(b.n, b.w):
+		return fromIndices(b.indices())
+	case b.w < trieMinWords:
+		return fromWords(b.words())
+	}
+	return b
+}
+
+// forEachLeaf calls fn with the index of the first word of every leaf in b, in ascending order.
+func (b trieBitSet) forEachLeaf(fn func(wordIdx int, leaf *trieLeaf)) {
+	walkLeaves(b.root, b.height, 0, fn)
+}
+
+// appendTrieIndices appends the bit indices of the set bits in the subtrie rooted at child to dst.
+// child is at the given height, and its first word has index wordIdx.
+func appendTrieIndices(dst []uint32, child any, height, wordIdx int) []uint32 {
+	if height == 0 {
+		for i, w := range child.(*trieLeaf) {
+			dst = appendBits(dst, w, uint32((wordIdx+i)*64))
+		}
+		return dst
+	}
+
+	capacity := trieCapacity(height - 1)
+	for i, c := range child.(*trieNode) {
+		if c != nil {
+			dst = appendTrieIndices(dst, c, height-1, wordIdx+i*capacity)
+		}
+	}
+	return dst
+}
+
+// walkLeaves calls fn with the index of the first word of every leaf in the subtrie rooted at child, in ascending order.
+// child is at the given height, and its first word has index wordIdx.
+func walkLeaves(child any, height, wordIdx int, fn func(wordIdx int, leaf *trieLeaf)) {
+	if height == 0 {
+		fn(wordIdx, child.(*trieLeaf))
+		return
+	}
+	for i, c := range child.(*trieNode) {
+		if c != nil {
+			walkLeaves(c, height-1, wordIdx+i*trieCapacity(height-1), fn)
+		}
+	}
+}
+
+// yieldLeaves calls yield with every set bit in the subtrie rooted at child in ascending order, until yield returns false.
+// child is at the given height, and its first word has index wordIdx. It reports whether yield always returned true.
+func yieldLeaves(child any, height, wordIdx int, yield func(uint32) bool) bool {
+	if height == 0 {
+		return yieldWords(child.(*trieLeaf)[:], uint32(wordIdx*64), yield)
+	}
+	for i, c := range child.(*trieNode) {
+		if c != nil && !yieldLeaves(c, height-1, wordIdx+i*trieCapacity(height-1), yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// words returns the bits of b as a newly allocated word slice.
+func (b trieBitSet) words() []uint64 {
+	words := make([]uint64, b.w)
+	b.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+		copy(words[wordIdx:], leaf[:])
+	})
+	return words
+}
+
+// indices returns the bit indices of all the set bits in b.
+func (b trieBitSet) indices() []uint32 {
+	s := make([]uint32, 0, b.n)
+	b.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+		for i, w := range leaf {
+			s = appendBits(s, w, uint32((wordIdx+i)*64))
+		}
+	})
+	return s
+}
+
+// toRuns returns the runs of set bits in b.
+func (b trieBitSet) toRuns() []run[uint32] {
+	runs := make([]run[uint32], 0, b.r)
+	b.forEachLeaf(func(wordIdx int, leaf *trieLeaf) {
+		runs = appendWordRuns(runs, leaf[:], uint32(wordIdx*64))
+	})
+	return runs
+}
+
+// trieFromWords returns the bits in words, which must not have trailing zero words, as a trieBitSet.
+// n and r are the number of set bits and runs in words.
+func trieFromWords(words []uint64, n, r int) trieBitSet {
+	b := trieBitSet{n: n, r: r, w: len(words), height: 1}
+	for trieCapacity(b.height) < len(words) {
+		b.height++
+	}
+
+	// Build the leaves, then each level of nodes above them
+	level := make([]any, (len(words)+trieLeafWords-1)/trieLeafWords)
+	for i := range level {
+		var leaf trieLeaf
+		if copy(leaf[:], words[i*trieLeafWords:]); leaf != (trieLeaf{}) {
+			level[i] = &leaf
+		}
+	}
+	for range b.height {
+		parents := make([]any, (len(level)+trieFanout-1)/trieFanout)
+		for i := range parents {
+			var node trieNode
+			if copy(node[:], level[i*trieFanout:]); node != (trieNode{}) {
+				parents[i] = &node
+			}
+		}
+		level = parents
+	}
+
+	b.root = level[0].(*trieNode)
+	return b
+}
+
+// denseFromWords returns words, which must not have trailing zero words, as a largeBitSet,
+// or as a trieBitSet if it is big enough that copying all of it on every modification would be too expensive.
+// n and r are the number of set bits and runs in words.
+func denseFromWords(words []uint64, n, r int) Set {
+	if len(words) >= trieMinWords {
+		return trieFromWords(words, n, r)
+	}
+	return largeBitSet(words)
+}
+
+// trieStats is the number of set bits and runs in a subtrie, along with its lowest and highest nonzero words,
+// to add up the counts of adjacent subtries.
+type trieStats struct {
+	n, r        int
+	first, last indexedWord // only valid if n > 0
+}
+
+// join returns the stats of the subtries of s and next together, where next is after s.
+func (s trieStats) join(next trieStats) trieStats {
+	switch {
+	case s.n == 0:
+		return next
+	case next.n == 0:
+		return s
+	}
+
+	r := s.r + next.r
+	if s.last.i+1 == next.first.i && s.last.w>>63 != 0 && next.first.w&1 != 0 {
+		// A run continues into next
+		r--
+	}
+	return trieStats{s.n + next.n, r, s.first, next.last}
+}
+
+// subtrieStats returns the stats of the subtrie rooted at child, at the given height, whose first word has index wordIdx.
+func subtrieStats(child any, height, wordIdx int) trieStats {
+	var stats trieStats
+	if child != nil {
+		walkLeaves(child, height, wordIdx, func(wordIdx int, leaf *trieLeaf) {
+			stats = stats.join(leafStats(leaf, wordIdx))
+		})
+	}
+	return stats
+}
+
+// leafStats returns the stats of leaf, whose first word has index wordIdx.
+func leafStats(leaf *trieLeaf, wordIdx int) trieStats {
+	var stats trieStats
+	var carry uint64
+	for i, w := range leaf {
+		if w != 0 {
+			if stats.n == 0 {
+				stats.first = indexedWord{wordIdx + i, w}
+			}
+			stats.last = indexedWord{wordIdx + i, w}
+			stats.n += bits.OnesCount64(w)
+			stats.r += bits.OnesCount64(w &^ (w<<1 | carry))
+		}
+		carry = w >> 63
+	}
+	return stats
+}
+
+// trieCombine returns the result of applying op to the subtries rooted at a and b, which are both at the given height,
+// with their first word at wordIdx, along with its stats. Either may be nil.
+// Subtries of the result that are equal to one of a or b are shared with it rather than copied,
+// so combining with a small set only copies the paths to its words.
+func trieCombine(a, b any, height, wordIdx int, op wordOp) (any, trieStats) {
+	// op keeps or clears each side where the other is empty, and both sides where they are the same subtrie
+	switch {
+	case a == nil && b == nil:
+		return nil, trieStats{}
+	case a == b:
+		if op(^uint64(0), ^uint64(0)) == 0 {
+			return nil, trieStats{}
+		}
+		return a, subtrieStats(a, height, wordIdx)
+	case b == nil:
+		if op(^uint64(0), 0) == 0 {
+			return nil, trieStats{}
+		}
+		return a, subtrieStats(a, height, wordIdx)
+	case a == nil:
+		if op(0, ^uint64(0)) == 0 {
+			return nil, trieStats{}
+		}
+		return b, subtrieStats(b, height, wordIdx)
+	}
+
+	if height == 0 {
+		aLeaf, bLeaf := a.(*trieLeaf), b.(*trieLeaf)
+		var leaf trieLeaf
+		for i := range leaf {
+			leaf[i] = op(aLeaf[i], bLeaf[i])
+		}
+		switch leaf {
+		case trieLeaf{}:
+			return nil, trieStats{}
+		case *aLeaf:
+			return a, leafStats(aLeaf, wordIdx)
+		case *bLeaf:
+			return b, leafStats(bLeaf, wordIdx)
+		}
+		return &leaf, leafStats(&leaf, wordIdx)
+	}
+
+	aNode, bNode := a.(*trieNode), b.(*trieNode)
+	var node trieNode
+	var stats trieStats
+	for i := range node {
+		var childStats trieStats
+		node[i], childStats = trieCombine(aNode[i], bNode[i], height-1, wordIdx+i*trieCapacity(height-1), op)
+		stats = stats.join(childStats)
+	}
+	switch node {
+	case trieNode{}:
+		return nil, trieStats{}
+	case *aNode:
+		return a, stats
+	case *bNode:
+		return b, stats
+	}
+	return &node, stats
+}
+
+// withHeight returns the root of b at the given height, which must be at least its height, adding levels above it.
+func (b trieBitSet) withHeight(height int) *trieNode {
+	root := b.root
+	for h := b.height; h < height; h++ {
+		root = &trieNode{root}
+	}
+	return root
+}
+
+// trieFromRoot returns the trie with the given root and stats, dropping the levels above it that it doesn't need.
+func trieFromRoot(root *trieNode, height int, stats trieStats) Set {
+	if stats.n == 0 {
+		return bitSet64(0)
+	}
+
+	b := trieBitSet{n: stats.n, r: stats.r, w: stats.last.i + 1, height: height, root: root}
+	for b.height > 1 && trieCapacity(b.height-1) >= b.w {
+		b.root = b.root[0].(*trieNode)
+		b.height--
+	}
+	return b.normalize()
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// everyThird returns a set with every third bit in [0, n) set.
+func everyThird(n uint32) Set {
+	b := NewBuilder(int(n))
+	for i := uint32(0); i < n; i += 3 {
+		b = b.With(i)
+	}
+	return b.Build()
+}
+
+func TestTrieBitSet(t *testing.T) {
+	bs := everyThird(70_000)
+
+	tbs, ok := bs.(trieBitSet)
+	if !ok {
+		t.Fatalf("Expected trieBitSet for a big dense set, but got %T", bs)
+	}
+	if tbs.w != 70_000/64+1 || tbs.n != 70_000/3+1 || tbs.r != tbs.n {
+		t.Errorf("trieBitSet has incorrect header: %+v", tbs)
+	}
+	for i := uint32(0); i < 71_000; i++ {
+		if bs.Test(i) != (i < 70_000 && i%3 == 0) {
+			t.Fatalf("trieBitSet has incorrect bit %d", i)
+		}
+	}
+
+	// Test immutability on Set
+	bs2 := bs.Set(1)
+	if bs.Test(1) {
+		t.Error("Original trieBitSet should not be modified after Set")
+	}
+	if !bs2.Test(1) || !bs2.Test(0) || !bs2.Test(69_999) {
+		t.Error("New trieBitSet should have old and new bits")
+	}
+
+	// Unchanged leaves are shared
+	tbs2 := bs2.(trieBitSet)
+	if tbs.root[0] == tbs2.root[0] {
+		t.Error("The modified path should have been copied")
+	}
+	if tbs.root[1] != tbs2.root[1] {
+		t.Error("Unmodified subtries should be shared")
+	}
+	if tbs2.r != tbs.r || tbs2.n != tbs.n+1 {
+		t.Errorf("Setting a bit adjacent to a run should not add a run: %+v", tbs2)
+	}
+
+	// Test immutability on Clear
+	bs3 := bs2.Clear(0)
+	if !bs2.Test(0) {
+		t.Error("Original trieBitSet should not be modified after Clear")
+	}
+	if bs3.Test(0) || !bs3.Test(1) {
+		t.Error("New trieBitSet should have correct bits after removal")
+	}
+}
+
+func TestTrieBitSetHeight(t *testing.T) {
+	bs := everyThird(16_000 * 64)
+	bs = bs.Set(15_999*64 + 63)
+	if h := bs.(trieBitSet).height; h != 2 {
+		t.Fatalf("Expected height 2, got %d", h)
+	}
+
+	grown := bs.Set(16_500 * 64)
+	if h := grown.(trieBitSet).height; h != 3 {
+		t.Fatalf("Expected height 3 after growing, got %d", h)
+	}
+	if !grown.Test(16_500*64) || !grown.Test(0) || !grown.Test(15_999*64+63) {
+		t.Error("Grown trieBitSet has incorrect bits")
+	}
+
+	shrunk := grown.Clear(16_500 * 64)
+	if h := shrunk.(trieBitSet).height; h != 2 {
+		t.Fatalf("Expected height 2 after shrinking, got %d", h)
+	}
+	if w := shrunk.(trieBitSet).w; w != 16_000 {
+		t.Fatalf("Expected 16000 words after shrinking, got %d", w)
+	}
+}
+
+func TestTrieBitSetDowngrade(t *testing.T) {
+	bs := everyThird(1024 * 64)
+	if _, ok := bs.(trieBitSet); !ok {
+		t.Fatalf("Expected trieBitSet, but got %T", bs)
+	}
+
+	// Clearing the highest bit drops below trieMinWords
+	var small Set = bs
+	for i := uint32(1023 * 64); i < 1024*64; i++ {
+		small = small.Clear(i)
+	}
+	if _, ok := small.(largeBitSet); !ok {
+		t.Fatalf("Expected largeBitSet after clearing the highest word, but got %T", small)
+	}
+	for i := uint32(0); i < 1023*64; i++ {
+		if small.Test(i) != (i%3 == 0) {
+			t.Fatalf("Downgraded set has incorrect bit %d", i)
+		}
+	}
+}
+
+func TestTrieBitSetRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	const n = 200_000
+
+	want := make([]bool, n)
+	var bs Set = everyThird(n)
+	for i := 0; i < n; i += 3 {
+		want[i] = true
+	}
+
+	for range 20_000 {
+		i := uint32(r.IntN(n))
+		if r.IntN(2) == 0 {
+			bs = bs.Clear(i)
+			want[i] = false
+		} else {
+			bs = bs.Set(i)
+			want[i] = true
+		}
+	}
+
+	tbs, ok := bs.(trieBitSet)
+	if !ok {
+		t.Fatalf("Expected trieBitSet, but got %T", bs)
+	}
+	count := 0
+	for i, w := range want {
+		if w {
+			count++
+		}
+		if bs.Test(uint32(i)) != w {
+			t.Fatalf("Bit %d: want %v, got %v", i, w, !w)
+		}
+	}
+	words := tbs.words()
+	if tbs.n != count || tbs.n != popCount(words) || tbs.r != runCount(words) {
+		t.Errorf("trieBitSet header is out of sync: %+v, want n=%d r=%d", tbs, count, runCount(words))
+	}
+}
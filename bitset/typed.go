@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "fmt"
+
+// bitset.Index is the constraint for the element types of a Typed set, and the values accepted by BitIndex.
+// Values of any of these types are valid bit indices if they are in [0, 2^32), on every platform.
+type Index interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint | ~int | ~int64
+}
+
+// bitset.Typed is an immutable set of values of type T, usually an enum type, stored as a bit set.
+//
+// Its methods take T instead of uint32, so values don't need to be converted at every call site,
+// and values of different enum types can't be mixed up. The zero value is an empty set.
+type Typed[T Index] struct {
+	s Set
+}
+
+// NewTyped creates and returns a new bitset.Typed with the given values.
+func NewTyped[T Index](values ...T) Typed[T] {
+	return Typed[T]{}.With(values...)
+}
+
+// TypedOf returns a bitset.Typed with the bits of s.
+func TypedOf[T Index](s Set) Typed[T] {
+	return Typed[T]{s}
+}
+
+// Bits returns the bits of t as a bitset.Set.
+func (t Typed[T]) Bits() Set {
+	if t.s == nil {
+		return New()
+	}
+	return t.s
+}
+
+// Test reports whether the given value is in t.
+func (t Typed[T]) Test(value T) bool {
+	i, ok := bitIndex(value)
+	return ok && t.Bits().Test(i)
+}
+
+// Set returns a new bitset.Typed with the given value added.
+// It panics if the value is negative or does not fit in a uint32.
+// The original bitset.Typed is not modified.
+func (t Typed[T]) Set(value T) Typed[T] {
+	return Typed[T]{t.Bits().Set(mustBitIndex(value))}
+}
+
+// With returns a new bitset.Typed with all the given values added.
+// It panics if any value is negative or does not fit in a uint32.
+// The original bitset.Typed is not modified.
+func (t Typed[T]) With(values ...T) Typed[T] {
+	s := t.Bits()
+	for _, v := range values {
+		s = s.Set(mustBitIndex(v))
+	}
+	return Typed[T]{s}
+}
+
+// Clear returns a new bitset.Typed with the given value removed.
+// The original bitset.Typed is not modified.
+func (t Typed[T]) Clear(value T) Typed[T] {
+	i, ok := bitIndex(value)
+	if !ok {
+		return t
+	}
+	return Typed[T]{t.Bits().Clear(i)}
+}
+
+// Union returns a new bitset.Typed with the values that are in either t or other.
+// Neither set is modified.
+func (t Typed[T]) Union(other Typed[T]) Typed[T] {
+	return Typed[T]{t.Bits().Union(other.Bits())}
+}
+
+// Intersect returns a new bitset.Typed with the values that are in both t and other.
+// Neither set is modified.
+func (t Typed[T]) Intersect(other Typed[T]) Typed[T] {
+	return Typed[T]{t.Bits().Intersect(other.Bits())}
+}
+
+// Difference returns a new bitset.Typed with the values that are in t but not in other.
+// Neither set is modified.
+func (t Typed[T]) Difference(other Typed[T]) Typed[T] {
+	return Typed[T]{t.Bits().Difference(other.Bits())}
+}
+
+// SymmetricDifference returns a new bitset.Typed with the values that are in exactly one of t and other.
+// Neither set is modified.
+func (t Typed[T]) SymmetricDifference(other Typed[T]) Typed[T] {
+	return Typed[T]{t.Bits().SymmetricDifference(other.Bits())}
+}
+
+// BitIndex returns value as a bit index, or ErrOutOfRange if it is negative or does not fit in a uint32,
+// e.g. to validate an id from a 64-bit id space before using it with a Set.
+func BitIndex[T Index](value T) (uint32, error) {
+	i, ok := bitIndex(value)
+	if !ok {
+		return 0, fmt.Errorf("%w: %d is not in [0, 2^32)", ErrOutOfRange, value)
+	}
+	return i, nil
+}
+
+// bitIndex returns value as a bit index, or false if it is negative or does not fit in a uint32.
+func bitIndex[T Index](value T) (uint32, bool) {
+	if value < 0 || uint64(value) > 1<<32-1 {
+		return 0, false
+	}
+	return uint32(value), true
+}
+
+func mustBitIndex[T Index](value T) uint32 {
+	i, ok := bitIndex(value)
+	if !ok {
+		panic(fmt.Sprintf("bitset: value %d is out of the bit index range", value))
+	}
+	return i
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type testOpcode uint8
+
+const (
+	opLoad testOpcode = iota
+	opStore
+	opJump
+)
+
+type testComponent int
+
+type testID uint64
+
+func TestTyped(t *testing.T) {
+	var empty Typed[testOpcode]
+	if empty.Test(opLoad) || empty.Bits() != New() {
+		t.Error("Zero value of Typed should be an empty set")
+	}
+
+	ops := NewTyped(opLoad, opJump)
+	if !ops.Test(opLoad) || ops.Test(opStore) || !ops.Test(opJump) {
+		t.Error("Typed set has incorrect values")
+	}
+
+	// Test immutability
+	ops2 := ops.Set(opStore).Clear(opLoad)
+	if !ops.Test(opLoad) || ops.Test(opStore) {
+		t.Error("Original Typed set should not be modified")
+	}
+	if ops2.Test(opLoad) || !ops2.Test(opStore) {
+		t.Error("New Typed set has incorrect values")
+	}
+
+	if u := ops.Union(ops2); !u.Test(opLoad) || !u.Test(opStore) || !u.Test(opJump) {
+		t.Error("Union has incorrect values")
+	}
+	if i := ops.Intersect(ops2); i.Test(opLoad) || !i.Test(opJump) {
+		t.Error("Intersect has incorrect values")
+	}
+	if d := ops.Difference(ops2); !d.Test(opLoad) || d.Test(opJump) {
+		t.Error("Difference has incorrect values")
+	}
+	if x := ops.SymmetricDifference(ops2); !x.Test(opLoad) || !x.Test(opStore) || x.Test(opJump) {
+		t.Error("SymmetricDifference has incorrect values")
+	}
+	if TypedOf[testOpcode](ops.Bits()) != ops {
+		t.Error("TypedOf should wrap the given bits")
+	}
+}
+
+func TestTypedIntRange(t *testing.T) {
+	c := NewTyped[testComponent](0, 1000)
+	if c.Test(-1) || c.Test(math.MaxInt) || !c.Test(1000) {
+		t.Error("Typed set has incorrect values")
+	}
+	if c2 := c.Clear(-1); !c2.Test(0) || !c2.Test(1000) {
+		t.Error("Clearing a value outside the bit index range should be a no-op")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Setting a negative value should panic")
+		}
+	}()
+	c.Set(-1)
+}
+
+func TestTypedWideIndices(t *testing.T) {
+	ids := NewTyped[testID](0, math.MaxUint32)
+	if !ids.Test(math.MaxUint32) || ids.Test(math.MaxUint32+1) || ids.Test(math.MaxUint64) {
+		t.Error("Typed set of uint64 values has incorrect values")
+	}
+	if ids.Clear(math.MaxUint32+1).Bits().Key() != ids.Bits().Key() {
+		t.Error("Clearing a value outside the bit index range should be a no-op")
+	}
+
+	tests := []struct {
+		i       int64
+		want    uint32
+		wantErr bool
+	}{
+		{0, 0, false},
+		{math.MaxUint32, math.MaxUint32, false},
+		{math.MaxUint32 + 1, 0, true},
+		{-1, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := BitIndex(tt.i)
+		if got != tt.want || (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrOutOfRange)) {
+			t.Errorf("BitIndex(%d) = %d, %v", tt.i, got, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// bitset.Value is an immutable set with value semantics, meant to be stored in large numbers, e.g. in a slice
+// with one set per entity.
+//
+// A Set is an interface, so storing one that only has bits below 64 takes 16 bytes plus an 8 byte allocation
+// the interface points to. A Value takes 16 bytes in total: it stores those bits inline,
+// and only points to a separate Set for sets with higher bits. The zero value is an empty set.
+type Value struct {
+	w    uint64         // the bits, if over is nil
+	over *valueOverflow // the bits, if any of them are 64 or higher
+}
+
+type valueOverflow struct {
+	s Set // never a bitSet64
+}
+
+// ValueOf returns a bitset.Value with the bits of s.
+func ValueOf(s Set) Value {
+	s = materialized(s)
+	if b, ok := s.(bitSet64); ok {
+		return Value{w: uint64(b)}
+	}
+	return Value{over: &valueOverflow{s}}
+}
+
+// Bits returns the bits of v as a bitset.Set.
+func (v Value) Bits() Set {
+	if v.over == nil {
+		return bitSet64(v.w)
+	}
+	return v.over.s
+}
+
+// Test reports whether the bit for the given bit index is set.
+func (v Value) Test(bitIndex uint32) bool {
+	if v.over == nil {
+		return bitIndex < 64 && v.w&(1<<bitIndex) != 0
+	}
+	return v.over.s.Test(bitIndex)
+}
+
+// Set returns a new bitset.Value with the bit for the given bit index set.
+// The original bitset.Value is not modified.
+func (v Value) Set(bitIndex uint32) Value {
+	if v.over == nil && bitIndex < 64 {
+		return Value{w: v.w | 1<<bitIndex}
+	}
+	if v.Test(bitIndex) {
+		return v
+	}
+	return ValueOf(v.Bits().Set(bitIndex))
+}
+
+// Clear returns a new bitset.Value with the bit for the given bit index cleared.
+// The original bitset.Value is not modified.
+func (v Value) Clear(bitIndex uint32) Value {
+	if v.over == nil {
+		if bitIndex < 64 {
+			v.w &^= 1 << bitIndex
+		}
+		return v
+	}
+	if !v.Test(bitIndex) {
+		return v
+	}
+	return ValueOf(v.over.s.Clear(bitIndex))
+}
+
+// Union returns a new bitset.Value with the bits that are set in either v or other.
+// Neither value is modified.
+func (v Value) Union(other Value) Value {
+	if v.over == nil && other.over == nil {
+		return Value{w: v.w | other.w}
+	}
+	return ValueOf(v.Bits().Union(other.Bits()))
+}
+
+// Intersect returns a new bitset.Value with the bits that are set in both v and other.
+// Neither value is modified.
+func (v Value) Intersect(other Value) Value {
+	if v.over == nil && other.over == nil {
+		return Value{w: v.w & other.w}
+	}
+	return ValueOf(v.Bits().Intersect(other.Bits()))
+}
+
+// Difference returns a new bitset.Value with the bits that are set in v but not in other.
+// Neither value is modified.
+func (v Value) Difference(other Value) Value {
+	if v.over == nil && other.over == nil {
+		return Value{w: v.w &^ other.w}
+	}
+	return ValueOf(v.Bits().Difference(other.Bits()))
+}
+
+// SymmetricDifference returns a new bitset.Value with the bits that are set in exactly one of v and other.
+// Neither value is modified.
+func (v Value) SymmetricDifference(other Value) Value {
+	if v.over == nil && other.over == nil {
+		return Value{w: v.w ^ other.w}
+	}
+	return ValueOf(v.Bits().SymmetricDifference(other.Bits()))
+}
+
+// Count returns the number of set bits.
+func (v Value) Count() int {
+	if v.over == nil {
+		return bits.OnesCount64(v.w)
+	}
+	return v.over.s.Count()
+}
+
+// Indices returns an iterator over the bit indices of the set bits, in ascending order.
+func (v Value) Indices() iter.Seq[uint32] {
+	return v.Bits().Indices()
+}
+
+// Key returns a comparable value representing the bits of v, the same as Bits().Key().
+func (v Value) Key() Key {
+	return v.Bits().Key()
+}
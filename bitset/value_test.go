@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func TestValue(t *testing.T) {
+	var v Value
+	if v.Count() != 0 || v.Bits().Kind() != KindSmall {
+		t.Fatal("The zero Value should be empty")
+	}
+	if want := 8 + unsafe.Sizeof(uintptr(0)); unsafe.Sizeof(v) != want {
+		t.Errorf("Value should take %d bytes, got %d", want, unsafe.Sizeof(v))
+	}
+
+	v2 := v.Set(3).Set(63)
+	if v.Test(3) || !v2.Test(3) || !v2.Test(63) || v2.over != nil {
+		t.Errorf("Set should store low bits inline without modifying the original")
+	}
+
+	v3 := v2.Set(1000)
+	if !v3.Test(1000) || v2.Test(1000) || v3.Count() != 3 {
+		t.Errorf("Set of a high bit = %v", slices.Collect(v3.Indices()))
+	}
+	if v4 := v3.Clear(1000); v4.over != nil || v4 != v2 {
+		t.Errorf("Clearing the only high bit should store the bits inline again")
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { v2.Set(10).Clear(3).Union(v2).Count() }); allocs != 0 {
+		t.Errorf("Operations on low bits allocated %v times", allocs)
+	}
+}
+
+func TestValueOperations(t *testing.T) {
+	ops := []struct {
+		name string
+		v    func(a, b Value) Value
+		s    func(a, b Set) Set
+	}{
+		{"Union", Value.Union, Set.Union},
+		{"Intersect", Value.Intersect, Set.Intersect},
+		{"Difference", Value.Difference, Set.Difference},
+		{"SymmetricDifference", Value.SymmetricDifference, Set.SymmetricDifference},
+	}
+
+	sets := testSets()
+	for _, op := range ops {
+		for _, a := range sets {
+			for _, b := range sets {
+				got := op.v(ValueOf(a.s), ValueOf(b.s))
+				want := op.s(a.s, b.s)
+				if got.Key() != want.Key() || got.Count() != want.Count() {
+					t.Errorf("%s(%s, %s) differs from the Set result", op.name, a.name, b.name)
+				}
+				checkCanonical(t, got.Bits())
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"iter"
+	"unsafe"
+)
+
+// UnionView returns a read-only bitset.Set with the bits that are set in either a or b,
+// without computing the union up front.
+//
+// Test, Count, and Indices are answered from a and b directly, so this is cheaper than a.Union(b) when the result
+// is only consulted a handful of times. Every other operation computes the union first, every time it is called.
+// Neither set is modified.
+func UnionView(a, b Set) Set {
+	return viewSet{a: a, b: b}
+}
+
+// IntersectView returns a read-only bitset.Set with the bits that are set in both a and b,
+// without computing the intersection up front. See UnionView.
+// Neither set is modified.
+func IntersectView(a, b Set) Set {
+	return viewSet{a: a, b: b, intersect: true}
+}
+
+// View (a lazy union or intersection of two sets)
+type viewSet struct {
+	a, b      Set
+	intersect bool
+}
+
+// materialize returns the bits of v as a canonical set.
+func (v viewSet) materialize() Set {
+	if v.intersect {
+		return combine(v.a, v.b, and)
+	}
+	return combine(v.a, v.b, or)
+}
+
+// materialized returns s, or its bits as a canonical set if it is a view.
+func materialized(s Set) Set {
+	if v, ok := s.(viewSet); ok {
+		return v.materialize()
+	}
+	return s
+}
+
+func (v viewSet) Test(bitIndex uint32) bool {
+	if v.intersect {
+		return v.a.Test(bitIndex) && v.b.Test(bitIndex)
+	}
+	return v.a.Test(bitIndex) || v.b.Test(bitIndex)
+}
+
+func (v viewSet) Set(bitIndex uint32) Set {
+	return v.materialize().Set(bitIndex)
+}
+
+func (v viewSet) Clear(bitIndex uint32) Set {
+	return v.materialize().Clear(bitIndex)
+}
+
+func (v viewSet) Union(other Set) Set {
+	return combine(v, other, or)
+}
+
+func (v viewSet) Intersect(other Set) Set {
+	return combine(v, other, and)
+}
+
+func (v viewSet) Difference(other Set) Set {
+	return combine(v, other, andNot)
+}
+
+func (v viewSet) SymmetricDifference(other Set) Set {
+	return combine(v, other, xor)
+}
+
+func (v viewSet) Count() int {
+	if v.intersect {
+		return andCount(v.a, v.b)
+	}
+	return v.a.Count() + v.b.Count() - andCount(v.a, v.b)
+}
+
+func (v viewSet) Indices() iter.Seq[uint32] {
+	if v.intersect {
+		return func(yield func(uint32) bool) {
+			for i := range v.a.Indices() {
+				if v.b.Test(i) && !yield(i) {
+					return
+				}
+			}
+		}
+	}
+
+	return func(yield func(uint32) bool) {
+		next, stop := iter.Pull(v.b.Indices())
+		defer stop()
+
+		j, ok := next()
+		for i := range v.a.Indices() {
+			for ; ok && j < i; j, ok = next() {
+				if !yield(j) {
+					return
+				}
+			}
+			if ok && j == i {
+				j, ok = next()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+		for ; ok; j, ok = next() {
+			if !yield(j) {
+				return
+			}
+		}
+	}
+}
+
+// SizeInBytes counts the memory of both operands.
+func (v viewSet) SizeInBytes() int {
+	return int(unsafe.Sizeof(v)) + v.a.SizeInBytes() + v.b.SizeInBytes()
+}
+
+func (v viewSet) Kind() Kind {
+	return KindView
+}
+
+// Key returns the key of the equivalent canonical set.
+func (v viewSet) Key() Key {
+	return v.materialize().Key()
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestViews(t *testing.T) {
+	views := []struct {
+		name string
+		view func(a, b Set) Set
+		op   func(a, b Set) Set
+	}{
+		{"UnionView", UnionView, Set.Union},
+		{"IntersectView", IntersectView, Set.Intersect},
+	}
+
+	sets := testSets()
+	for _, v := range views {
+		for _, a := range sets {
+			for _, b := range sets {
+				got, want := v.view(a.s, b.s), v.op(a.s, b.s)
+				if got.Kind() != KindView || got.Key() != want.Key() {
+					t.Fatalf("%s(%s, %s) has kind %v and a different key", v.name, a.name, b.name, got.Kind())
+				}
+				if got.Count() != want.Count() {
+					t.Errorf("%s(%s, %s).Count() = %d, want %d", v.name, a.name, b.name, got.Count(), want.Count())
+				}
+				if !slices.Equal(slices.Collect(got.Indices()), slices.Collect(want.Indices())) {
+					t.Errorf("%s(%s, %s).Indices() differs from %T", v.name, a.name, b.name, want)
+				}
+				for _, i := range []uint32{0, 1, 63, 64, 150, 299, 1000, 70_001, 3_000_050} {
+					if got.Test(i) != want.Test(i) {
+						t.Errorf("%s(%s, %s).Test(%d) = %v", v.name, a.name, b.name, i, got.Test(i))
+					}
+				}
+
+				// Views can be used like any other set, on either side of an operation
+				if got.Union(b.s).Key() != want.Union(b.s).Key() || b.s.SymmetricDifference(got).Key() != b.s.SymmetricDifference(want).Key() {
+					t.Errorf("%s(%s, %s) gives a different result when combined", v.name, a.name, b.name)
+				}
+				checkCanonical(t, b.s.Difference(got))
+			}
+		}
+	}
+}
+
+func TestViewSetAndClear(t *testing.T) {
+	a, b := FromIndices(1, 2, 3), FromIndices(3, 4)
+	v := UnionView(a, b)
+
+	s := v.Set(100).Clear(1)
+	if want := FromIndices(2, 3, 4, 100); s.Key() != want.Key() || s.Kind() == KindView {
+		t.Errorf("Set and Clear on a view = %v", slices.Collect(s.Indices()))
+	}
+	if a.Test(100) || !a.Test(1) || !v.Test(1) {
+		t.Error("Set and Clear on a view should not modify it or its operands")
+	}
+
+	it := NewIterator(IntersectView(a, b))
+	if !it.HasNext() || it.Next() != 3 || it.HasNext() {
+		t.Error("Iterator over a view should yield its bits")
+	}
+}
+
+func TestEmptyView(t *testing.T) {
+	v := IntersectView(New().Set(1), New().Set(2))
+	if !isEmpty(v) {
+		t.Error("An empty view should be empty")
+	}
+	if isEmpty(UnionView(New(), New().Set(2))) {
+		t.Error("A nonempty view should not be empty")
+	}
+	if p, err := PortSetOf(v); err != nil || p != (PortSet{}) {
+		t.Errorf("PortSetOf an empty view = %v, %v, expected the zero PortSet", p, err)
+	}
+	if c := (ChangeSet{Added: v, Removed: v}); !c.IsEmpty() {
+		t.Error("A ChangeSet of empty views should be empty")
+	}
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// bitset.Wide is an immutable set of 64-bit indices, e.g. snowflake-style IDs, that are too far apart for one Set.
+//
+// It partitions the index space into segments of 1<<32 indices by their high 32 bits, and only stores the segments
+// that have bits set, each as a bitset.Set of the low 32 bits. So a few IDs with distant timestamps take a few small
+// sets rather than one huge word slice. The zero value is an empty set.
+type Wide struct {
+	segs []wideSegment // sorted by hi, without empty sets - immutable, always copied on modification
+}
+
+// wideSegment is the bits of a Wide set whose indices have the high 32 bits hi.
+type wideSegment struct {
+	hi uint32
+	s  Set
+}
+
+// NewWide creates and returns a new bitset.Wide with the bits for the given indices set.
+// The indices can be in any order, and may contain duplicates.
+func NewWide(indices ...uint64) Wide {
+	sorted := slices.Clone(indices)
+	slices.Sort(sorted)
+
+	var w Wide
+	for len(sorted) > 0 {
+		hi := uint32(sorted[0] >> 32)
+		n := len(sorted)
+		if hi < 1<<32-1 {
+			n, _ = slices.BinarySearch(sorted, uint64(hi+1)<<32)
+		}
+		lows := make([]uint32, n)
+		for i, idx := range sorted[:n] {
+			lows[i] = uint32(idx)
+		}
+		w.segs = append(w.segs, wideSegment{hi, FromIndices(lows...)})
+		sorted = sorted[n:]
+	}
+	return w
+}
+
+// Segment returns the bits of w whose indices have the given high 32 bits, as a bitset.Set of their low 32 bits.
+func (w Wide) Segment(hi uint32) Set {
+	if i, ok := w.find(hi); ok {
+		return w.segs[i].s
+	}
+	return New()
+}
+
+// Segments returns an iterator over the high 32 bits of the segments of w that have bits set, and the bits of each
+// as a bitset.Set of their low 32 bits, in ascending order.
+func (w Wide) Segments() iter.Seq2[uint32, Set] {
+	return func(yield func(uint32, Set) bool) {
+		for _, seg := range w.segs {
+			if !yield(seg.hi, seg.s) {
+				return
+			}
+		}
+	}
+}
+
+// Test reports whether the bit for the given index is set.
+func (w Wide) Test(index uint64) bool {
+	i, ok := w.find(uint32(index >> 32))
+	return ok && w.segs[i].s.Test(uint32(index))
+}
+
+// Set returns a new bitset.Wide with the bit for the given index set.
+// The original bitset.Wide is not modified.
+func (w Wide) Set(index uint64) Wide {
+	hi := uint32(index >> 32)
+	i, ok := w.find(hi)
+	if !ok {
+		return Wide{slices.Insert(slices.Clone(w.segs), i, wideSegment{hi, New().Set(uint32(index))})}
+	}
+	if w.segs[i].s.Test(uint32(index)) {
+		return w
+	}
+	segs := slices.Clone(w.segs)
+	segs[i].s = segs[i].s.Set(uint32(index))
+	return Wide{segs}
+}
+
+// Clear returns a new bitset.Wide with the bit for the given index cleared.
+// The original bitset.Wide is not modified.
+func (w Wide) Clear(index uint64) Wide {
+	i, ok := w.find(uint32(index >> 32))
+	if !ok || !w.segs[i].s.Test(uint32(index)) {
+		return w
+	}
+	s := w.segs[i].s.Clear(uint32(index))
+	if isEmpty(s) {
+		return Wide{slices.Delete(slices.Clone(w.segs), i, i+1)}
+	}
+	segs := slices.Clone(w.segs)
+	segs[i].s = s
+	return Wide{segs}
+}
+
+// Union returns a new bitset.Wide with the bits that are set in either w or other.
+// Neither set is modified.
+func (w Wide) Union(other Wide) Wide {
+	return w.combine(other, Set.Union)
+}
+
+// Intersect returns a new bitset.Wide with the bits that are set in both w and other.
+// Neither set is modified.
+func (w Wide) Intersect(other Wide) Wide {
+	return w.combine(other, Set.Intersect)
+}
+
+// Difference returns a new bitset.Wide with the bits that are set in w but not in other.
+// Neither set is modified.
+func (w Wide) Difference(other Wide) Wide {
+	return w.combine(other, Set.Difference)
+}
+
+// SymmetricDifference returns a new bitset.Wide with the bits that are set in exactly one of w and other.
+// Neither set is modified.
+func (w Wide) SymmetricDifference(other Wide) Wide {
+	return w.combine(other, Set.SymmetricDifference)
+}
+
+// Count returns the number of set bits, clamped to math.MaxInt.
+func (w Wide) Count() int {
+	n := uint64(0)
+	for _, seg := range w.segs {
+		n += uint64(seg.s.Count())
+	}
+	return clampCount(n)
+}
+
+// Indices returns an iterator over the indices of all the set bits, in ascending order.
+func (w Wide) Indices() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for _, seg := range w.segs {
+			for lo := range seg.s.Indices() {
+				if !yield(uint64(seg.hi)<<32 | uint64(lo)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// find returns the position of the segment with the given high bits in w, or where it would be inserted.
+func (w Wide) find(hi uint32) (int, bool) {
+	return slices.BinarySearchFunc(w.segs, hi, func(seg wideSegment, hi uint32) int {
+		return cmp.Compare(seg.hi, hi)
+	})
+}
+
+// combine returns a new bitset.Wide with the segments of w and other combined by op, which must leave a bit unset
+// where neither set has it.
+func (w Wide) combine(other Wide, op func(a, b Set) Set) Wide {
+	var segs []wideSegment
+	add := func(hi uint32, s Set) {
+		if !isEmpty(s) {
+			segs = append(segs, wideSegment{hi, s})
+		}
+	}
+
+	a, b := w.segs, other.segs
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0].hi < b[0].hi:
+			add(a[0].hi, op(a[0].s, New()))
+			a = a[1:]
+		case len(a) == 0 || b[0].hi < a[0].hi:
+			add(b[0].hi, op(New(), b[0].s))
+			b = b[1:]
+		default:
+			add(a[0].hi, op(a[0].s, b[0].s))
+			a, b = a[1:], b[1:]
+		}
+	}
+	return Wide{segs}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWide(t *testing.T) {
+	// Snowflake-style IDs, with a timestamp in the high bits
+	ids := []uint64{1 << 62, 1<<62 + 5, 7, 1<<40 | 3, math.MaxUint64, 7}
+	w := NewWide(ids...)
+	want := []uint64{7, 1<<40 | 3, 1 << 62, 1<<62 + 5, math.MaxUint64}
+	if got := slices.Collect(w.Indices()); !slices.Equal(got, want) {
+		t.Fatalf("Indices = %v, expected %v", got, want)
+	}
+	if w.Count() != 5 || !w.Test(1<<62+5) || w.Test(1<<62+6) || w.Test(8) {
+		t.Error("Test and Count don't match the indices")
+	}
+	var segs []uint32
+	for hi := range w.Segments() {
+		segs = append(segs, hi)
+	}
+	if !slices.Equal(segs, []uint32{0, 1 << 8, 1 << 30, math.MaxUint32}) {
+		t.Errorf("Segments = %v", segs)
+	}
+	if got := w.Segment(1 << 30); !slices.Equal(slices.Collect(got.Indices()), []uint32{0, 5}) {
+		t.Errorf("Segment = %v", slices.Collect(got.Indices()))
+	}
+
+	w2 := w.Set(1 << 50).Set(8).Clear(math.MaxUint64).Clear(1<<40 | 3)
+	if !w2.Test(1<<50) || !w2.Test(8) || w2.Test(math.MaxUint64) || w.Test(1<<50) || !w.Test(math.MaxUint64) {
+		t.Error("Set and Clear should return a new set, without modifying the original")
+	}
+	if got := len(w2.segs); got != 3 {
+		t.Errorf("Clearing the last bit of a segment should remove it, have %d segments", got)
+	}
+	if w.Set(7).segs[0] != w.segs[0] || w.Clear(9).segs[0] != w.segs[0] {
+		t.Error("Setting a set bit or clearing a clear bit should not change the set")
+	}
+	if (Wide{}).Count() != 0 || (Wide{}).Test(0) || slices.Collect((Wide{}).Indices()) != nil {
+		t.Error("The zero Wide should be empty")
+	}
+}
+
+func TestWideOperations(t *testing.T) {
+	a := NewWide(1, 2, 1<<33, 1<<33+1, 1<<60)
+	b := NewWide(2, 3, 1<<33+1, 1<<61)
+	tests := []struct {
+		name string
+		got  Wide
+		want []uint64
+	}{
+		{"Union", a.Union(b), []uint64{1, 2, 3, 1 << 33, 1<<33 + 1, 1 << 60, 1 << 61}},
+		{"Intersect", a.Intersect(b), []uint64{2, 1<<33 + 1}},
+		{"Difference", a.Difference(b), []uint64{1, 1 << 33, 1 << 60}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []uint64{1, 3, 1 << 33, 1 << 60, 1 << 61}},
+	}
+	for _, tt := range tests {
+		if got := slices.Collect(tt.got.Indices()); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, expected %v", tt.name, got, tt.want)
+		}
+		for _, seg := range tt.got.segs {
+			if isEmpty(seg.s) {
+				t.Errorf("%s has an empty segment %d", tt.name, seg.hi)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "iter"
+
+// bitset.Window is an immutable selective acknowledgement window, as used by reliable transport protocols:
+// a base sequence number, and the sequence numbers in [base, base+size) that have been acknowledged so far,
+// possibly out of order.
+//
+// Sequence numbers are uint32 values that wrap around, so a window can straddle the end of the sequence space.
+// The zero value is an empty window of size 0 at sequence number 0; use NewWindow for a window that can be acknowledged.
+type Window struct {
+	base  uint32
+	size  uint32
+	acked Set // bit i is the sequence number base+i. nil is empty
+}
+
+// NewWindow creates and returns a new bitset.Window of size sequence numbers, starting at base,
+// with none of them acknowledged.
+func NewWindow(base, size uint32) Window {
+	return Window{base: base, size: size, acked: New()}
+}
+
+// Base returns the first sequence number of w.
+func (w Window) Base() uint32 {
+	return w.base
+}
+
+// Size returns the number of sequence numbers in w.
+func (w Window) Size() uint32 {
+	return w.size
+}
+
+// Acked reports whether the given sequence number is in w and acknowledged.
+func (w Window) Acked(seq uint32) bool {
+	offset := seq - w.base
+	return offset < w.size && Has(w.acked, offset)
+}
+
+// Ack returns a new bitset.Window with the given sequence number acknowledged.
+// Sequence numbers outside w, e.g. duplicates of ones it has already advanced past, are ignored.
+// The original bitset.Window is not modified.
+func (w Window) Ack(seq uint32) Window {
+	if offset := seq - w.base; offset < w.size {
+		w.acked = Add(w.acked, offset)
+	}
+	return w
+}
+
+// Advance returns a new bitset.Window slid forward past the acknowledged sequence numbers at its start,
+// so its base is the first sequence number that has not been acknowledged.
+// The original bitset.Window is not modified.
+func (w Window) Advance() Window {
+	if w.acked == nil {
+		return w
+	}
+	n, ok := nextClearBit(w.acked, 0) // skips the acknowledged prefix a word or a run at a time
+	if !ok || n > w.size {
+		n = w.size
+	}
+	if n == 0 {
+		return w
+	}
+
+	w.base += n
+	w.acked = Slice(w.acked, n, w.size)
+	return w
+}
+
+// Missing returns an iterator over the sequence numbers of w that have not been acknowledged, but are followed by
+// one that has, i.e. the gaps a selective acknowledgement reports, in ascending order from the base.
+func (w Window) Missing() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		if w.acked == nil {
+			return
+		}
+		next := uint32(0)
+		for _, r := range runsOf(w.acked) {
+			for offset := next; offset < r.start; offset++ {
+				if !yield(w.base + offset) {
+					return
+				}
+			}
+			next = r.last + 1
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	w := NewWindow(100, 64)
+	w2 := w.Ack(102).Ack(105).Ack(99).Ack(164)
+	if !w2.Acked(102) || !w2.Acked(105) || w2.Acked(103) || w.Acked(102) {
+		t.Error("Ack should return a new window with the sequence number acknowledged, without modifying the original")
+	}
+	if w2.Acked(99) || w2.Acked(164) {
+		t.Error("Ack should ignore sequence numbers outside the window")
+	}
+	if got := slices.Collect(w2.Missing()); !slices.Equal(got, []uint32{100, 101, 103, 104}) {
+		t.Errorf("Missing = %v", got)
+	}
+	if w2.Advance() != w2 {
+		t.Error("Advance should not move past an unacknowledged base")
+	}
+
+	w3 := w2.Ack(100).Ack(101).Advance()
+	if w3.Base() != 103 || w3.Size() != 64 || !w3.Acked(105) || w3.Acked(102) {
+		t.Errorf("Advance moved the base to %d", w3.Base())
+	}
+	if got := slices.Collect(w3.Missing()); !slices.Equal(got, []uint32{103, 104}) {
+		t.Errorf("Missing after Advance = %v", got)
+	}
+	if w3 = w3.Ack(166); !w3.Acked(166) {
+		t.Error("The window should slide forward with its base")
+	}
+	if got := w3.Ack(103).Ack(104).Advance(); got.Base() != 106 || !got.Acked(166) {
+		t.Errorf("Advance past several acknowledged numbers moved the base to %d", got.Base())
+	}
+}
+
+func TestWindowWraparound(t *testing.T) {
+	w := NewWindow(math.MaxUint32-1, 8).Ack(math.MaxUint32).Ack(1)
+	if got := slices.Collect(w.Missing()); !slices.Equal(got, []uint32{math.MaxUint32 - 1, 0}) {
+		t.Errorf("Missing across the wraparound = %v", got)
+	}
+
+	w = w.Ack(math.MaxUint32 - 1).Ack(0).Advance()
+	if w.Base() != 2 || slices.Collect(w.Missing()) != nil {
+		t.Errorf("Advance across the wraparound moved the base to %d", w.Base())
+	}
+}
+
+func TestWindowZeroValue(t *testing.T) {
+	var w Window
+	w = w.Ack(0).Advance()
+	if w.Base() != 0 || w.Size() != 0 || w.Acked(0) || slices.Collect(w.Missing()) != nil {
+		t.Error("The zero Window should be an empty window of size 0")
+	}
+
+	w = Window{size: 8}.Ack(3).Ack(0)
+	if !w.Acked(3) || !w.Acked(0) {
+		t.Error("Ack should treat a nil acknowledged set as empty")
+	}
+	if w = w.Advance(); w.Base() != 1 || !w.Acked(3) {
+		t.Errorf("Advance moved the base to %d", w.Base())
+	}
+}
+
+func TestWindowAdvanceFull(t *testing.T) {
+	w := NewWindow(10, 200)
+	for seq := uint32(10); seq < 210; seq++ {
+		w = w.Ack(seq)
+	}
+	if w = w.Advance(); w.Base() != 210 || w.Size() != 200 || w.Acked(210) {
+		t.Errorf("Advance past a fully acknowledged window moved the base to %d", w.Base())
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "fmt"
+
+// numWords is the number of words needed to hold every bit index.
+const numWords = 1 << 26
+
+// UpdateWord returns a new bitset.Set with word wordIdx, i.e. the bits [wordIdx*64, wordIdx*64+64), replaced by
+// fn applied to it, where bit j of the word is the bit index wordIdx*64+j. It lets algorithms with custom per-word
+// logic update 64 bits at once instead of setting and clearing them one by one.
+// If fn returns the word unchanged, it returns s itself. It panics if wordIdx is not in [0, 1<<26).
+// The original bitset.Set is not modified.
+func UpdateWord(s Set, wordIdx int, fn func(w uint64) uint64) Set {
+	if wordIdx < 0 || wordIdx >= numWords {
+		panic(fmt.Sprintf("bitset: word index %d is out of range [0, %d)", wordIdx, numWords))
+	}
+
+	words, dense := denseWords(s)
+	var old uint64
+	if dense {
+		if wordIdx < len(words) {
+			old = words[wordIdx]
+		}
+	} else if ws := wordsOf(slice(s, uint64(wordIdx)*64, uint64(wordIdx)*64+64)); len(ws) > 0 {
+		old = ws[0]
+	}
+	w := fn(old)
+	if w == old {
+		return s
+	}
+
+	// Replace the word with a single copy if it is within the words of s
+	if dense && wordIdx < len(words) {
+		newBits := make([]uint64, len(words))
+		copy(newBits, words)
+		newBits[wordIdx] = w
+		if r, ok := s.(retainedBitSet); ok {
+			return r.retain(newBits)
+		}
+		return fromWords(newBits)
+	}
+	return s.SymmetricDifference(fromWordList([]indexedWord{{wordIdx, old ^ w}}))
+}
+
+// OrMask returns a new bitset.Set with the bits of mask set in word wordIdx, where bit j of mask is the bit index
+// wordIdx*64+j, e.g. for masks produced by a protocol decoder. It panics if wordIdx is not in [0, 1<<26).
+// The original bitset.Set is not modified.
+func OrMask(s Set, wordIdx int, mask uint64) Set {
+	return UpdateWord(s, wordIdx, func(w uint64) uint64 { return w | mask })
+}
+
+// AndMask returns a new bitset.Set with the bits of word wordIdx that are not in mask cleared, leaving the other
+// words unchanged. It panics if wordIdx is not in [0, 1<<26).
+// The original bitset.Set is not modified.
+func AndMask(s Set, wordIdx int, mask uint64) Set {
+	return UpdateWord(s, wordIdx, func(w uint64) uint64 { return w & mask })
+}
+
+// XorMask returns a new bitset.Set with the bits of mask flipped in word wordIdx. It panics if wordIdx is not
+// in [0, 1<<26).
+// The original bitset.Set is not modified.
+func XorMask(s Set, wordIdx int, mask uint64) Set {
+	return UpdateWord(s, wordIdx, func(w uint64) uint64 { return w ^ mask })
+}
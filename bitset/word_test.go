@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// wordOf returns bit j of word wordIdx of s as bit j of the result, by testing every bit.
+func wordOf(s Set, wordIdx int) uint64 {
+	var w uint64
+	for j := range 64 {
+		if s.Test(uint32(wordIdx*64 + j)) {
+			w |= 1 << j
+		}
+	}
+	return w
+}
+
+func TestUpdateWord(t *testing.T) {
+	fns := map[string]func(uint64) uint64{
+		"reverse":  func(w uint64) uint64 { return ^w },
+		"clear":    func(uint64) uint64 { return 0 },
+		"high":     func(w uint64) uint64 { return w | 1<<63 },
+		"rotate":   func(w uint64) uint64 { return w<<1 | w>>63 },
+		"identity": func(w uint64) uint64 { return w },
+	}
+	for _, ts := range testSets() {
+		for _, wordIdx := range []int{0, 1, 3, 4, 1000, 15_625, 46_875, numWords - 1} {
+			for name, fn := range fns {
+				got := UpdateWord(ts.s, wordIdx, fn)
+				if err := CheckInvariants(got); err != nil {
+					t.Fatalf("%s: UpdateWord(%d, %s): %v", ts.name, wordIdx, name, err)
+				}
+				if w := wordOf(got, wordIdx); w != fn(wordOf(ts.s, wordIdx)) {
+					t.Fatalf("%s: UpdateWord(%d, %s) word is %#x", ts.name, wordIdx, name, w)
+				}
+				if restored := UpdateWord(got, wordIdx, func(uint64) uint64 { return wordOf(ts.s, wordIdx) }); restored.Key() != ts.s.Key() {
+					t.Fatalf("%s: UpdateWord(%d, %s) changed bits outside the word", ts.name, wordIdx, name)
+				}
+			}
+		}
+	}
+}
+
+func TestUpdateWordRetained(t *testing.T) {
+	rs := WithShrinkPolicy(New().Set(5).Set(700), ShrinkNever)
+	got := UpdateWord(rs, 0, func(w uint64) uint64 { return w | 0b11 })
+	if got.Kind() != KindRetained || !got.Test(0) || !got.Test(1) || !got.Test(700) {
+		t.Errorf("UpdateWord on a retained set = %v", got.Kind())
+	}
+}
+
+func TestUpdateWordOutOfRange(t *testing.T) {
+	for _, wordIdx := range []int{-1, numWords} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("UpdateWord(%d) should panic", wordIdx)
+				}
+			}()
+			UpdateWord(New(), wordIdx, func(w uint64) uint64 { return w })
+		}()
+	}
+}
+
+func TestMasks(t *testing.T) {
+	const mask = 0xf0f0_0000_0000_0f0f
+	for _, ts := range testSets() {
+		for _, wordIdx := range []int{0, 2, 4, 1000, 46_875} {
+			old := wordOf(ts.s, wordIdx)
+			for name, got := range map[string]Set{
+				"OrMask":  OrMask(ts.s, wordIdx, mask),
+				"AndMask": AndMask(ts.s, wordIdx, mask),
+				"XorMask": XorMask(ts.s, wordIdx, mask),
+			} {
+				want := map[string]uint64{"OrMask": old | mask, "AndMask": old & mask, "XorMask": old ^ mask}[name]
+				if w := wordOf(got, wordIdx); w != want {
+					t.Errorf("%s: %s(%d) word is %#x, want %#x", ts.name, name, wordIdx, w, want)
+				}
+				if got.Count()-bits.OnesCount64(wordOf(got, wordIdx)) != ts.s.Count()-bits.OnesCount64(old) {
+					t.Errorf("%s: %s(%d) changed bits outside the word", ts.name, name, wordIdx)
+				}
+				checkCanonical(t, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+// bitset.WordAllocator allocates the words of the sets built with NewBuilderWith and FromWordsWith,
+// so high-throughput code can plug in a pool or an off-heap allocator. Arena is a WordAllocator.
+//
+// Only the sets returned by those functions are allocated with it.
+// Sets derived from them using Set, Clear, or the boolean operations are allocated normally.
+type WordAllocator interface {
+	// Alloc returns n zeroed words.
+	Alloc(n int) []uint64
+
+	// Free returns words that were returned by Alloc and are no longer used, e.g. to a pool.
+	// words starts at the same element, and has the same capacity, as the slice returned by Alloc,
+	// but may be shorter.
+	Free(words []uint64)
+}
+
+// NewBuilderWith creates and returns a new bitset.Builder that allocates its words with alloc,
+// with an initial bit capacity of at least minCapacity.
+// You can set bits beyond this capacity and the builder will expand automatically, freeing the words it outgrew.
+func NewBuilderWith(alloc WordAllocator, minCapacity int) Builder {
+	return allocBuilder{alloc: alloc, words: alloc.Alloc((minCapacity + 63) / 64)}
+}
+
+// FromWordsWith returns a bitset.Set with the bits in words, where bit i is bit i%64 of words[i/64].
+// words is copied into words allocated with alloc, and can be modified afterwards.
+func FromWordsWith(alloc WordAllocator, words []uint64) Set {
+	lastIdx := len(words) - 1
+	for lastIdx >= 0 && words[lastIdx] == 0 {
+		lastIdx--
+	}
+
+	newBits := alloc.Alloc(lastIdx + 1)
+	copy(newBits, words)
+	return adoptWords(alloc, newBits)
+}
+
+// Free returns the words of s to alloc, if s uses words that were allocated with it.
+// s must have been returned by a builder from NewBuilderWith or by FromWordsWith, and must not be used afterwards.
+// Sets that don't use the words, because a more compact representation suits their bits, were already freed.
+func Free(alloc WordAllocator, s Set) {
+	if b, ok := s.(largeBitSet); ok && len(b) > 0 {
+		alloc.Free(b)
+	}
+}
+
+// adoptWords returns the most suitable Set representation for words, which were allocated with alloc,
+// freeing them if the set doesn't use them.
+func adoptWords(alloc WordAllocator, words []uint64) Set {
+	s := fromWords(words)
+	if b, ok := s.(largeBitSet); (!ok || &b[0] != &words[0]) && cap(words) > 0 {
+		alloc.Free(words)
+	}
+	return s
+}
+
+type allocBuilder struct {
+	alloc WordAllocator
+	words []uint64
+}
+
+func (b allocBuilder) With(bitIndex uint32) Builder {
+	idx := int(bitIndex / 64)
+	if idx >= len(b.words) {
+		newBits := b.alloc.Alloc(max(idx+1, 2*len(b.words)))
+		copy(newBits, b.words)
+		if cap(b.words) > 0 {
+			b.alloc.Free(b.words)
+		}
+		b.words = newBits
+	}
+
+	b.words[idx] |= 1 << (bitIndex % 64)
+	return b
+}
+
+func (b allocBuilder) WithMany(bitIndices ...uint32) Builder {
+	var bld Builder = b
+	for _, i := range bitIndices {
+		bld = bld.With(i)
+	}
+	return bld
+}
+
+func (b allocBuilder) Build() Set {
+	return adoptWords(b.alloc, b.words)
+}
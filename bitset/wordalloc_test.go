@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bitset
+
+import "testing"
+
+// countingAllocator is a WordAllocator that tracks the slices it allocated and hasn't had freed.
+type countingAllocator struct {
+	live map[*uint64]int // the capacity of each live slice, by its first element
+}
+
+func (a *countingAllocator) Alloc(n int) []uint64 {
+	words := make([]uint64, n, n+1) // never empty, so every slice has a first element
+	a.live[&words[:1][0]] = cap(words)
+	return words
+}
+
+func (a *countingAllocator) Free(words []uint64) {
+	p := &words[:1][0]
+	if a.live[p] != cap(words) {
+		panic("freed a slice that wasn't allocated, or twice")
+	}
+	delete(a.live, p)
+}
+
+func TestNewBuilderWith(t *testing.T) {
+	a := &countingAllocator{live: map[*uint64]int{}}
+
+	// Growing the builder frees the words it outgrew, and a dense set keeps its words
+	b := NewBuilderWith(a, 64)
+	for i := uint32(0); i < 2000; i += 3 {
+		b = b.With(i)
+	}
+	s := b.Build()
+	if _, ok := s.(largeBitSet); !ok {
+		t.Fatalf("Expected largeBitSet, but got %T", s)
+	}
+	if len(a.live) != 1 {
+		t.Errorf("Expected only the words of the set to be live, got %d slices", len(a.live))
+	}
+	if s.Key() != FromStride(0, 3, 667).Key() {
+		t.Error("Set built with an allocator has the wrong bits")
+	}
+	Free(a, s)
+	if len(a.live) != 0 {
+		t.Errorf("Expected Free to free the words of the set, got %d live slices", len(a.live))
+	}
+
+	// Sets in other representations free their words right away
+	for _, bits := range [][]uint32{{5}, {5, 100}, {1, 100000}} {
+		s := NewBuilderWith(a, 0).WithMany(bits...).Build()
+		if len(a.live) != 0 {
+			t.Errorf("%v: expected a %v set to free its words, got %d live slices", bits, s.Kind(), len(a.live))
+		}
+		Free(a, s)
+	}
+}
+
+func TestFromWordsWith(t *testing.T) {
+	a := &countingAllocator{live: map[*uint64]int{}}
+	words := []uint64{1, 2, 3, 4, 0, 0}
+	s := FromWordsWith(a, words)
+	words[0] = 0
+	if !s.Test(0) || s.Count() != 5 {
+		t.Error("FromWordsWith should copy the words")
+	}
+	if l, ok := s.(largeBitSet); !ok || len(l) != 4 {
+		t.Errorf("Expected a largeBitSet of 4 words, got %v", Inspect(s))
+	}
+	Free(a, s)
+
+	FromWordsWith(a, []uint64{0, 0})
+	if len(a.live) != 0 {
+		t.Errorf("Expected every slice to be freed, got %d live slices", len(a.live))
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package bloom provides an immutable Bloom filter built on bitset.Set.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+var errInvalidData = errors.New("bloom: invalid filter data")
+
+// MaxK is the maximum number of hash functions of a filter. More than that doesn't lower the false positive rate
+// of any practical filter, and bounds the work of Add and Test on a filter read from untrusted data.
+const MaxK = 64
+
+// bloom.Filter is an immutable Bloom filter with m bits and k hash functions.
+//
+// Add returns a new filter, so a filter can be shared between goroutines and updated by publishing new versions,
+// the same as a bitset.Set. The hash functions are fixed, so filters can be serialized and shared between processes.
+// Filters must be created with New or NewWithEstimates.
+type Filter struct {
+	bits bitset.Set
+	m, k uint32
+}
+
+// New creates and returns a new empty bloom.Filter with m bits and k hash functions.
+// It panics if m or k is 0, or if k is greater than MaxK.
+func New(m, k uint32) Filter {
+	if m == 0 || k == 0 || k > MaxK {
+		panic(fmt.Sprintf("bloom: invalid filter size m=%d, k=%d", m, k))
+	}
+	return Filter{bits: bitset.New(), m: m, k: k}
+}
+
+// NewWithEstimates creates and returns a new empty bloom.Filter sized for n items with a false positive rate of p.
+// The number of hash functions is at most MaxK.
+func NewWithEstimates(n uint, p float64) Filter {
+	m := math.Ceil(-float64(max(n, 1)) * math.Log(p) / (math.Ln2 * math.Ln2))
+	m = min(max(m, 1), math.MaxUint32)
+	k := min(max(math.Round(m/float64(max(n, 1))*math.Ln2), 1), MaxK)
+	return New(uint32(m), uint32(k))
+}
+
+// M returns the number of bits in f.
+func (f Filter) M() uint32 {
+	return f.m
+}
+
+// K returns the number of hash functions of f.
+func (f Filter) K() uint32 {
+	return f.k
+}
+
+// Bits returns the bits of f as a bitset.Set.
+func (f Filter) Bits() bitset.Set {
+	return f.bits
+}
+
+// Add returns a new bloom.Filter with data added.
+// The original bloom.Filter is not modified.
+func (f Filter) Add(data []byte) Filter {
+	return f.AddAll(data)
+}
+
+// AddAll returns a new bloom.Filter with every item in items added.
+// The original bloom.Filter is not modified.
+func (f Filter) AddAll(items ...[]byte) Filter {
+	indices := make([]uint32, 0, len(items)*int(f.k))
+	for _, data := range items {
+		indices = f.appendLocations(indices, data)
+	}
+	f.bits = f.bits.Union(bitset.FromIndices(indices...))
+	return f
+}
+
+// Test reports whether data may have been added to f.
+// A false result means it was definitely not added, but a true result may be a false positive.
+func (f Filter) Test(data []byte) bool {
+	var buf [16]uint32
+	for _, i := range f.appendLocations(buf[:0], data) {
+		if !f.bits.Test(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new bloom.Filter that matches everything either f or other matches.
+// It panics if the filters have different sizes or numbers of hash functions.
+// Neither filter is modified.
+func (f Filter) Union(other Filter) Filter {
+	if f.m != other.m || f.k != other.k {
+		panic(fmt.Sprintf("bloom: mismatched filters m=%d, k=%d and m=%d, k=%d", f.m, f.k, other.m, other.k))
+	}
+	f.bits = f.bits.Union(other.bits)
+	return f
+}
+
+// appendLocations appends the k bit indices for data to dst, using double hashing of a 64-bit FNV-1a hash.
+func (f Filter) appendLocations(dst []uint32, data []byte) []uint32 {
+	h := fnv.New64a()
+	h.Write(data)
+	h1 := h.Sum64()
+	h2 := bits.RotateLeft64(h1, 32) | 1
+	for i := range uint64(f.k) {
+		dst = append(dst, uint32((h1+i*h2)%uint64(f.m)))
+	}
+	return dst
+}
+
+// MarshalBinary returns f as m and k as little-endian uint32s, followed by its bits as little-endian uint64 words.
+func (f Filter) MarshalBinary() ([]byte, error) {
+	words := bitset.AppendWords(nil, f.bits)
+	data := make([]byte, 0, 8+8*len(words))
+	data = binary.LittleEndian.AppendUint32(data, f.m)
+	data = binary.LittleEndian.AppendUint32(data, f.k)
+	for _, w := range words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets f to the filter in data, in the format written by MarshalBinary.
+// It returns an error if k is 0 or greater than MaxK.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 || len(data)%8 != 0 {
+		return errInvalidData
+	}
+
+	m, k := binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint32(data[4:])
+	words := make([]uint64, (len(data)-8)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[8+8*i:])
+	}
+	if m == 0 || k == 0 || k > MaxK || uint64(len(words)) > (uint64(m)+63)/64 {
+		return errInvalidData
+	}
+	if rem := m % 64; rem != 0 && uint64(len(words)) == (uint64(m)+63)/64 && words[len(words)-1]>>rem != 0 {
+		return errInvalidData
+	}
+
+	*f = Filter{bits: bitset.UnsafeFromWords(words), m: m, k: k}
+	return nil
+}
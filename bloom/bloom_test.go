@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(1024, 4)
+	f2 := f.Add([]byte("alice")).Add([]byte("bob"))
+
+	if !f2.Test([]byte("alice")) || !f2.Test([]byte("bob")) {
+		t.Error("Filter should contain added items")
+	}
+	if f.Test([]byte("alice")) || f.Bits().Count() != 0 {
+		t.Error("Add should not modify the original filter")
+	}
+	if f2.Bits().Count() > 8 {
+		t.Errorf("Expected at most 8 bits set, got %d", f2.Bits().Count())
+	}
+
+	u := New(1024, 4).Add([]byte("carol")).Union(f2)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if !u.Test([]byte(name)) {
+			t.Errorf("Union should contain %q", name)
+		}
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	const n = 10_000
+	f := NewWithEstimates(n, 0.01)
+	if f.K() != 7 || f.M() != 95851 {
+		t.Errorf("Unexpected size m=%d, k=%d", f.M(), f.K())
+	}
+
+	items := make([][]byte, n)
+	for i := range items {
+		items[i] = fmt.Appendf(nil, "item-%d", i)
+	}
+	f = f.AddAll(items...)
+	for _, item := range items {
+		if !f.Test(item) {
+			t.Fatalf("Filter should contain %q", item)
+		}
+	}
+
+	fp := 0
+	for i := range n {
+		if f.Test(fmt.Appendf(nil, "other-%d", i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.02 {
+		t.Errorf("False positive rate %.4f is too high", rate)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f := New(1000, 3).Add([]byte("x")).Add([]byte("y"))
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Filter
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.M() != 1000 || got.K() != 3 || got.Bits().Key() != f.Bits().Key() {
+		t.Error("Filter does not round trip")
+	}
+	if !got.Test([]byte("x")) || !got.Test([]byte("y")) {
+		t.Error("Unmarshaled filter should contain the added items")
+	}
+
+	invalid := [][]byte{
+		nil,
+		data[:7],
+		{0, 0, 0, 0, 1, 0, 0, 0}, // m == 0
+		{64, 0, 0, 0, 1, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 1, 0, 0, 0, 0, 0, 0, 0}, // too many words
+		{1, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0},                          // bit past m
+		{64, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0, 0, 0, 0, 0},             // k > MaxK
+		{64, 0, 0, 0, MaxK + 1, 0, 0, 0},                                          // k > MaxK
+	}
+	for _, data := range invalid {
+		if err := new(Filter).UnmarshalBinary(data); err == nil {
+			t.Errorf("Expected an error for % x", data)
+		}
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	for _, k := range []uint32{0, MaxK + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New should panic for k == %d", k)
+				}
+			}()
+			New(10, k)
+		}()
+	}
+
+	if k := NewWithEstimates(10, 1e-300).K(); k != MaxK {
+		t.Errorf("NewWithEstimates K = %d, expected MaxK", k)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/sibber5/go-immutable-bitset/cmd/bitset
+
+go 1.25.0
+
+require (
+	github.com/RoaringBitmap/roaring/v2 v2.14.4
+	github.com/sibber5/go-immutable-bitset v1.2.0
+	github.com/sibber5/go-immutable-bitset/roaringbitmap v1.2.0
+)
+
+require github.com/bits-and-blooms/bitset v1.24.2 // indirect
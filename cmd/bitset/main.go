@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Command bitset inspects, compares and converts bitmap files in the formats the bitset package reads and writes.
+//
+// Usage:
+//
+//	bitset info [-f format] file
+//	bitset ranges [-f format] file
+//	bitset diff [-f format] old new
+//	bitset convert [-from format] [-to format] in out
+//
+// info prints the number of bits and ranges in the file, its first and last bit, and the representation and size
+// of the set it decodes to. ranges prints its bits as a range list, e.g. "0-3,8". diff prints the bits added and
+// removed between two files, and exits with status 1 if there are any. convert reads a file in one format and
+// writes it in another.
+//
+// A file named "-" is the standard input or output. The formats are:
+//
+//	words    little-endian uint64 words, bit i is bit i%64 of word i/64 (the default, as read by FromMappedBytes)
+//	encoded  the versioned binary format written by bitset.Encode
+//	roaring  the portable Roaring bitmap serialization format
+//	redis    a Redis bitmap string, as described by EncodeRedis
+//	java     the bytes of java.util.BitSet.toByteArray
+//	ranges   a range list, as described by ParseRangeList
+//
+// It is a separate module, so the main package doesn't depend on roaring.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/RoaringBitmap/roaring/v2"
+	"github.com/sibber5/go-immutable-bitset/bitset"
+	"github.com/sibber5/go-immutable-bitset/roaringbitmap"
+)
+
+// format decodes and encodes a set in one file format.
+type format struct {
+	decode func(data []byte) (bitset.Set, error)
+	encode func(s bitset.Set) ([]byte, error)
+}
+
+var formats = map[string]format{
+	"words": {decodeWords, encodeWords},
+	"encoded": {
+		decode: bitset.Decode,
+		encode: func(s bitset.Set) ([]byte, error) { return bitset.Encode(s), nil },
+	},
+	"roaring": {
+		decode: func(data []byte) (bitset.Set, error) {
+			rb := roaring.New()
+			if _, err := rb.ReadFrom(bytes.NewReader(data)); err != nil {
+				return nil, err
+			}
+			return roaringbitmap.FromRoaring(rb), nil
+		},
+		encode: func(s bitset.Set) ([]byte, error) {
+			return roaringbitmap.ToRoaring(s).ToBytes()
+		},
+	},
+	"redis": {
+		decode: func(data []byte) (bitset.Set, error) { return bitset.DecodeRedis(data), nil },
+		encode: func(s bitset.Set) ([]byte, error) { return bitset.EncodeRedis(s), nil },
+	},
+	"java": {
+		decode: func(data []byte) (bitset.Set, error) { return bitset.FromJavaBytes(data), nil },
+		encode: func(s bitset.Set) ([]byte, error) { return bitset.ToJavaBytes(s), nil },
+	},
+	"ranges": {
+		decode: func(data []byte) (bitset.Set, error) {
+			return bitset.ParseRangeList(strings.TrimSpace(string(data)))
+		},
+		encode: func(s bitset.Set) ([]byte, error) { return []byte(bitset.FormatRangeList(s) + "\n"), nil },
+	},
+}
+
+// errDiffer is returned by diff when the files have different bits, to exit with status 1 like diff(1).
+var errDiffer = errors.New("files differ")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch {
+	case errors.Is(err, errDiffer):
+		os.Exit(1)
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "bitset:", err)
+		os.Exit(2)
+	}
+}
+
+const usage = `usage:
+	bitset info [-f format] file
+	bitset ranges [-f format] file
+	bitset diff [-f format] old new
+	bitset convert [-from format] [-to format] in out
+formats: words, encoded, roaring, redis, java, ranges
+`
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	cmd := args[0]
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	switch cmd {
+	case "info", "ranges", "diff":
+		name := fs.String("f", "words", "the `format` of the files")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		n := 1
+		if cmd == "diff" {
+			n = 2
+		}
+		if fs.NArg() != n {
+			fs.Usage()
+			return flag.ErrHelp
+		}
+
+		sets := make([]bitset.Set, n)
+		for i, path := range fs.Args() {
+			s, err := readSet(path, *name, stdin)
+			if err != nil {
+				return err
+			}
+			sets[i] = s
+		}
+		switch cmd {
+		case "info":
+			return info(stdout, sets[0])
+		case "ranges":
+			_, err := fmt.Fprintln(stdout, bitset.FormatRangeList(sets[0]))
+			return err
+		}
+		return diff(stdout, sets[0], sets[1])
+
+	case "convert":
+		from := fs.String("from", "words", "the `format` of the input")
+		to := fs.String("to", "words", "the `format` of the output")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			fs.Usage()
+			return flag.ErrHelp
+		}
+		out, ok := formats[*to]
+		if !ok {
+			return fmt.Errorf("unknown format %q", *to)
+		}
+		s, err := readSet(fs.Arg(0), *from, stdin)
+		if err != nil {
+			return err
+		}
+		data, err := out.encode(s)
+		if err != nil {
+			return err
+		}
+		if fs.Arg(1) == "-" {
+			_, err = stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(fs.Arg(1), data, 0o644)
+	}
+
+	fmt.Fprint(stderr, usage)
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+// readSet reads the file at path, or stdin if path is "-", and decodes it in the named format.
+func readSet(path, name string, stdin io.Reader) (bitset.Set, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := f.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func info(w io.Writer, s bitset.Set) error {
+	rs := bitset.RangeSetOf(s)
+	inspected := bitset.Inspect(s)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "count   %d\n", s.Count())
+	fmt.Fprintf(&b, "ranges  %d\n", rs.Len())
+	if rs.Len() > 0 {
+		ranges := slices.Collect(rs.Ranges())
+		fmt.Fprintf(&b, "first   %d\n", ranges[0].Start)
+		fmt.Fprintf(&b, "last    %d\n", ranges[len(ranges)-1].End-1)
+	}
+	fmt.Fprintf(&b, "kind    %s\n", inspected.Kind)
+	fmt.Fprintf(&b, "bytes   %d\n", inspected.SizeInBytes)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// diff prints the bits added and removed between old and new as range lists, prefixed with + and -.
+// It returns errDiffer if there are any.
+func diff(w io.Writer, old, new bitset.Set) error {
+	c := bitset.Diff(old, new)
+	if c.IsEmpty() {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "+ %s\n- %s\n", bitset.FormatRangeList(c.Added), bitset.FormatRangeList(c.Removed)); err != nil {
+		return err
+	}
+	return errDiffer
+}
+
+func decodeWords(data []byte) (bitset.Set, error) {
+	if len(data)%8 != 0 {
+		return nil, errors.New("length is not a multiple of 8")
+	}
+	words := make([]uint64, len(data)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return bitset.UnsafeFromWords(words), nil
+}
+
+func encodeWords(s bitset.Set) ([]byte, error) {
+	words := bitset.AppendWords(nil, s)
+	data := make([]byte, 0, len(words)*8)
+	for _, w := range words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+func TestConvertRoundTrip(t *testing.T) {
+	want := bitset.FromIndices(0, 3, 64, 65, 66, 1000, 70000)
+	dir := t.TempDir()
+
+	for name, f := range formats {
+		data, err := f.encode(want)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", name, err)
+		}
+		in := filepath.Join(dir, name)
+		if err := os.WriteFile(in, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		out := filepath.Join(dir, name+".words")
+		if err := run([]string{"convert", "-from", name, in, out}, nil, nil, nil); err != nil {
+			t.Fatalf("%s: convert: %v", name, err)
+		}
+		got, err := readSet(out, "words", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Key() != want.Key() {
+			t.Errorf("%s: got %s, want %s", name, bitset.FormatRangeList(got), bitset.FormatRangeList(want))
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"info", "-f", "ranges", "-"}, strings.NewReader("3-5,100\n"), &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"count   4", "ranges  2", "first   3", "last    100"} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("output %q does not contain %q", out.String(), line)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	old, new := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	os.WriteFile(old, []byte("1-5"), 0o644)
+	os.WriteFile(new, []byte("3-8"), 0o644)
+
+	var out bytes.Buffer
+	if err := run([]string{"diff", "-f", "ranges", old, new}, nil, &out, nil); !errors.Is(err, errDiffer) {
+		t.Errorf("run returned %v, want errDiffer", err)
+	}
+	if want := "+ 6-8\n- 1-2\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := run([]string{"diff", "-f", "ranges", old, old}, nil, &out, nil); err != nil || out.Len() != 0 {
+		t.Errorf("identical files: got %q, %v", out.String(), err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, err := decodeWords(make([]byte, 7)); err == nil {
+		t.Error("decodeWords accepted a partial word")
+	}
+	if err := run([]string{"ranges", "-f", "gif", "-"}, strings.NewReader(""), nil, nil); err == nil {
+		t.Error("run accepted an unknown format")
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package concurrent provides a mutable bitmap for high-throughput concurrent writers,
+// which can produce immutable bitset.Set snapshots for readers.
+package concurrent
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+// concurrent.Bitmap is a fixed-size mutable bitmap that is safe for concurrent use.
+//
+// Writers update individual words atomically, so they never block each other.
+// Snapshot briefly blocks writers to return a consistent immutable copy of the bitmap.
+//
+// The words are guarded by a set of locks, striped by cache line, so writers to different parts of the bitmap
+// don't contend on a shared lock.
+type Bitmap struct {
+	stripes []stripe // held for reading by writers to their words, and all for writing by Snapshot
+	words   []atomic.Uint64
+	size    uint32
+}
+
+// stripe is a lock over the words of every len(stripes)-th cache line,
+// padded to a cache line of its own so writers to different stripes don't share one.
+type stripe struct {
+	sync.RWMutex
+	_ [64 - unsafe.Sizeof(sync.RWMutex{})%64]byte
+}
+
+// stripeWords is the number of words in a cache line, which share a stripe.
+const stripeWords = 8
+
+// New creates and returns a new empty concurrent.Bitmap with bit indices in [0, size).
+func New(size uint32) *Bitmap {
+	words := (uint64(size) + 63) / 64
+	// A power of two stripes, enough that writers rarely share one, but at most one per cache line
+	n := 1
+	for n < 4*runtime.GOMAXPROCS(0) && uint64(2*n*stripeWords) <= words {
+		n *= 2
+	}
+	return &Bitmap{stripes: make([]stripe, n), words: make([]atomic.Uint64, words), size: size}
+}
+
+// Size returns the number of bit indices in the bitmap.
+func (b *Bitmap) Size() uint32 {
+	return b.size
+}
+
+// Test reports whether the bit for the given bit index is set.
+func (b *Bitmap) Test(bitIndex uint32) bool {
+	if bitIndex >= b.size {
+		return false
+	}
+	return b.words[bitIndex/64].Load()&(1<<(bitIndex%64)) != 0
+}
+
+// Set sets the bit for the given bit index, and reports whether it was previously clear.
+// It panics if the bit index is not less than Size.
+func (b *Bitmap) Set(bitIndex uint32) bool {
+	b.check(bitIndex)
+	mu := b.stripeOf(bitIndex)
+	mu.RLock()
+	defer mu.RUnlock()
+
+	mask := uint64(1) << (bitIndex % 64)
+	return b.words[bitIndex/64].Or(mask)&mask == 0
+}
+
+// Clear clears the bit for the given bit index, and reports whether it was previously set.
+// It panics if the bit index is not less than Size.
+func (b *Bitmap) Clear(bitIndex uint32) bool {
+	b.check(bitIndex)
+	mu := b.stripeOf(bitIndex)
+	mu.RLock()
+	defer mu.RUnlock()
+
+	mask := uint64(1) << (bitIndex % 64)
+	return b.words[bitIndex/64].And(^mask)&mask != 0
+}
+
+// Snapshot returns an immutable bitset.Set with the bits of the bitmap at a single point in time.
+// Writers are blocked while the bits are copied.
+func (b *Bitmap) Snapshot() bitset.Set {
+	words := make([]uint64, len(b.words))
+
+	for i := range b.stripes {
+		b.stripes[i].Lock()
+	}
+	for i := range b.words {
+		words[i] = b.words[i].Load()
+	}
+	for i := range b.stripes {
+		b.stripes[i].Unlock()
+	}
+
+	return bitset.UnsafeFromWords(words)
+}
+
+// stripeOf returns the lock over the word of the given bit index.
+func (b *Bitmap) stripeOf(bitIndex uint32) *sync.RWMutex {
+	return &b.stripes[int(bitIndex/(64*stripeWords))&(len(b.stripes)-1)].RWMutex
+}
+
+func (b *Bitmap) check(bitIndex uint32) {
+	if bitIndex >= b.size {
+		panic(fmt.Sprintf("concurrent: bit index %d is out of range [0, %d)", bitIndex, b.size))
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package concurrent
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBitmap(t *testing.T) {
+	b := New(1000)
+	if !b.Set(5) || b.Set(5) {
+		t.Error("Set should report whether the bit was previously clear")
+	}
+	if !b.Test(5) || b.Test(6) || b.Test(5000) {
+		t.Error("Bitmap has incorrect bits")
+	}
+
+	snap := b.Snapshot()
+	if !b.Clear(5) || b.Clear(5) {
+		t.Error("Clear should report whether the bit was previously set")
+	}
+	if !snap.Test(5) {
+		t.Error("Snapshot should not change when the bitmap does")
+	}
+	if b.Snapshot().Test(5) {
+		t.Error("New snapshot should reflect the cleared bit")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Setting a bit outside the bitmap should panic")
+		}
+	}()
+	b.Set(1000)
+}
+
+func TestBitmapConcurrent(t *testing.T) {
+	b := New(64 * 100)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := g; i < 64*100; i += 8 {
+				b.Set(uint32(i))
+			}
+		})
+	}
+	wg.Go(func() {
+		for range 50 {
+			b.Snapshot()
+		}
+	})
+	wg.Wait()
+
+	snap := b.Snapshot()
+	for i := range uint32(64 * 100) {
+		if !snap.Test(i) {
+			t.Fatalf("Snapshot is missing bit %d", i)
+		}
+	}
+}
+
+func TestBitmapSnapshotIsConsistent(t *testing.T) {
+	b := New(1 << 16)
+	if len(b.stripes) < 2 {
+		t.Skip("needs more than one stripe")
+	}
+	// Bits 0 and hi are in different stripes, and hi is only set after 0
+	hi := uint32(64 * stripeWords)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10000 {
+			b.Set(0)
+			b.Set(hi)
+			b.Clear(hi)
+			b.Clear(0)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if snap := b.Snapshot(); snap.Test(hi) && !snap.Test(0) {
+			t.Fatal("Snapshot saw a later write without an earlier one")
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package cpuaffinity converts between bitset.Set values and CPU affinity masks, i.e. unix.CPUSet on Linux,
+// and the "cpu list" strings used by taskset, cpusets, and files like /sys/devices/system/cpu/online.
+//
+// It is a separate module, so the main package doesn't depend on golang.org/x/sys.
+package cpuaffinity
+
+import "github.com/sibber5/go-immutable-bitset/bitset"
+
+// ParseCPUList returns a bitset.Set with the CPUs in a cpu list like "0-3,8", i.e. comma separated CPU numbers
+// and inclusive ranges. Surrounding whitespace is ignored, so the contents of /sys/devices/system/cpu/online
+// can be passed directly. An empty list is an empty set. It is bitset.ParseRangeList.
+func ParseCPUList(list string) (bitset.Set, error) {
+	return bitset.ParseRangeList(list)
+}
+
+// FormatCPUList returns the CPUs in s as a cpu list, using ranges for consecutive CPUs, e.g. "0-3,8".
+// It returns an empty string for an empty set. It is bitset.FormatRangeList.
+func FormatCPUList(s bitset.Set) string {
+	return bitset.FormatRangeList(s)
+}
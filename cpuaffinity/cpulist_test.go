@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package cpuaffinity
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		list string
+		want []uint32
+	}{
+		{"", nil},
+		{"0", []uint32{0}},
+		{"0-3,8\n", []uint32{0, 1, 2, 3, 8}},
+		{"8,0-1,1", []uint32{0, 1, 8}},
+	}
+	for _, tt := range tests {
+		s, err := ParseCPUList(tt.list)
+		if err != nil {
+			t.Errorf("ParseCPUList(%q) returned error: %v", tt.list, err)
+			continue
+		}
+		if got := slices.Collect(s.Indices()); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseCPUList(%q) = %v, expected %v", tt.list, got, tt.want)
+		}
+	}
+
+	for _, list := range []string{"a", "1,", "3-1", "-2", "1-2-3", "0x1"} {
+		if _, err := ParseCPUList(list); err == nil {
+			t.Errorf("Expected an error for %q", list)
+		}
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	tests := []struct {
+		cpus []uint32
+		want string
+	}{
+		{nil, ""},
+		{[]uint32{5}, "5"},
+		{[]uint32{0, 1, 2, 3, 8}, "0-3,8"},
+		{[]uint32{0, 2, 3, 100, 101, 102}, "0,2-3,100-102"},
+	}
+	for _, tt := range tests {
+		if got := FormatCPUList(bitset.FromIndices(tt.cpus...)); got != tt.want {
+			t.Errorf("FormatCPUList(%v) = %q, expected %q", tt.cpus, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package cpuaffinity
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+	"golang.org/x/sys/unix"
+)
+
+// maxCPUs is the number of CPUs a unix.CPUSet can hold.
+const maxCPUs = uint32(unsafe.Sizeof(unix.CPUSet{}) * 8)
+
+// FromCPUSet returns a bitset.Set with the CPUs in set, e.g. the result of unix.SchedGetaffinity.
+func FromCPUSet(set *unix.CPUSet) bitset.Set {
+	b := bitset.NewBuilder(0)
+	if set == nil {
+		return b.Build()
+	}
+
+	for cpu := range maxCPUs {
+		if set.IsSet(int(cpu)) {
+			b = b.With(cpu)
+		}
+	}
+	return b.Build()
+}
+
+// ToCPUSet returns a unix.CPUSet with the CPUs in s, to pass to unix.SchedSetaffinity,
+// or bitset.ErrOutOfRange if s has a CPU that doesn't fit in a unix.CPUSet.
+func ToCPUSet(s bitset.Set) (unix.CPUSet, error) {
+	var set unix.CPUSet
+	for cpu := range s.Indices() {
+		if cpu >= maxCPUs {
+			return unix.CPUSet{}, fmt.Errorf("%w: cpu %d is not in [0, %d)", bitset.ErrOutOfRange, cpu, maxCPUs)
+		}
+		set.Set(int(cpu))
+	}
+	return set, nil
+}
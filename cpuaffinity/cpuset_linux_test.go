@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package cpuaffinity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+	"golang.org/x/sys/unix"
+)
+
+func TestCPUSet(t *testing.T) {
+	var set unix.CPUSet
+	set.Set(0)
+	set.Set(3)
+	set.Set(int(maxCPUs - 1))
+
+	s := FromCPUSet(&set)
+	if s.Count() != 3 || !s.Test(0) || !s.Test(3) || !s.Test(maxCPUs-1) {
+		t.Errorf("Set from CPUSet has incorrect bits: %s", FormatCPUList(s))
+	}
+	if s := FromCPUSet(nil); s.Count() != 0 {
+		t.Error("Expected an empty set for a nil CPUSet")
+	}
+
+	got, err := ToCPUSet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != set {
+		t.Error("CPUSet does not round trip")
+	}
+
+	if _, err := ToCPUSet(bitset.FromIndices(maxCPUs)); !errors.Is(err, bitset.ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+module github.com/sibber5/go-immutable-bitset/cpuaffinity
+
+go 1.25.0
+
+require (
+	github.com/sibber5/go-immutable-bitset v1.2.0
+	golang.org/x/sys v0.47.0
+)
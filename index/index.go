@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package index provides an immutable bitmap index, which maps terms to the sets of row ids that have them,
+// and evaluates boolean queries over those terms.
+package index
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+// index.BitmapIndex maps terms, e.g. labels or column values, to the set of row ids that have each term.
+//
+// Like bitset.Set, a BitmapIndex is immutable: With and Without return a new index that shares
+// most of its memory with the original, so it can be read from many goroutines while a writer publishes new versions.
+// The zero value is an empty index.
+type BitmapIndex[K comparable] struct {
+	terms bitset.SetMap[K]
+	rows  bitset.Set
+}
+
+// Rows returns every row id in ix, i.e. every row that was added with With and not removed with Without.
+func (ix BitmapIndex[K]) Rows() bitset.Set {
+	if ix.rows == nil {
+		return bitset.New()
+	}
+	return ix.rows
+}
+
+// Term returns the row ids that have the given term.
+func (ix BitmapIndex[K]) Term(term K) bitset.Set {
+	if s, ok := ix.terms.Get(term); ok {
+		return s
+	}
+	return bitset.New()
+}
+
+// Terms returns an iterator over the terms in ix and their row ids, in no particular order.
+func (ix BitmapIndex[K]) Terms() iter.Seq2[K, bitset.Set] {
+	return ix.terms.All()
+}
+
+// With returns a new index.BitmapIndex with the given row id added, and the given terms added to it.
+// The original index.BitmapIndex is not modified.
+func (ix BitmapIndex[K]) With(row uint32, terms ...K) BitmapIndex[K] {
+	ix.rows = ix.Rows().Set(row)
+	for _, t := range terms {
+		ix.terms = ix.terms.With(t, ix.Term(t).Set(row))
+	}
+	return ix
+}
+
+// Without returns a new index.BitmapIndex without the given row id, or any of its terms.
+// Terms that no longer have any rows are removed.
+// The original index.BitmapIndex is not modified.
+func (ix BitmapIndex[K]) Without(row uint32) BitmapIndex[K] {
+	if !ix.Rows().Test(row) {
+		return ix
+	}
+
+	ix.rows = ix.rows.Clear(row)
+	for t, s := range ix.terms.All() {
+		if !s.Test(row) {
+			continue
+		}
+		if s = s.Clear(row); s.Count() == 0 {
+			ix.terms = ix.terms.Without(t)
+		} else {
+			ix.terms = ix.terms.With(t, s)
+		}
+	}
+	return ix
+}
+
+// Query returns the row ids that match q.
+func (ix BitmapIndex[K]) Query(q Query[K]) bitset.Set {
+	return q.eval(ix)
+}
+
+// index.Query is a boolean combination of terms, created with Term, And, Or, and Not.
+type Query[K comparable] interface {
+	eval(ix BitmapIndex[K]) bitset.Set
+}
+
+type termQuery[K comparable] struct{ term K }
+type andQuery[K comparable] []Query[K]
+type orQuery[K comparable] []Query[K]
+type notQuery[K comparable] struct{ q Query[K] }
+
+// Term returns a query that matches the rows with the given term.
+func Term[K comparable](term K) Query[K] {
+	return termQuery[K]{term}
+}
+
+// And returns a query that matches the rows that match every one of qs. And() matches every row.
+func And[K comparable](qs ...Query[K]) Query[K] {
+	return andQuery[K](slices.Clone(qs))
+}
+
+// Or returns a query that matches the rows that match any of qs. Or() matches no rows.
+func Or[K comparable](qs ...Query[K]) Query[K] {
+	return orQuery[K](slices.Clone(qs))
+}
+
+// Not returns a query that matches the rows that don't match q.
+func Not[K comparable](q Query[K]) Query[K] {
+	return notQuery[K]{q}
+}
+
+func (q termQuery[K]) eval(ix BitmapIndex[K]) bitset.Set {
+	return ix.Term(q.term)
+}
+
+func (q andQuery[K]) eval(ix BitmapIndex[K]) bitset.Set {
+	if len(q) == 0 {
+		return ix.Rows()
+	}
+
+	// Intersect the smallest sets first, so the intermediate results stay small
+	sets := make([]bitset.Set, len(q))
+	for i, sub := range q {
+		sets[i] = sub.eval(ix)
+	}
+	slices.SortFunc(sets, func(a, b bitset.Set) int {
+		return cmp.Compare(a.Count(), b.Count())
+	})
+
+	s := sets[0]
+	for _, other := range sets[1:] {
+		if s.Count() == 0 {
+			break
+		}
+		s = s.Intersect(other)
+	}
+	return s
+}
+
+func (q orQuery[K]) eval(ix BitmapIndex[K]) bitset.Set {
+	s := bitset.New()
+	for _, sub := range q {
+		s = s.Union(sub.eval(ix))
+	}
+	return s
+}
+
+func (q notQuery[K]) eval(ix BitmapIndex[K]) bitset.Set {
+	return ix.Rows().Difference(q.q.eval(ix))
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package index
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+func testIndex() BitmapIndex[string] {
+	var ix BitmapIndex[string]
+	return ix.
+		With(0, "red", "small").
+		With(1, "red", "large").
+		With(2, "blue", "small").
+		With(3, "blue", "large").
+		With(4)
+}
+
+func rows(s bitset.Set) []uint32 {
+	return slices.Collect(s.Indices())
+}
+
+func TestBitmapIndex(t *testing.T) {
+	ix := testIndex()
+	if got := rows(ix.Rows()); !slices.Equal(got, []uint32{0, 1, 2, 3, 4}) {
+		t.Errorf("Unexpected rows %v", got)
+	}
+	if got := rows(ix.Term("red")); !slices.Equal(got, []uint32{0, 1}) {
+		t.Errorf("Unexpected rows for red: %v", got)
+	}
+	if ix.Term("green").Count() != 0 {
+		t.Error("Unknown terms should have no rows")
+	}
+
+	n := 0
+	for range ix.Terms() {
+		n++
+	}
+	if n != 4 {
+		t.Errorf("Expected 4 terms, got %d", n)
+	}
+
+	var zero BitmapIndex[int]
+	if zero.Rows().Count() != 0 || zero.Query(Not(Term(1))).Count() != 0 {
+		t.Error("The zero BitmapIndex should be empty")
+	}
+}
+
+func TestBitmapIndexWithout(t *testing.T) {
+	ix := testIndex()
+	ix2 := ix.Without(0).Without(2).Without(100)
+
+	if got := rows(ix2.Term("small")); len(got) != 0 {
+		t.Errorf("Expected no small rows, got %v", got)
+	}
+	for term := range ix2.Terms() {
+		if term == "small" {
+			t.Error("Terms without rows should be removed")
+		}
+	}
+	if got := rows(ix2.Rows()); !slices.Equal(got, []uint32{1, 3, 4}) {
+		t.Errorf("Unexpected rows %v", got)
+	}
+	if got := rows(ix.Term("small")); !slices.Equal(got, []uint32{0, 2}) {
+		t.Error("Without should not modify the original index")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ix := testIndex()
+	tests := []struct {
+		name string
+		q    Query[string]
+		want []uint32
+	}{
+		{"term", Term("blue"), []uint32{2, 3}},
+		{"and", And(Term("red"), Term("small")), []uint32{0}},
+		{"or", Or(Term("red"), Term("small")), []uint32{0, 1, 2}},
+		{"not", Not(Term("red")), []uint32{2, 3, 4}},
+		{"nested", And(Term("large"), Or(Term("red"), Not(Term("blue")))), []uint32{1}},
+		{"empty and", And[string](), []uint32{0, 1, 2, 3, 4}},
+		{"empty or", Or[string](), nil},
+		{"unknown", And(Term("red"), Term("green")), nil},
+	}
+	for _, tt := range tests {
+		if got := rows(ix.Query(tt.q)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
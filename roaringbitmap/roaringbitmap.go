@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+// Package roaringbitmap converts between immutable bitset.Set values and github.com/RoaringBitmap/roaring Bitmaps,
+// so code that stores its data as Roaring bitmaps can hand out immutable snapshots.
+//
+// It is a separate module, so the main package doesn't depend on roaring.
+package roaringbitmap
+
+import (
+	"github.com/RoaringBitmap/roaring/v2"
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+// FromRoaring returns a bitset.Set with the bits of rb.
+// The bits are copied, so rb can be modified afterwards. They are read in ascending order straight into ranges
+// of consecutive bits, so they are never sorted, and long runs in rb don't turn into a bit or word each.
+func FromRoaring(rb *roaring.Bitmap) bitset.Set {
+	if rb == nil {
+		return bitset.New()
+	}
+
+	var ranges []bitset.Range
+	buf := make([]uint32, 4096)
+	it := rb.ManyIterator()
+	for n := it.NextMany(buf); n > 0; n = it.NextMany(buf) {
+		for _, i := range buf[:n] {
+			if k := len(ranges) - 1; k >= 0 && ranges[k].End == uint64(i) {
+				ranges[k].End++
+			} else {
+				ranges = append(ranges, bitset.Range{Start: uint64(i), End: uint64(i) + 1})
+			}
+		}
+	}
+	return bitset.NewRangeSet(ranges...).Bits()
+}
+
+// ToRoaring returns a new Roaring bitmap with the bits of s.
+func ToRoaring(s bitset.Set) *roaring.Bitmap {
+	rb := roaring.New()
+	buf := make([]uint32, 0, 4096)
+	for i := range s.Indices() {
+		if buf = append(buf, i); len(buf) == cap(buf) {
+			rb.AddMany(buf)
+			buf = buf[:0]
+		}
+	}
+	rb.AddMany(buf)
+	rb.RunOptimize()
+	return rb
+}
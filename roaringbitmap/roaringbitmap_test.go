@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (c) 2025 sibber (GitHub: sibber5)
+
+package roaringbitmap
+
+import (
+	"testing"
+
+	"github.com/RoaringBitmap/roaring/v2"
+	"github.com/sibber5/go-immutable-bitset/bitset"
+)
+
+func TestFromRoaring(t *testing.T) {
+	rb := roaring.BitmapOf(3, 700, 3_000_000_000)
+	rb.AddRange(10_000, 20_000)
+	s := FromRoaring(rb)
+
+	rb.Remove(3)
+	if !s.Test(3) || !s.Test(700) || !s.Test(15_000) || !s.Test(3_000_000_000) || s.Test(4) {
+		t.Error("Converted set has incorrect bits, or shares memory with the bitmap")
+	}
+	if s.Count() != 10_003 {
+		t.Errorf("Expected 10003 bits, got %d", s.Count())
+	}
+
+	if s := FromRoaring(nil); s.Count() != 0 {
+		t.Error("Converting nil should return an empty set")
+	}
+
+	// Runs stay runs
+	rb = roaring.New()
+	rb.AddRange(0, 1<<20)
+	rb.AddRange(1<<30, 1<<30+1<<20)
+	if s := FromRoaring(rb); s.Kind() != bitset.KindRuns || s.Count() != 2<<20 {
+		t.Errorf("Expected the runs of the bitmap as a %v set, got %+v", bitset.KindRuns, bitset.Inspect(s))
+	}
+
+	// Bits spread over many batches of the iterator
+	rb = roaring.New()
+	var want []uint32
+	for i := uint32(0); i < 100_000; i++ {
+		rb.Add(i * 7919)
+		want = append(want, i*7919)
+	}
+	if s := FromRoaring(rb); !bitset.Equal(s, bitset.FromIndices(want...)) {
+		t.Error("Converted set has incorrect bits")
+	}
+}
+
+func TestToRoaring(t *testing.T) {
+	b := bitset.NewBuilder(0)
+	for i := uint32(0); i < 10_000; i += 2 {
+		b = b.With(i)
+	}
+	s := b.Build().Set(4_000_000_000)
+
+	rb := ToRoaring(s)
+	if rb.GetCardinality() != 5001 || !rb.Contains(9998) || !rb.Contains(4_000_000_000) || rb.Contains(1) {
+		t.Error("Converted bitmap has incorrect bits")
+	}
+
+	// Round trip
+	if got := FromRoaring(rb); got.Key() != s.Key() {
+		t.Error("Set does not round trip through a bitmap")
+	}
+	if rb := ToRoaring(bitset.New()); !rb.IsEmpty() {
+		t.Error("Converting an empty set should return an empty bitmap")
+	}
+}